@@ -0,0 +1,128 @@
+// Package providers 为命令行工具提供按名称创建客户端的工厂
+package providers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/models"
+	"github.com/ojbkgo/llm-sdk/pkg/providers/anthropic"
+	"github.com/ojbkgo/llm-sdk/pkg/providers/cohere"
+	"github.com/ojbkgo/llm-sdk/pkg/providers/deepseek"
+	"github.com/ojbkgo/llm-sdk/pkg/providers/doubao"
+	"github.com/ojbkgo/llm-sdk/pkg/providers/gemini"
+	"github.com/ojbkgo/llm-sdk/pkg/providers/mistral"
+	"github.com/ojbkgo/llm-sdk/pkg/providers/moonshot"
+	"github.com/ojbkgo/llm-sdk/pkg/providers/openai"
+	"github.com/ojbkgo/llm-sdk/pkg/providers/qwen"
+	"github.com/ojbkgo/llm-sdk/pkg/providers/zhipu"
+)
+
+// Provider 描述一个可以通过名称创建的提供商
+type Provider struct {
+	// Name 提供商名称，与models注册表中的Provider一致（Gemini除外，注册表中为google）
+	Name string
+	// EnvKey 读取API密钥的环境变量
+	EnvKey string
+	// DefaultModel 未指定模型时使用的模型
+	DefaultModel string
+
+	newClient func(options ...api.ClientOption) (api.LLMClient, error)
+}
+
+// NewClient 创建该提供商的客户端
+func (p *Provider) NewClient(options ...api.ClientOption) (api.LLMClient, error) {
+	return p.newClient(options...)
+}
+
+// 已支持的提供商
+var registry = map[string]*Provider{
+	"openai":    {Name: "openai", EnvKey: "OPENAI_API_KEY", DefaultModel: models.GPT4o, newClient: openai.NewClient},
+	"anthropic": {Name: "anthropic", EnvKey: "ANTHROPIC_API_KEY", DefaultModel: models.ClaudeSonnet4, newClient: anthropic.NewClient},
+	"deepseek":  {Name: "deepseek", EnvKey: "DEEPSEEK_API_KEY", DefaultModel: models.DeepSeekChat, newClient: deepseek.NewClient},
+	"gemini":    {Name: "gemini", EnvKey: "GEMINI_API_KEY", DefaultModel: models.Gemini25Flash, newClient: gemini.NewClient},
+	"mistral":   {Name: "mistral", EnvKey: "MISTRAL_API_KEY", DefaultModel: models.MistralLarge, newClient: mistral.NewClient},
+	"cohere":    {Name: "cohere", EnvKey: "COHERE_API_KEY", DefaultModel: models.CommandA, newClient: cohere.NewClient},
+	"zhipu":     {Name: "zhipu", EnvKey: "ZHIPU_API_KEY", DefaultModel: models.GLM4Plus, newClient: zhipu.NewClient},
+	"qwen":      {Name: "qwen", EnvKey: "DASHSCOPE_API_KEY", DefaultModel: models.QwenPlus, newClient: qwen.NewClient},
+	"moonshot":  {Name: "moonshot", EnvKey: "MOONSHOT_API_KEY", DefaultModel: models.MoonshotV18K, newClient: moonshot.NewClient},
+	"doubao":    {Name: "doubao", EnvKey: "ARK_API_KEY", DefaultModel: models.DoubaoPro32K, newClient: doubao.NewClient},
+}
+
+// 注册表中的提供商名称与本包名称不一致的情况
+var aliases = map[string]string{
+	"google": "gemini",
+	"claude": "anthropic",
+	"ark":    "doubao",
+}
+
+// Get 按名称返回提供商，名称不区分大小写并支持别名
+func Get(name string) (*Provider, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := aliases[name]; ok {
+		name = alias
+	}
+	if p, ok := registry[name]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("不支持的提供商: %s（可选: %s）", name, strings.Join(Names(), ", "))
+}
+
+// Names 返回所有提供商名称
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ForModel 根据models注册表推断模型所属的提供商
+func ForModel(model string) (*Provider, bool) {
+	info := models.GetModelInfo(model)
+	if info == nil {
+		return nil, false
+	}
+	p, err := Get(info.Provider)
+	if err != nil {
+		return nil, false
+	}
+	return p, true
+}
+
+// FirstConfigured 按名称顺序返回第一个设置了API密钥环境变量的提供商
+func FirstConfigured() (*Provider, bool) {
+	for _, name := range Names() {
+		if p := registry[name]; os.Getenv(p.EnvKey) != "" {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// RegistryName 返回该提供商在models注册表中的名称
+func (p *Provider) RegistryName() string {
+	if p.Name == "gemini" {
+		return "google"
+	}
+	return p.Name
+}
+
+// Models 返回该提供商在models注册表中的模型
+func (p *Provider) Models() []models.ModelInfo {
+	return models.ProviderModels(p.RegistryName())
+}
+
+// Discover 通过client查询该提供商当前可用的模型并合并到models注册表中，返回新增的模型数
+func (p *Provider) Discover(ctx context.Context, client api.LLMClient) (int, error) {
+	lister, ok := client.(api.ModelLister)
+	if !ok {
+		return 0, fmt.Errorf("%s不支持查询模型列表", p.Name)
+	}
+	return models.Discover(ctx, p.RegistryName(), lister)
+}
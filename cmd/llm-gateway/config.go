@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Config 定义网关配置文件的结构
+type Config struct {
+	// Listen 监听地址，如":8080"
+	Listen string `json:"listen,omitempty"`
+	// Timeout 上游请求超时时间（秒）
+	Timeout int `json:"timeout,omitempty"`
+	// UsageLog 用量日志的路径，每行一条JSON记录；为空或"-"时写入标准输出
+	UsageLog string `json:"usage_log,omitempty"`
+
+	// Providers 按提供商名称配置API密钥和BaseURL，未配置API密钥时读取对应的环境变量
+	Providers map[string]ProviderConfig `json:"providers,omitempty"`
+	// Routes 将模型名称映射到提供商，优先于models注册表的推断
+	Routes map[string]string `json:"routes,omitempty"`
+	// StrictParams 为true时拒绝包含上游提供商不支持参数的请求，否则忽略这些参数
+	StrictParams bool `json:"strict_params,omitempty"`
+	// DiscoverModels 为true时在启动时从已配置提供商的模型列表接口查询可用的模型，
+	// 使新发布的模型可以按名称路由并出现在/v1/models中
+	DiscoverModels bool `json:"discover_models,omitempty"`
+
+	// Keys 允许访问网关的API密钥
+	Keys []KeyConfig `json:"keys,omitempty"`
+}
+
+// ProviderConfig 定义单个提供商的配置
+type ProviderConfig struct {
+	APIKey  string `json:"api_key,omitempty"`
+	BaseURL string `json:"base_url,omitempty"`
+}
+
+// KeyConfig 定义网关API密钥及其配额，配额为0表示不限制
+type KeyConfig struct {
+	// Name 写入用量日志的名称，避免记录密钥本身
+	Name string `json:"name"`
+	Key  string `json:"key"`
+
+	RequestsPerMinute int `json:"requests_per_minute,omitempty"`
+	RequestsPerDay    int `json:"requests_per_day,omitempty"`
+	TokensPerDay      int `json:"tokens_per_day,omitempty"`
+
+	// Models 允许访问的模型，为空时不限制
+	Models []string `json:"models,omitempty"`
+}
+
+// allowsModel 判断该密钥是否可以访问模型
+func (k *KeyConfig) allowsModel(model string) bool {
+	if len(k.Models) == 0 {
+		return true
+	}
+	for _, m := range k.Models {
+		if m == model || m == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// loadConfig 读取配置文件，path为空时返回空配置
+func loadConfig(path string) (*Config, error) {
+	config := &Config{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取配置文件失败: %w", err)
+		}
+		if err := json.Unmarshal(data, config); err != nil {
+			return nil, fmt.Errorf("解析配置文件%s失败: %w", path, err)
+		}
+	}
+
+	if config.Listen == "" {
+		config.Listen = defaultListen
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+
+	seen := make(map[string]bool, len(config.Keys))
+	for i, key := range config.Keys {
+		if key.Key == "" {
+			return nil, fmt.Errorf("第%d个密钥的key不能为空", i+1)
+		}
+		if seen[key.Key] {
+			return nil, fmt.Errorf("密钥%s重复", key.Name)
+		}
+		seen[key.Key] = true
+		if key.Name == "" {
+			config.Keys[i].Name = fmt.Sprintf("key-%d", i+1)
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,107 @@
+// llm-gateway 是OpenAI兼容的HTTP网关，按模型名称将请求转发给SDK支持的任意提供商
+//
+// 支持的接口:
+//
+//	POST /v1/chat/completions  聊天补全（支持stream）
+//	POST /v1/embeddings        嵌入向量
+//	GET  /v1/models            可用的模型
+//
+// 用法:
+//
+//	llm-gateway -config gateway.json
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// 默认配置
+const (
+	defaultListen          = ":8080"
+	defaultTimeout         = 300
+	defaultShutdownTimeout = 30 * time.Second
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatalf("错误: %v", err)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("llm-gateway", flag.ContinueOnError)
+	var (
+		configPath = flags.String("config", "", "配置文件路径（JSON）")
+		listen     = flags.String("listen", "", "监听地址，覆盖配置文件")
+		noAuth     = flags.Bool("no-auth", false, "允许在未配置密钥时匿名访问")
+	)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	if *listen != "" {
+		config.Listen = *listen
+	}
+	if len(config.Keys) == 0 && !*noAuth {
+		return errors.New("未配置网关密钥，请在配置文件的keys中添加密钥，或使用-no-auth允许匿名访问")
+	}
+
+	var usageLog io.Writer = os.Stdout
+	if config.UsageLog != "" && config.UsageLog != "-" {
+		f, err := os.OpenFile(config.UsageLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("打开用量日志失败: %w", err)
+		}
+		defer f.Close()
+		usageLog = f
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	g := newGateway(config, usageLog)
+	if config.DiscoverModels {
+		g.discoverModels(ctx)
+	}
+
+	server := &http.Server{
+		Addr:              config.Listen,
+		Handler:           g.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("llm-gateway 监听 %s", config.Listen)
+		errCh <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	// 等待进行中的请求（包括流式响应）结束
+	log.Printf("正在关闭...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+	defer cancel()
+	return server.Shutdown(shutdownCtx)
+}
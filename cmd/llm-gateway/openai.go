@@ -0,0 +1,247 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// chatCompletionRequest 定义OpenAI格式的聊天请求
+type chatCompletionRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+
+	Temperature         *float64           `json:"temperature,omitempty"`
+	TopP                *float64           `json:"top_p,omitempty"`
+	MaxTokens           *int               `json:"max_tokens,omitempty"`
+	MaxCompletionTokens *int               `json:"max_completion_tokens,omitempty"`
+	PresencePenalty     *float64           `json:"presence_penalty,omitempty"`
+	FrequencyPenalty    *float64           `json:"frequency_penalty,omitempty"`
+	Stop                json.RawMessage    `json:"stop,omitempty"`
+	N                   *int               `json:"n,omitempty"`
+	Seed                *int               `json:"seed,omitempty"`
+	LogitBias           map[string]float64 `json:"logit_bias,omitempty"`
+	User                string             `json:"user,omitempty"`
+	Metadata            map[string]string  `json:"metadata,omitempty"`
+	ReasoningEffort     string             `json:"reasoning_effort,omitempty"`
+	// TopK 不属于OpenAI格式，供支持top_k的提供商使用
+	TopK *int `json:"top_k,omitempty"`
+
+	Stream        bool `json:"stream,omitempty"`
+	StreamOptions *struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options,omitempty"`
+
+	Tools             []api.Tool          `json:"tools,omitempty"`
+	ToolChoice        interface{}         `json:"tool_choice,omitempty"`
+	ParallelToolCalls *bool               `json:"parallel_tool_calls,omitempty"`
+	ResponseFormat    *api.ResponseFormat `json:"response_format,omitempty"`
+}
+
+// chatMessage 定义OpenAI格式的消息，content可以是字符串或内容段数组
+type chatMessage struct {
+	Role       api.Role        `json:"role"`
+	Content    json.RawMessage `json:"content"`
+	Name       string          `json:"name,omitempty"`
+	ToolCalls  []api.ToolCall  `json:"tool_calls,omitempty"`
+	ToolCallID string          `json:"tool_call_id,omitempty"`
+}
+
+// embeddingRequest 定义OpenAI格式的嵌入请求，input可以是字符串或字符串数组
+type embeddingRequest struct {
+	Model string          `json:"model"`
+	Input json.RawMessage `json:"input"`
+}
+
+// embeddingResponse 定义OpenAI格式的嵌入响应
+type embeddingResponse struct {
+	Object string          `json:"object"`
+	Data   []embeddingData `json:"data"`
+	Model  string          `json:"model"`
+	Usage  api.Usage       `json:"usage"`
+}
+
+// embeddingData 定义单条输入的嵌入向量
+type embeddingData struct {
+	Object    string    `json:"object"`
+	Index     int       `json:"index"`
+	Embedding []float32 `json:"embedding"`
+}
+
+// modelList 定义/v1/models的响应
+type modelList struct {
+	Object string        `json:"object"`
+	Data   []modelObject `json:"data"`
+}
+
+// modelObject 定义单个模型
+type modelObject struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// errorResponse 定义OpenAI格式的错误响应
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+// errorBody 定义错误详情
+type errorBody struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Param   string `json:"param,omitempty"`
+	Code    string `json:"code,omitempty"`
+}
+
+// toRequest 将OpenAI格式的请求转换为SDK请求
+func (r *chatCompletionRequest) toRequest() (*api.Request, error) {
+	if r.Model == "" {
+		return nil, errors.New("model不能为空")
+	}
+	if len(r.Messages) == 0 {
+		return nil, errors.New("messages不能为空")
+	}
+	if r.N != nil && *r.N < 1 {
+		return nil, errors.New("n必须大于0")
+	}
+
+	request := &api.Request{
+		Model:             r.Model,
+		Messages:          make([]api.Message, 0, len(r.Messages)),
+		Temperature:       r.Temperature,
+		TopP:              r.TopP,
+		MaxTokens:         r.MaxTokens,
+		PresencePenalty:   r.PresencePenalty,
+		FrequencyPenalty:  r.FrequencyPenalty,
+		Stream:            r.Stream,
+		TopK:              r.TopK,
+		Seed:              r.Seed,
+		LogitBias:         r.LogitBias,
+		User:              r.User,
+		Tools:             r.Tools,
+		ToolChoice:        r.ToolChoice,
+		ParallelToolCalls: r.ParallelToolCalls,
+		ResponseFormat:    r.ResponseFormat,
+		ReasoningEffort:   r.ReasoningEffort,
+		Metadata:          r.Metadata,
+	}
+	if r.MaxCompletionTokens != nil {
+		request.MaxTokens = r.MaxCompletionTokens
+	}
+	if r.N != nil {
+		request.N = *r.N
+	}
+
+	stop, err := parseStringOrList(r.Stop)
+	if err != nil {
+		return nil, fmt.Errorf("stop格式错误: %w", err)
+	}
+	request.Stop = stop
+
+	for i, m := range r.Messages {
+		content, err := parseContent(m.Content)
+		if err != nil {
+			return nil, fmt.Errorf("messages[%d].content: %w", i, err)
+		}
+		request.Messages = append(request.Messages, api.Message{
+			Role:       m.Role,
+			Content:    content,
+			Name:       m.Name,
+			ToolCalls:  m.ToolCalls,
+			ToolCallID: m.ToolCallID,
+		})
+	}
+	return request, nil
+}
+
+// includeUsage 判断流式响应是否需要在结尾返回用量
+func (r *chatCompletionRequest) includeUsage() bool {
+	return r.StreamOptions != nil && r.StreamOptions.IncludeUsage
+}
+
+// parseContent 解析消息内容，内容段数组中只支持文本段
+func parseContent(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, nil
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return "", errors.New("必须是字符串或内容段数组")
+	}
+
+	var sb strings.Builder
+	for _, part := range parts {
+		if part.Type != "text" {
+			return "", fmt.Errorf("暂不支持%s类型的内容", part.Type)
+		}
+		sb.WriteString(part.Text)
+	}
+	return sb.String(), nil
+}
+
+// parseStringOrList 解析字符串或字符串数组
+func parseStringOrList(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return []string{s}, nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, errors.New("必须是字符串或字符串数组")
+	}
+	return list, nil
+}
+
+// errorStatus 将SDK错误映射为返回给调用方的HTTP状态码和错误类型
+//
+// 上游的认证错误说明网关自身的提供商密钥有问题，对调用方返回502而不是401。
+func errorStatus(err error) (int, string) {
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) {
+		return http.StatusInternalServerError, string(api.ErrorTypeUnknown)
+	}
+
+	switch apiErr.Type {
+	case api.ErrorTypeInvalidRequest, api.ErrorTypeContentFiltered:
+		return http.StatusBadRequest, string(apiErr.Type)
+	case api.ErrorTypeRateLimit:
+		return http.StatusTooManyRequests, string(apiErr.Type)
+	case api.ErrorTypeTimeout:
+		return http.StatusGatewayTimeout, string(apiErr.Type)
+	case api.ErrorTypeAuthentication, api.ErrorTypeConnection, api.ErrorTypeServer:
+		return http.StatusBadGateway, string(apiErr.Type)
+	default:
+		return http.StatusInternalServerError, string(apiErr.Type)
+	}
+}
+
+// newCompletionID 生成响应ID，用于上游未返回ID的情况
+func newCompletionID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "chatcmpl-gateway"
+	}
+	return "chatcmpl-" + hex.EncodeToString(b)
+}
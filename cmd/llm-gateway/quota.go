@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// quotaTracker 在内存中统计每个密钥的用量，网关重启后清零
+type quotaTracker struct {
+	mu    sync.Mutex
+	usage map[string]*keyUsage
+	now   func() time.Time
+}
+
+// keyUsage 记录单个密钥在当前窗口内的用量
+type keyUsage struct {
+	// day 按UTC日期统计的窗口
+	day      string
+	requests int
+	tokens   int
+
+	// minute 最近一分钟窗口的开始时间
+	minute         time.Time
+	minuteRequests int
+}
+
+func newQuotaTracker() *quotaTracker {
+	return &quotaTracker{
+		usage: make(map[string]*keyUsage),
+		now:   time.Now,
+	}
+}
+
+// acquire 检查配额并计入一次请求，超出配额时返回原因
+//
+// token配额只能在请求结束后统计，因此按已用量判断：当天用量已达到上限时拒绝新请求。
+func (q *quotaTracker) acquire(key *KeyConfig) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	u := q.get(key.Key)
+	now := q.now()
+	if now.Sub(u.minute) >= time.Minute {
+		u.minute = now
+		u.minuteRequests = 0
+	}
+
+	if key.RequestsPerMinute > 0 && u.minuteRequests >= key.RequestsPerMinute {
+		return fmt.Errorf("超出每分钟%d次请求的限制", key.RequestsPerMinute)
+	}
+	if key.RequestsPerDay > 0 && u.requests >= key.RequestsPerDay {
+		return fmt.Errorf("超出每天%d次请求的限制", key.RequestsPerDay)
+	}
+	if key.TokensPerDay > 0 && u.tokens >= key.TokensPerDay {
+		return fmt.Errorf("超出每天%d个token的限制", key.TokensPerDay)
+	}
+
+	u.minuteRequests++
+	u.requests++
+	return nil
+}
+
+// addTokens 在请求结束后计入token用量
+func (q *quotaTracker) addTokens(key *KeyConfig, tokens int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.get(key.Key).tokens += tokens
+}
+
+// get 返回密钥的用量记录，跨天时重置
+func (q *quotaTracker) get(key string) *keyUsage {
+	day := q.now().UTC().Format("2006-01-02")
+	u, ok := q.usage[key]
+	if !ok {
+		u = &keyUsage{}
+		q.usage[key] = u
+	}
+	if u.day != day {
+		u.day = day
+		u.requests = 0
+		u.tokens = 0
+	}
+	return u
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuotaTracker(t *testing.T) {
+	// step 是测试中的一步：先推进时间并计入token，再申请一次请求
+	type step struct {
+		advance time.Duration
+		tokens  int
+		wantErr string
+	}
+
+	tests := []struct {
+		name  string
+		key   KeyConfig
+		start time.Time
+		steps []step
+	}{
+		{
+			name:  "unlimited",
+			key:   KeyConfig{Key: "k"},
+			start: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			steps: []step{{}, {}, {tokens: 1 << 20}},
+		},
+		{
+			name:  "minute window",
+			key:   KeyConfig{Key: "k", RequestsPerMinute: 2},
+			start: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			steps: []step{
+				{},
+				{advance: 30 * time.Second},
+				{advance: 29 * time.Second, wantErr: "每分钟2次"},
+				{advance: time.Second},
+				{},
+				{wantErr: "每分钟2次"},
+			},
+		},
+		{
+			name:  "daily requests reset at UTC midnight",
+			key:   KeyConfig{Key: "k", RequestsPerDay: 2},
+			start: time.Date(2024, 1, 1, 23, 58, 0, 0, time.UTC),
+			steps: []step{
+				{},
+				{},
+				{advance: time.Minute, wantErr: "每天2次"},
+				{advance: time.Minute},
+			},
+		},
+		{
+			name:  "daily requests use UTC not local day",
+			key:   KeyConfig{Key: "k", RequestsPerDay: 1},
+			start: time.Date(2024, 1, 1, 23, 0, 0, 0, time.FixedZone("UTC+8", 8*3600)),
+			steps: []step{
+				{},
+				{advance: 2 * time.Hour, wantErr: "每天1次"},
+			},
+		},
+		{
+			name:  "daily tokens",
+			key:   KeyConfig{Key: "k", TokensPerDay: 100},
+			start: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			steps: []step{
+				{},
+				{tokens: 99},
+				{tokens: 1, wantErr: "每天100个token"},
+				{advance: time.Hour, wantErr: "每天100个token"},
+				{advance: 12 * time.Hour},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := tt.start
+			quota := newQuotaTracker()
+			quota.now = func() time.Time { return now }
+
+			for i, s := range tt.steps {
+				now = now.Add(s.advance)
+				if s.tokens > 0 {
+					quota.addTokens(&tt.key, s.tokens)
+				}
+				err := quota.acquire(&tt.key)
+				if s.wantErr == "" {
+					if err != nil {
+						t.Fatalf("step %d: unexpected error %v", i, err)
+					}
+					continue
+				}
+				if err == nil || !strings.Contains(err.Error(), s.wantErr) {
+					t.Fatalf("step %d: err = %v, want %q", i, err, s.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestQuotaTrackerKeysIndependent(t *testing.T) {
+	quota := newQuotaTracker()
+	a := &KeyConfig{Key: "a", RequestsPerMinute: 1}
+	b := &KeyConfig{Key: "b", RequestsPerMinute: 1}
+
+	if err := quota.acquire(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := quota.acquire(a); err == nil {
+		t.Error("second request of key a accepted")
+	}
+	if err := quota.acquire(b); err != nil {
+		t.Errorf("key b limited by key a: %v", err)
+	}
+}
@@ -0,0 +1,505 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ojbkgo/llm-sdk/cmd/internal/providers"
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/utils"
+)
+
+const (
+	// maxBodySize 请求体的最大长度
+	maxBodySize = 10 * 1024 * 1024
+	// statusClientClosed 客户端提前断开时记录到用量日志的状态码（沿用Nginx的约定）
+	statusClientClosed = 499
+)
+
+// gateway 将OpenAI格式的请求转发给各提供商的客户端
+type gateway struct {
+	config *Config
+	keys   map[string]*KeyConfig
+	quota  *quotaTracker
+	usage  *usageLogger
+
+	mu      sync.Mutex
+	clients map[string]api.LLMClient
+}
+
+func newGateway(config *Config, usageLog io.Writer) *gateway {
+	keys := make(map[string]*KeyConfig, len(config.Keys))
+	for i := range config.Keys {
+		keys[config.Keys[i].Key] = &config.Keys[i]
+	}
+	return &gateway{
+		config:  config,
+		keys:    keys,
+		quota:   newQuotaTracker(),
+		usage:   newUsageLogger(usageLog),
+		clients: make(map[string]api.LLMClient),
+	}
+}
+
+// handler 返回网关的HTTP处理器
+func (g *gateway) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/chat/completions", g.handleChatCompletions)
+	mux.HandleFunc("/v1/embeddings", g.handleEmbeddings)
+	mux.HandleFunc("/v1/models", g.handleModels)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return mux
+}
+
+// handleChatCompletions 处理/v1/chat/completions请求
+func (g *gateway) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	record := &usageRecord{Time: time.Now(), Endpoint: r.URL.Path}
+	key, ok := g.begin(w, r, record)
+	if !ok {
+		return
+	}
+	defer g.finish(key, record)
+
+	var body chatCompletionRequest
+	if err := decodeBody(r, &body); err != nil {
+		g.fail(w, record, http.StatusBadRequest, string(api.ErrorTypeInvalidRequest), err.Error(), "")
+		return
+	}
+	request, err := body.toRequest()
+	if err != nil {
+		g.fail(w, record, http.StatusBadRequest, string(api.ErrorTypeInvalidRequest), err.Error(), "")
+		return
+	}
+	record.Model = body.Model
+	record.Stream = body.Stream
+
+	client, ok := g.prepare(w, key, record, request)
+	if !ok {
+		return
+	}
+
+	if request.Stream {
+		g.streamChat(w, r, client, request, body.includeUsage(), record)
+		return
+	}
+
+	response, err := client.Complete(r.Context(), request)
+	if err != nil {
+		g.failWithError(w, record, err)
+		return
+	}
+	if response.ID == "" {
+		response.ID = newCompletionID()
+	}
+	if response.Created == 0 {
+		response.Created = time.Now().Unix()
+	}
+	if response.Choices == nil {
+		response.Choices = []api.Choice{}
+	}
+	response.Object = "chat.completion"
+	record.setUsage(&response.Usage)
+
+	writeJSON(w, http.StatusOK, response)
+	record.Status = http.StatusOK
+}
+
+// streamChat 以SSE格式转发流式响应，结束时发送[DONE]
+func (g *gateway) streamChat(w http.ResponseWriter, r *http.Request, client api.LLMClient, request *api.Request, includeUsage bool, record *usageRecord) {
+	stream, err := client.CompleteStream(r.Context(), request)
+	if err != nil {
+		g.failWithError(w, record, err)
+		return
+	}
+
+	utils.SetSSEHeaders(w.Header())
+	w.WriteHeader(http.StatusOK)
+	record.Status = http.StatusOK
+
+	id := newCompletionID()
+	created := time.Now().Unix()
+	// 上游没有返回用量时按转发的内容估算输出token数
+	completionTokens := 0
+	usage, err := utils.RelayStream(r.Context(), w, stream, func(options *utils.RelayOptions) {
+		options.Transform = func(chunk *api.ResponseChunk) interface{} {
+			for i := range chunk.Choices {
+				completionTokens += estimateMessageTokens(&chunk.Choices[i].Delta)
+			}
+			if !includeUsage {
+				chunk.Usage = nil
+			}
+			if len(chunk.Choices) == 0 && chunk.Usage == nil {
+				return nil
+			}
+			chunk.ID = id
+			chunk.Object = "chat.completion.chunk"
+			chunk.Created = created
+			if chunk.Model == "" {
+				chunk.Model = request.Model
+			}
+			return chunk
+		}
+		options.OnDone = func(writer *utils.SSEWriter, usage *api.Usage) error {
+			if includeUsage && usage != nil {
+				if err := writer.WriteJSON("", &api.ResponseChunk{
+					ID:      id,
+					Object:  "chat.completion.chunk",
+					Created: created,
+					Model:   request.Model,
+					Choices: []api.ChunkChoice{},
+					Usage:   usage,
+				}); err != nil {
+					return err
+				}
+			}
+			return writer.WriteData("[DONE]")
+		}
+		// 响应头已发送，只能以数据事件的形式返回错误
+		options.OnError = func(writer *utils.SSEWriter, err error) error {
+			_, errType := errorStatus(err)
+			return writer.WriteJSON("", errorResponse{Error: errorBody{Message: err.Error(), Type: errType}})
+		}
+	})
+	if usage != nil && (usage.TotalTokens > 0 || usage.PromptTokens+usage.CompletionTokens > 0) {
+		record.setUsage(usage)
+	} else {
+		// 避免客户端通过流式请求绕过token配额
+		record.setEstimatedUsage(estimateRequestTokens(request), completionTokens)
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		// 客户端在响应结束前断开
+		record.Status, record.ErrorType = statusClientClosed, "client_closed"
+	case err != nil:
+		record.Status, record.ErrorType = errorStatus(err)
+	}
+}
+
+// handleEmbeddings 处理/v1/embeddings请求
+//
+// SDK的Embedding接口不接收模型参数，model只用于选择提供商，实际使用提供商的默认嵌入模型。
+func (g *gateway) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
+	record := &usageRecord{Time: time.Now(), Endpoint: r.URL.Path}
+	key, ok := g.begin(w, r, record)
+	if !ok {
+		return
+	}
+	defer g.finish(key, record)
+
+	var body embeddingRequest
+	if err := decodeBody(r, &body); err != nil {
+		g.fail(w, record, http.StatusBadRequest, string(api.ErrorTypeInvalidRequest), err.Error(), "")
+		return
+	}
+	inputs, err := parseStringOrList(body.Input)
+	if err != nil || len(inputs) == 0 {
+		g.fail(w, record, http.StatusBadRequest, string(api.ErrorTypeInvalidRequest), "input必须是非空的字符串或字符串数组", "")
+		return
+	}
+	if body.Model == "" {
+		g.fail(w, record, http.StatusBadRequest, string(api.ErrorTypeInvalidRequest), "model不能为空", "")
+		return
+	}
+	record.Model = body.Model
+
+	request := &api.Request{Model: body.Model}
+	client, ok := g.prepare(w, key, record, request)
+	if !ok {
+		return
+	}
+
+	response := embeddingResponse{
+		Object: "list",
+		Data:   make([]embeddingData, 0, len(inputs)),
+		Model:  body.Model,
+	}
+	// SDK的Embedding接口不返回用量，按输入估算token数
+	promptTokens := 0
+	for i, input := range inputs {
+		embedding, err := client.Embedding(r.Context(), input)
+		if err != nil {
+			g.failWithError(w, record, err)
+			return
+		}
+		response.Data = append(response.Data, embeddingData{Object: "embedding", Index: i, Embedding: embedding})
+		promptTokens += estimateTokens(input)
+	}
+	record.setEstimatedUsage(promptTokens, 0)
+
+	writeJSON(w, http.StatusOK, response)
+	record.Status = http.StatusOK
+}
+
+// handleModels 处理/v1/models请求，返回已配置API密钥的提供商的模型
+func (g *gateway) handleModels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, string(api.ErrorTypeInvalidRequest), "只支持GET请求", "")
+		return
+	}
+	key, err := g.authenticate(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, string(api.ErrorTypeAuthentication), err.Error(), "invalid_api_key")
+		return
+	}
+
+	list := modelList{Object: "list", Data: []modelObject{}}
+	seen := map[string]bool{}
+	add := func(id, owner string) {
+		if !seen[id] && key.allowsModel(id) {
+			seen[id] = true
+			list.Data = append(list.Data, modelObject{ID: id, Object: "model", OwnedBy: owner})
+		}
+	}
+	routed := make([]string, 0, len(g.config.Routes))
+	for model := range g.config.Routes {
+		routed = append(routed, model)
+	}
+	sort.Strings(routed)
+	for _, model := range routed {
+		if p, err := providers.Get(g.config.Routes[model]); err == nil && g.configured(p) {
+			add(model, p.Name)
+		}
+	}
+	for _, name := range providers.Names() {
+		p, _ := providers.Get(name)
+		if !g.configured(p) {
+			continue
+		}
+		for _, info := range p.Models() {
+			add(info.ID, p.Name)
+		}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// begin 检查请求方法并认证，失败时已写入错误响应
+func (g *gateway) begin(w http.ResponseWriter, r *http.Request, record *usageRecord) (*KeyConfig, bool) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, string(api.ErrorTypeInvalidRequest), "只支持POST请求", "")
+		return nil, false
+	}
+	key, err := g.authenticate(r)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, string(api.ErrorTypeAuthentication), err.Error(), "invalid_api_key")
+		return nil, false
+	}
+	record.Key = key.Name
+	return key, true
+}
+
+// prepare 检查模型权限和配额并选择客户端，失败时已写入错误响应
+//
+// 请求中的模型会被替换为上游使用的模型名称。
+func (g *gateway) prepare(w http.ResponseWriter, key *KeyConfig, record *usageRecord, request *api.Request) (api.LLMClient, bool) {
+	if !key.allowsModel(request.Model) {
+		g.fail(w, record, http.StatusForbidden, "permission_error", fmt.Sprintf("无权访问模型%s", request.Model), "model_not_allowed")
+		return nil, false
+	}
+
+	p, model, err := g.route(request.Model)
+	if err != nil {
+		g.fail(w, record, http.StatusNotFound, string(api.ErrorTypeInvalidRequest), err.Error(), "model_not_found")
+		return nil, false
+	}
+	record.Provider = p.Name
+
+	client, err := g.client(p)
+	if err != nil {
+		g.fail(w, record, http.StatusBadGateway, string(api.ErrorTypeServer), err.Error(), "")
+		return nil, false
+	}
+
+	if err := g.quota.acquire(key); err != nil {
+		g.fail(w, record, http.StatusTooManyRequests, string(api.ErrorTypeRateLimit), err.Error(), "quota_exceeded")
+		return nil, false
+	}
+
+	request.Model = model
+	return client, true
+}
+
+// finish 统计token用量并写入用量日志
+func (g *gateway) finish(key *KeyConfig, record *usageRecord) {
+	record.LatencyMS = time.Since(record.Time).Milliseconds()
+	if record.TotalTokens > 0 {
+		g.quota.addTokens(key, record.TotalTokens)
+	}
+	g.usage.log(record)
+}
+
+// authenticate 从Authorization或x-api-key请求头读取密钥，未配置密钥时允许匿名访问
+func (g *gateway) authenticate(r *http.Request) (*KeyConfig, error) {
+	if len(g.keys) == 0 {
+		return &KeyConfig{Name: "anonymous"}, nil
+	}
+
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if token == "" {
+		token = r.Header.Get("x-api-key")
+	}
+	if token == "" {
+		return nil, errors.New("缺少API密钥")
+	}
+	key, ok := g.keys[token]
+	if !ok {
+		return nil, errors.New("无效的API密钥")
+	}
+	return key, nil
+}
+
+// route 根据模型名称选择提供商，返回上游使用的模型名称
+//
+// 依次匹配配置中的路由、"提供商/模型"形式的前缀和models注册表。
+func (g *gateway) route(model string) (*providers.Provider, string, error) {
+	if name, ok := g.config.Routes[model]; ok {
+		p, err := providers.Get(name)
+		return p, model, err
+	}
+	if name, rest, ok := strings.Cut(model, "/"); ok {
+		if p, err := providers.Get(name); err == nil {
+			return p, rest, nil
+		}
+	}
+	if p, ok := providers.ForModel(model); ok {
+		return p, model, nil
+	}
+	return nil, "", fmt.Errorf("未知的模型%s，请在配置的routes中指定提供商或使用\"提供商/模型\"的形式", model)
+}
+
+// client 返回提供商的客户端，首次使用时创建
+func (g *gateway) client(p *providers.Provider) (api.LLMClient, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if client, ok := g.clients[p.Name]; ok {
+		return client, nil
+	}
+
+	pc := g.providerConfig(p)
+	if pc.APIKey == "" {
+		return nil, fmt.Errorf("网关未配置%s的API密钥", p.Name)
+	}
+	client, err := p.NewClient(func(options *api.ClientOptions) {
+		options.APIKey = pc.APIKey
+		if pc.BaseURL != "" {
+			options.BaseURL = pc.BaseURL
+		}
+		options.Timeout = g.config.Timeout
+		options.StrictParams = g.config.StrictParams
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建%s客户端失败: %w", p.Name, err)
+	}
+	g.clients[p.Name] = client
+	return client, nil
+}
+
+// discoverModels 从已配置提供商的模型列表接口查询可用的模型并合并到models注册表中，失败时只记录日志
+func (g *gateway) discoverModels(ctx context.Context) {
+	for _, name := range providers.Names() {
+		p, _ := providers.Get(name)
+		if !g.configured(p) {
+			continue
+		}
+		client, err := g.client(p)
+		if err != nil {
+			log.Printf("查询%s的模型失败: %v", p.Name, err)
+			continue
+		}
+		if _, ok := client.(api.ModelLister); !ok {
+			continue
+		}
+
+		discoverCtx, cancel := context.WithTimeout(ctx, time.Duration(g.config.Timeout)*time.Second)
+		added, err := p.Discover(discoverCtx, client)
+		cancel()
+		if err != nil {
+			log.Printf("查询%s的模型失败: %v", p.Name, err)
+			continue
+		}
+		log.Printf("已从%s发现%d个新模型", p.Name, added)
+	}
+}
+
+// providerConfig 返回提供商的配置，未配置API密钥时读取环境变量
+func (g *gateway) providerConfig(p *providers.Provider) ProviderConfig {
+	pc := g.config.Providers[p.Name]
+	if pc.APIKey == "" {
+		pc.APIKey = os.Getenv(p.EnvKey)
+	}
+	return pc
+}
+
+// configured 判断提供商是否配置了API密钥
+func (g *gateway) configured(p *providers.Provider) bool {
+	return g.providerConfig(p).APIKey != ""
+}
+
+// fail 写入错误响应并记录到用量日志
+func (g *gateway) fail(w http.ResponseWriter, record *usageRecord, status int, errType, message, code string) {
+	record.Status = status
+	record.ErrorType = errType
+	writeError(w, status, errType, message, code)
+}
+
+// failWithError 将SDK错误写入错误响应
+func (g *gateway) failWithError(w http.ResponseWriter, record *usageRecord, err error) {
+	status, errType := errorStatus(err)
+	message, code := err.Error(), ""
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) {
+		message, code = apiErr.Message, apiErr.Code
+	}
+	g.fail(w, record, status, errType, message, code)
+}
+
+// setUsage 记录token用量
+func (r *usageRecord) setUsage(usage *api.Usage) {
+	r.PromptTokens = usage.PromptTokens
+	r.CompletionTokens = usage.CompletionTokens
+	r.TotalTokens = usage.TotalTokens
+	if r.TotalTokens == 0 {
+		r.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+}
+
+// setEstimatedUsage 记录估算的token用量
+func (r *usageRecord) setEstimatedUsage(promptTokens, completionTokens int) {
+	r.PromptTokens = promptTokens
+	r.CompletionTokens = completionTokens
+	r.TotalTokens = promptTokens + completionTokens
+	r.Estimated = true
+}
+
+// decodeBody 解析JSON请求体
+func decodeBody(r *http.Request, v interface{}) error {
+	body := http.MaxBytesReader(nil, r.Body, maxBodySize)
+	if err := json.NewDecoder(body).Decode(v); err != nil {
+		return fmt.Errorf("无法解析请求体: %w", err)
+	}
+	return nil
+}
+
+// writeJSON 写入JSON响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError 写入OpenAI格式的错误响应
+func writeError(w http.ResponseWriter, status int, errType, message, code string) {
+	writeJSON(w, status, errorResponse{Error: errorBody{Message: message, Type: errType, Code: code}})
+}
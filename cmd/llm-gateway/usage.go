@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// usageRecord 定义用量日志中的一行
+type usageRecord struct {
+	Time     time.Time `json:"time"`
+	Key      string    `json:"key"`
+	Endpoint string    `json:"endpoint"`
+	Model    string    `json:"model,omitempty"`
+	Provider string    `json:"provider,omitempty"`
+	Stream   bool      `json:"stream,omitempty"`
+	Status   int       `json:"status"`
+	// ErrorType 请求失败时的错误类型
+	ErrorType string `json:"error_type,omitempty"`
+
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+	// Estimated 为true表示上游没有返回用量，token数是网关估算的
+	Estimated bool `json:"estimated,omitempty"`
+
+	LatencyMS int64 `json:"latency_ms"`
+}
+
+// usageLogger 将用量记录逐行写入日志
+type usageLogger struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func newUsageLogger(w io.Writer) *usageLogger {
+	return &usageLogger{encoder: json.NewEncoder(w)}
+}
+
+// log 写入一条记录，写入失败不影响请求
+func (l *usageLogger) log(record *usageRecord) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.encoder.Encode(record); err != nil {
+		log.Printf("写入用量日志失败: %v", err)
+	}
+}
+
+// estimateTokens 粗略估算文本的token数：非ASCII字符（如汉字）每个按1个token计算，其余每4个字符按1个token计算
+//
+// 只用于上游没有返回用量时统计配额，宁可偏多也不要偏少。
+func estimateTokens(text string) int {
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return other + (ascii+3)/4
+}
+
+// estimateMessageTokens 估算消息的token数，包括内容、思考内容和工具调用参数
+func estimateMessageTokens(message *api.Message) int {
+	tokens := estimateTokens(message.Content) + estimateTokens(message.ReasoningContent)
+	for _, part := range message.Parts {
+		tokens += estimateTokens(part.Text)
+	}
+	for _, call := range message.ToolCalls {
+		tokens += estimateTokens(call.Function.Name) + estimateTokens(call.Function.Arguments)
+	}
+	return tokens
+}
+
+// estimateRequestTokens 估算请求中所有消息的token数
+func estimateRequestTokens(request *api.Request) int {
+	tokens := 0
+	for i := range request.Messages {
+		tokens += estimateMessageTokens(&request.Messages[i])
+	}
+	return tokens
+}
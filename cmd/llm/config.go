@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// 默认配置
+const (
+	defaultTimeout  = 300
+	maxAttachSize   = 1024 * 1024
+	configEnvKey    = "LLM_CONFIG"
+	providerEnvKey  = "LLM_PROVIDER"
+	modelEnvKey     = "LLM_MODEL"
+	systemEnvKey    = "LLM_SYSTEM"
+	baseURLEnvKey   = "LLM_BASE_URL"
+	defaultConfigFn = "config.json"
+)
+
+// Config 定义配置文件的结构，优先级低于环境变量和命令行参数
+type Config struct {
+	Provider    string   `json:"provider,omitempty"`
+	Model       string   `json:"model,omitempty"`
+	System      string   `json:"system,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+	// Timeout 请求超时时间（秒）
+	Timeout int `json:"timeout,omitempty"`
+	// Providers 按提供商名称配置API密钥和BaseURL
+	Providers map[string]ProviderConfig `json:"providers,omitempty"`
+}
+
+// ProviderConfig 定义单个提供商的配置
+type ProviderConfig struct {
+	APIKey  string `json:"api_key,omitempty"`
+	BaseURL string `json:"base_url,omitempty"`
+}
+
+// defaultConfigPath 返回默认的配置文件路径
+func defaultConfigPath() string {
+	if path := os.Getenv(configEnvKey); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "llm", defaultConfigFn)
+}
+
+// loadConfig 读取配置文件；使用默认路径且文件不存在时返回空配置
+func loadConfig(path string, explicit bool) (*Config, error) {
+	config := &Config{}
+	if path == "" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !explicit {
+		return config, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("解析配置文件%s失败: %w", path, err)
+	}
+	return config, nil
+}
+
+// applyEnv 用环境变量覆盖配置文件中的值
+func (c *Config) applyEnv() {
+	if v := os.Getenv(providerEnvKey); v != "" {
+		c.Provider = v
+	}
+	if v := os.Getenv(modelEnvKey); v != "" {
+		c.Model = v
+	}
+	if v := os.Getenv(systemEnvKey); v != "" {
+		c.System = v
+	}
+}
+
+// providerConfig 返回提供商的配置，环境变量优先
+func (c *Config) providerConfig(name, envKey string) ProviderConfig {
+	pc := c.Providers[name]
+	if v := os.Getenv(envKey); v != "" {
+		pc.APIKey = v
+	}
+	if v := os.Getenv(baseURLEnvKey); v != "" {
+		pc.BaseURL = v
+	}
+	return pc
+}
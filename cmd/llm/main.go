@@ -0,0 +1,413 @@
+// llm 是基于SDK的命令行工具，支持单次提问、交互式对话和脚本调用
+//
+// 用法:
+//
+//	llm [参数] [提示词...]
+//	echo "内容" | llm -m deepseek-chat "总结一下"
+//	llm -i -p anthropic
+//
+// 配置优先级: 命令行参数 > 环境变量 > 配置文件（默认为用户配置目录下的llm/config.json）。
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ojbkgo/llm-sdk/cmd/internal/providers"
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// stringList 实现可重复指定的命令行参数
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// cli 保存一次运行的配置和客户端
+type cli struct {
+	provider    *providers.Provider
+	client      api.LLMClient
+	config      *Config
+	model       string
+	system      string
+	temperature *float64
+	maxTokens   *int
+	stream      bool
+	jsonOutput  bool
+
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("llm", flag.ContinueOnError)
+	var (
+		configPath  = flags.String("config", defaultConfigPath(), "配置文件路径（JSON）")
+		provider    = flags.String("p", "", "提供商名称，未指定时根据模型推断")
+		model       = flags.String("m", "", "模型ID")
+		system      = flags.String("s", "", "系统提示词")
+		temperature = flags.String("t", "", "采样温度")
+		maxTokens   = flags.Int("max-tokens", 0, "最大生成token数")
+		timeout     = flags.Int("timeout", 0, "请求超时时间（秒）")
+		noStream    = flags.Bool("no-stream", false, "等待完整响应后再输出")
+		jsonOutput  = flags.Bool("json", false, "以JSON格式输出完整响应（隐含-no-stream）")
+		interactive = flags.Bool("i", false, "进入交互模式")
+		listModels  = flags.Bool("list-models", false, "列出已知的模型")
+		refresh     = flags.Bool("refresh-models", false, "列出模型前从提供商接口查询当前可用的模型（隐含-list-models）")
+		files       stringList
+	)
+	flags.Var(&files, "f", "附加文件内容到提示词，可重复指定")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "用法: llm [参数] [提示词...]\n\n")
+		flags.PrintDefaults()
+		fmt.Fprintf(flags.Output(), "\n支持的提供商: %s\n", strings.Join(providers.Names(), ", "))
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	explicitConfig := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "config" {
+			explicitConfig = true
+		}
+	})
+	config, err := loadConfig(*configPath, explicitConfig || os.Getenv(configEnvKey) != "")
+	if err != nil {
+		return err
+	}
+	config.applyEnv()
+
+	// 命令行参数覆盖配置
+	if *provider != "" {
+		config.Provider = *provider
+	}
+	if *model != "" {
+		config.Model = *model
+		// 只在命令行指定模型时，以模型所属的提供商为准
+		if _, ok := providers.ForModel(*model); ok && *provider == "" {
+			config.Provider = ""
+		}
+	}
+	if *system != "" {
+		config.System = *system
+	}
+	if *temperature != "" {
+		t, err := strconv.ParseFloat(*temperature, 64)
+		if err != nil {
+			return fmt.Errorf("无效的温度: %s", *temperature)
+		}
+		config.Temperature = &t
+	}
+	if *maxTokens > 0 {
+		config.MaxTokens = *maxTokens
+	}
+	if *timeout > 0 {
+		config.Timeout = *timeout
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultTimeout
+	}
+
+	if *listModels || *refresh {
+		if *refresh {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Timeout)*time.Second)
+			defer cancel()
+			if err := refreshModels(ctx, config, config.Provider, os.Stderr); err != nil {
+				return err
+			}
+		}
+		return printModels(os.Stdout, config.Provider)
+	}
+
+	c := &cli{
+		config:      config,
+		system:      config.System,
+		temperature: config.Temperature,
+		stream:      !*noStream && !*jsonOutput,
+		jsonOutput:  *jsonOutput,
+		stdout:      os.Stdout,
+		stderr:      os.Stderr,
+	}
+	if config.MaxTokens > 0 {
+		c.maxTokens = &config.MaxTokens
+	}
+	if err := c.selectModel(config.Provider, config.Model); err != nil {
+		return err
+	}
+
+	attachments, err := readAttachments(files)
+	if err != nil {
+		return err
+	}
+
+	prompt := strings.Join(flags.Args(), " ")
+	if *interactive || (prompt == "" && isTerminal(os.Stdin)) {
+		return c.repl(os.Stdin, attachments)
+	}
+
+	// 单次模式：标准输入不是终端时读取其内容
+	if !isTerminal(os.Stdin) {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("读取标准输入失败: %w", err)
+		}
+		if input := strings.TrimSpace(string(data)); input != "" {
+			prompt = joinNonEmpty(prompt, input)
+		}
+	}
+	prompt = joinNonEmpty(prompt, attachments)
+	if prompt == "" {
+		return errors.New("提示词不能为空")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	_, err = c.send(ctx, c.messages(nil, prompt))
+	return err
+}
+
+// selectModel 根据提供商和模型选择客户端
+//
+// 只指定模型时根据models注册表推断提供商；都未指定时使用第一个设置了API密钥的提供商及其默认模型。
+func (c *cli) selectModel(providerName, model string) error {
+	var p *providers.Provider
+	switch {
+	case providerName != "":
+		var err error
+		if p, err = providers.Get(providerName); err != nil {
+			return err
+		}
+	case model != "":
+		var ok bool
+		if p, ok = providers.ForModel(model); !ok {
+			return fmt.Errorf("无法根据模型%s推断提供商，请使用-p指定", model)
+		}
+	default:
+		var ok bool
+		if p, ok = providers.FirstConfigured(); !ok {
+			return errors.New("未找到可用的API密钥，请设置提供商的API密钥环境变量或配置文件")
+		}
+	}
+	if model == "" {
+		model = p.DefaultModel
+	}
+
+	client, err := newClient(c.config, p)
+	if err != nil {
+		return err
+	}
+
+	c.provider = p
+	c.client = client
+	c.model = model
+	return nil
+}
+
+// newClient 使用配置中的API密钥创建提供商的客户端
+func newClient(config *Config, p *providers.Provider) (api.LLMClient, error) {
+	pc := config.providerConfig(p.Name, p.EnvKey)
+	if pc.APIKey == "" {
+		return nil, fmt.Errorf("请设置%s环境变量或在配置文件中配置%s的api_key", p.EnvKey, p.Name)
+	}
+
+	client, err := p.NewClient(func(options *api.ClientOptions) {
+		options.APIKey = pc.APIKey
+		if pc.BaseURL != "" {
+			options.BaseURL = pc.BaseURL
+		}
+		options.Timeout = config.Timeout
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建%s客户端失败: %w", p.Name, err)
+	}
+	return client, nil
+}
+
+// conversation 返回加上系统提示词的对话历史
+func (c *cli) conversation(history []api.Message) []api.Message {
+	messages := make([]api.Message, 0, len(history)+2)
+	if c.system != "" {
+		messages = append(messages, api.Message{Role: api.RoleSystem, Content: c.system})
+	}
+	return append(messages, history...)
+}
+
+// messages 在对话历史后追加用户消息
+func (c *cli) messages(history []api.Message, prompt string) []api.Message {
+	return append(c.conversation(history), api.Message{Role: api.RoleUser, Content: prompt})
+}
+
+// send 发送请求并输出结果，返回助手回复的文本
+func (c *cli) send(ctx context.Context, messages []api.Message) (string, error) {
+	request := &api.Request{
+		Model:       c.model,
+		Messages:    messages,
+		Temperature: c.temperature,
+		MaxTokens:   c.maxTokens,
+		Stream:      c.stream,
+	}
+
+	if c.stream {
+		stream, err := c.client.CompleteStream(ctx, request)
+		if err != nil {
+			return "", err
+		}
+		var reply bytes.Buffer
+		err = api.StreamToWriter(stream, io.MultiWriter(c.stdout, &reply))
+		fmt.Fprintln(c.stdout)
+		return reply.String(), err
+	}
+
+	response, err := c.client.Complete(ctx, request)
+	if err != nil {
+		return "", err
+	}
+
+	reply := ""
+	if len(response.Choices) > 0 {
+		reply = response.Choices[0].Message.Content
+	}
+
+	if c.jsonOutput {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		return reply, encoder.Encode(response)
+	}
+	_, err = fmt.Fprintln(c.stdout, reply)
+	return reply, err
+}
+
+// printModels 输出models注册表中的模型
+func printModels(w io.Writer, providerName string) error {
+	names := providers.Names()
+	if providerName != "" {
+		p, err := providers.Get(providerName)
+		if err != nil {
+			return err
+		}
+		names = []string{p.Name}
+	}
+
+	for _, name := range names {
+		p, _ := providers.Get(name)
+		for _, info := range p.Models() {
+			marker := ""
+			if info.ID == p.DefaultModel {
+				marker = " (默认)"
+			}
+			fmt.Fprintf(w, "%-10s %-32s %8d  %s%s\n", p.Name, info.ID, info.MaxTokens, strings.Join(info.Capabilities, ","), marker)
+		}
+	}
+	return nil
+}
+
+// refreshModels 从提供商接口查询当前可用的模型并合并到models注册表中
+//
+// 指定了提供商时查询失败即返回错误；否则查询所有配置了API密钥且支持模型列表的提供商，失败时输出警告并继续。
+func refreshModels(ctx context.Context, config *Config, providerName string, stderr io.Writer) error {
+	if providerName != "" {
+		p, err := providers.Get(providerName)
+		if err != nil {
+			return err
+		}
+		client, err := newClient(config, p)
+		if err != nil {
+			return err
+		}
+		if _, err := p.Discover(ctx, client); err != nil {
+			return fmt.Errorf("查询%s的模型失败: %w", p.Name, err)
+		}
+		return nil
+	}
+
+	for _, name := range providers.Names() {
+		p, _ := providers.Get(name)
+		if config.providerConfig(p.Name, p.EnvKey).APIKey == "" {
+			continue
+		}
+		client, err := newClient(config, p)
+		if err != nil {
+			fmt.Fprintf(stderr, "警告: %v\n", err)
+			continue
+		}
+		if _, ok := client.(api.ModelLister); !ok {
+			continue
+		}
+		if _, err := p.Discover(ctx, client); err != nil {
+			fmt.Fprintf(stderr, "警告: 查询%s的模型失败: %v\n", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// readAttachments 读取附加文件并拼接为提示词片段
+func readAttachments(paths []string) (string, error) {
+	parts := make([]string, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", fmt.Errorf("读取附件失败: %w", err)
+		}
+		if info.Size() > maxAttachSize {
+			return "", fmt.Errorf("附件%s超过%d字节的限制", path, maxAttachSize)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("读取附件失败: %w", err)
+		}
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("附件%s不是UTF-8文本文件", path)
+		}
+		parts = append(parts, fmt.Sprintf("文件 %s:\n```\n%s\n```", path, strings.TrimRight(string(data), "\n")))
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// joinNonEmpty 用空行连接非空的文本片段
+func joinNonEmpty(parts ...string) string {
+	nonEmpty := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, "\n\n")
+}
+
+// isTerminal 判断文件是否为终端
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
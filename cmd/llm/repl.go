@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/ojbkgo/llm-sdk/cmd/internal/providers"
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+const replHelp = `命令:
+  /model <模型ID>   切换模型（必要时切换提供商）
+  /system [文本]    设置系统提示词，不带参数时清除
+  /file <路径>      附加文件到下一条消息
+  /history         显示对话历史
+  /save <路径>      将对话历史保存为JSON
+  /reset           清空对话历史
+  /exit            退出（也可以按Ctrl-D）
+回复过程中按Ctrl-C可以中断当前回复。`
+
+// repl 运行交互式对话，对话历史在多轮之间保留
+func (c *cli) repl(in io.Reader, attachments string) error {
+	// 交互模式下Ctrl-C只中断当前回复，不退出程序
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	fmt.Fprintf(c.stderr, "%s / %s，输入/help查看命令\n", c.provider.Name, c.model)
+
+	var history []api.Message
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxAttachSize)
+	for {
+		fmt.Fprint(c.stderr, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(c.stderr)
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "/") {
+			quit, err := c.command(line, &history, &attachments)
+			if err != nil {
+				fmt.Fprintf(c.stderr, "错误: %v\n", err)
+			}
+			if quit {
+				return nil
+			}
+			continue
+		}
+
+		prompt := joinNonEmpty(line, attachments)
+		reply, err := c.sendInterruptible(c.messages(history, prompt), interrupts)
+		if err != nil {
+			fmt.Fprintf(c.stderr, "错误: %v\n", err)
+			continue
+		}
+		attachments = ""
+		history = append(history,
+			api.Message{Role: api.RoleUser, Content: prompt},
+			api.Message{Role: api.RoleAssistant, Content: reply},
+		)
+	}
+}
+
+// sendInterruptible 发送请求，收到中断信号时取消该请求
+func (c *cli) sendInterruptible(messages []api.Message, interrupts <-chan os.Signal) (string, error) {
+	// 丢弃在等待输入时收到的中断信号
+	select {
+	case <-interrupts:
+	default:
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-interrupts:
+			cancel()
+		case <-done:
+		}
+	}()
+
+	return c.send(ctx, messages)
+}
+
+// command 执行REPL命令，返回是否退出
+func (c *cli) command(line string, history *[]api.Message, attachments *string) (bool, error) {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "/exit", "/quit":
+		return true, nil
+	case "/help":
+		fmt.Fprintln(c.stderr, replHelp)
+	case "/reset":
+		*history = nil
+		*attachments = ""
+		fmt.Fprintln(c.stderr, "对话历史已清空")
+	case "/model":
+		if arg == "" {
+			fmt.Fprintf(c.stderr, "%s / %s\n", c.provider.Name, c.model)
+			return false, nil
+		}
+		// 模型属于其他提供商时重新创建客户端，否则沿用当前提供商
+		providerName := c.provider.Name
+		if p, ok := providers.ForModel(arg); ok {
+			providerName = p.Name
+		}
+		if err := c.selectModel(providerName, arg); err != nil {
+			return false, err
+		}
+		fmt.Fprintf(c.stderr, "已切换到 %s / %s\n", c.provider.Name, c.model)
+	case "/system":
+		c.system = arg
+		if arg == "" {
+			fmt.Fprintln(c.stderr, "已清除系统提示词")
+		} else {
+			fmt.Fprintln(c.stderr, "已设置系统提示词")
+		}
+	case "/file":
+		if arg == "" {
+			return false, fmt.Errorf("用法: /file <路径>")
+		}
+		content, err := readAttachments([]string{arg})
+		if err != nil {
+			return false, err
+		}
+		*attachments = joinNonEmpty(*attachments, content)
+		fmt.Fprintf(c.stderr, "已附加%s，将随下一条消息发送\n", arg)
+	case "/history":
+		for _, message := range *history {
+			fmt.Fprintf(c.stderr, "[%s] %s\n", message.Role, message.Content)
+		}
+	case "/save":
+		if arg == "" {
+			return false, fmt.Errorf("用法: /save <路径>")
+		}
+		data, err := json.MarshalIndent(c.conversation(*history), "", "  ")
+		if err != nil {
+			return false, err
+		}
+		if err := os.WriteFile(arg, data, 0o644); err != nil {
+			return false, fmt.Errorf("保存对话历史失败: %w", err)
+		}
+		fmt.Fprintf(c.stderr, "已保存到%s\n", arg)
+	default:
+		return false, fmt.Errorf("未知命令%s，输入/help查看命令", name)
+	}
+	return false, nil
+}
@@ -30,7 +30,7 @@ func main() {
 	// 准备请求
 	temperature := 0.7
 	request := &api.Request{
-		Model: models.Claude3Sonnet,
+		Model: models.ClaudeSonnet4,
 		Messages: []api.Message{
 			{
 				Role:    api.RoleSystem,
@@ -44,7 +44,7 @@ func main() {
 
 	if geminiKey := os.Getenv("GEMINI_API_KEY"); geminiKey != "" {
 		fmt.Println("\n=== Google (Gemini Pro) ===")
-		streamFromProvider("gemini", geminiKey, models.GeminiPro, promptText)
+		streamFromProvider("gemini", geminiKey, models.Gemini25Flash, promptText)
 	} else {
 		fmt.Println("\n=== Google (未设置API密钥) ===")
 	}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -40,6 +41,9 @@ func main() {
 
 	fmt.Println("\n=== 演示 4: 使用StreamProcessor接口 ===")
 	streamProcessorExample(client)
+
+	fmt.Println("\n=== 演示 5: 通过通道接收并设置超时 ===")
+	channelStreamingExample(client)
 }
 
 // 基本流式输出示例
@@ -76,7 +80,7 @@ func basicStreamingExample(client api.LLMClient) {
 		chunk, err := stream.Recv()
 		if err != nil {
 			// 检查是否是正常的EOF
-			if err.Error() == "EOF" {
+			if err == io.EOF {
 				break
 			}
 			fmt.Printf("接收响应块出错: %v\n", err)
@@ -91,7 +95,7 @@ func basicStreamingExample(client api.LLMClient) {
 			}
 		}
 	}
-	fmt.Println("\n")
+	fmt.Print("\n\n")
 }
 
 // 带进度显示的流式输出示例
@@ -134,7 +138,7 @@ func progressStreamingExample(client api.LLMClient) {
 	for {
 		chunk, err := stream.Recv()
 		if err != nil {
-			if err.Error() == "EOF" {
+			if err == io.EOF {
 				break
 			}
 			fmt.Printf("接收响应块出错: %v\n", err)
@@ -253,3 +257,43 @@ func streamProcessorExample(client api.LLMClient) {
 		fmt.Printf("流处理失败: %v\n", err)
 	}
 }
+
+// 通过通道接收流式响应示例
+func channelStreamingExample(client api.LLMClient) {
+	// 准备请求
+	request := &api.Request{
+		Model: models.DeepSeekChat,
+		Messages: []api.Message{
+			{
+				Role:    api.RoleUser,
+				Content: "用三句话介绍一下Go的goroutine。",
+			},
+		},
+	}
+
+	// 整个流最多接收30秒，超时后流会被关闭
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	stream, err := client.CompleteStream(ctx, request)
+	if err != nil {
+		fmt.Printf("请求失败: %v\n", err)
+		return
+	}
+
+	// 通道关闭时流已被关闭
+	for result := range api.StreamChannel(ctx, stream, 16) {
+		if result.Err != nil {
+			fmt.Printf("\n接收响应块出错: %v\n", result.Err)
+			return
+		}
+		if len(result.Chunk.Choices) > 0 {
+			fmt.Print(result.Chunk.Choices[0].Delta.Content)
+		}
+	}
+	if ctx.Err() != nil {
+		fmt.Printf("\n已超时: %v\n", ctx.Err())
+		return
+	}
+	fmt.Println()
+}
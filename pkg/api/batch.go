@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"time"
+)
+
+// BatchClient 定义异步批处理的统一接口
+//
+// 批处理任务提交后在后台执行，调用方通过Status轮询，任务结束后通过Results读取结果。
+type BatchClient interface {
+	// Submit 提交一批请求，返回新建的任务
+	Submit(ctx context.Context, requests []BatchRequest) (*BatchJob, error)
+
+	// Status 查询任务状态
+	Status(ctx context.Context, jobID string) (*BatchJob, error)
+
+	// Cancel 取消任务，已在执行中的请求仍可能完成
+	Cancel(ctx context.Context, jobID string) error
+
+	// Results 读取已结束任务的结果
+	Results(ctx context.Context, jobID string) (BatchResultIterator, error)
+}
+
+// BatchClientProvider 由支持原生批处理接口的客户端实现
+type BatchClientProvider interface {
+	// BatchClient 返回基于提供商批处理接口的客户端
+	BatchClient() BatchClient
+}
+
+// NewBatchClient 返回客户端对应的批处理客户端
+//
+// 客户端实现了BatchClientProvider时使用提供商的批处理接口，
+// 否则退化为本地以最多concurrency个并发执行的实现。
+func NewBatchClient(client LLMClient, concurrency int) BatchClient {
+	if provider, ok := client.(BatchClientProvider); ok {
+		return provider.BatchClient()
+	}
+	return NewLocalBatchClient(client, concurrency)
+}
+
+// BatchRequest 定义批处理中的单个请求
+type BatchRequest struct {
+	// CustomID 用于在结果中定位该请求，同一批次内必须唯一；为空时按序号生成
+	CustomID string   `json:"custom_id"`
+	Request  *Request `json:"request"`
+}
+
+// BatchStatus 定义标准化的批处理任务状态
+type BatchStatus string
+
+const (
+	// BatchStatusPending 任务已提交，正在校验或排队
+	BatchStatusPending BatchStatus = "pending"
+	// BatchStatusInProgress 任务正在执行
+	BatchStatusInProgress BatchStatus = "in_progress"
+	// BatchStatusCancelling 任务正在取消
+	BatchStatusCancelling BatchStatus = "cancelling"
+	// BatchStatusCompleted 任务已结束，部分请求可能失败
+	BatchStatusCompleted BatchStatus = "completed"
+	// BatchStatusFailed 任务整体失败
+	BatchStatusFailed BatchStatus = "failed"
+	// BatchStatusCancelled 任务已取消
+	BatchStatusCancelled BatchStatus = "cancelled"
+	// BatchStatusExpired 任务未在时限内完成
+	BatchStatusExpired BatchStatus = "expired"
+)
+
+// BatchJob 定义批处理任务
+type BatchJob struct {
+	ID     string      `json:"id"`
+	Status BatchStatus `json:"status"`
+	// RawStatus 提供商返回的原始状态
+	RawStatus string `json:"raw_status,omitempty"`
+
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+
+	CreatedAt time.Time `json:"created_at"`
+	// EndedAt 任务结束的时间，未结束时为零值
+	EndedAt time.Time `json:"ended_at,omitempty"`
+
+	// Warnings 提交时被忽略的参数等警告，只有Submit返回的任务包含
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Done 判断任务是否已结束
+func (j *BatchJob) Done() bool {
+	switch j.Status {
+	case BatchStatusCompleted, BatchStatusFailed, BatchStatusCancelled, BatchStatusExpired:
+		return true
+	}
+	return false
+}
+
+// BatchResult 定义批处理中单个请求的结果
+type BatchResult struct {
+	CustomID string    `json:"custom_id"`
+	Response *Response `json:"response,omitempty"`
+	// Error 请求失败、被取消或过期时不为空
+	Error error `json:"-"`
+}
+
+// BatchResultIterator 逐条读取批处理结果
+type BatchResultIterator interface {
+	// Next 读取下一条结果，全部读取完毕后返回io.EOF
+	Next() (*BatchResult, error)
+
+	// Close 释放底层资源
+	Close() error
+}
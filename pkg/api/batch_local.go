@@ -0,0 +1,232 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// defaultLocalBatchConcurrency 本地批处理的默认并发数
+const defaultLocalBatchConcurrency = 4
+
+// localBatchRetention 已结束的本地批处理任务在内存中保留的时间，超过后任务及其结果被删除
+const localBatchRetention = time.Hour
+
+// localBatchClient 在本地通过LLMClient执行批处理，用于没有批处理接口的提供商
+type localBatchClient struct {
+	client      LLMClient
+	concurrency int
+	// now 返回当前时间，测试中可以替换
+	now func() time.Time
+
+	mu   sync.Mutex
+	seq  int
+	jobs map[string]*localBatchJob
+}
+
+// localBatchJob 记录本地批处理任务的执行状态
+type localBatchJob struct {
+	job     BatchJob
+	results []BatchResult
+	cancel  context.CancelFunc
+}
+
+// NewLocalBatchClient 创建在本地执行的批处理客户端
+//
+// 任务提交后在后台以最多concurrency个并发调用client.Complete，concurrency不大于0时使用默认值。
+// 任务状态只保存在内存中，进程退出后无法恢复；任务结束一小时后会被删除，需要在此之前读取结果。
+func NewLocalBatchClient(client LLMClient, concurrency int) BatchClient {
+	if concurrency <= 0 {
+		concurrency = defaultLocalBatchConcurrency
+	}
+	return &localBatchClient{
+		client:      client,
+		concurrency: concurrency,
+		now:         time.Now,
+		jobs:        map[string]*localBatchJob{},
+	}
+}
+
+// Submit 提交一批请求并在后台执行
+func (c *localBatchClient) Submit(ctx context.Context, requests []BatchRequest) (*BatchJob, error) {
+	if len(requests) == 0 {
+		return nil, NewError(ErrorTypeInvalidRequest, "批处理请求不能为空", 0, nil)
+	}
+	for i, item := range requests {
+		if item.Request == nil {
+			return nil, NewError(ErrorTypeInvalidRequest, fmt.Sprintf("第%d个批处理请求为空", i+1), 0, nil)
+		}
+	}
+
+	// 任务与提交时的ctx无关，只能通过Cancel终止
+	runCtx, cancel := context.WithCancel(context.Background())
+
+	c.mu.Lock()
+	now := c.now()
+	c.evictLocked(now)
+	c.seq++
+	job := &localBatchJob{
+		job: BatchJob{
+			ID:        fmt.Sprintf("local-batch-%d", c.seq),
+			Status:    BatchStatusInProgress,
+			RawStatus: string(BatchStatusInProgress),
+			Total:     len(requests),
+			CreatedAt: now,
+		},
+		results: make([]BatchResult, len(requests)),
+		cancel:  cancel,
+	}
+	c.jobs[job.job.ID] = job
+	snapshot := job.job
+	c.mu.Unlock()
+
+	go c.run(runCtx, job, requests)
+
+	return &snapshot, nil
+}
+
+// run 以有限并发执行任务中的所有请求
+func (c *localBatchClient) run(ctx context.Context, job *localBatchJob, requests []BatchRequest) {
+	sem := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+
+	for i, item := range requests {
+		customID := item.CustomID
+		if customID == "" {
+			customID = fmt.Sprintf("request-%d", i)
+		}
+
+		acquired := false
+		select {
+		case sem <- struct{}{}:
+			acquired = true
+		case <-ctx.Done():
+		}
+		// 任务已取消时剩余的请求直接记为失败，不再启动goroutine
+		if err := ctx.Err(); err != nil {
+			if acquired {
+				<-sem
+			}
+			c.record(job, i, BatchResult{CustomID: customID, Error: NewError(ErrorTypeUnknown, "批处理已取消", 0, err)})
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, customID string, request *Request) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			result := BatchResult{CustomID: customID}
+			result.Response, result.Error = c.client.Complete(ctx, request)
+			c.record(job, i, result)
+		}(i, customID, item.Request)
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	if job.job.Status == BatchStatusCancelling {
+		job.job.Status = BatchStatusCancelled
+	} else {
+		job.job.Status = BatchStatusCompleted
+	}
+	job.job.RawStatus = string(job.job.Status)
+	job.job.EndedAt = c.now()
+	c.mu.Unlock()
+	job.cancel()
+}
+
+// record 保存第i个请求的结果并更新计数
+func (c *localBatchClient) record(job *localBatchJob, i int, result BatchResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	job.results[i] = result
+	if result.Error != nil {
+		job.job.Failed++
+	} else {
+		job.job.Succeeded++
+	}
+}
+
+// evictLocked 删除结束超过localBatchRetention的任务，调用方需要持有c.mu
+func (c *localBatchClient) evictLocked(now time.Time) {
+	for id, job := range c.jobs {
+		if job.job.Done() && now.Sub(job.job.EndedAt) > localBatchRetention {
+			delete(c.jobs, id)
+		}
+	}
+}
+
+// Status 查询任务状态
+func (c *localBatchClient) Status(ctx context.Context, jobID string) (*BatchJob, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictLocked(c.now())
+
+	job, ok := c.jobs[jobID]
+	if !ok {
+		return nil, NewError(ErrorTypeInvalidRequest, fmt.Sprintf("批处理任务不存在: %s", jobID), 0, nil)
+	}
+	snapshot := job.job
+	return &snapshot, nil
+}
+
+// Cancel 取消任务，尚未开始的请求不再执行
+func (c *localBatchClient) Cancel(ctx context.Context, jobID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictLocked(c.now())
+
+	job, ok := c.jobs[jobID]
+	if !ok {
+		return NewError(ErrorTypeInvalidRequest, fmt.Sprintf("批处理任务不存在: %s", jobID), 0, nil)
+	}
+	if job.job.Done() {
+		return nil
+	}
+	job.job.Status = BatchStatusCancelling
+	job.job.RawStatus = string(BatchStatusCancelling)
+	job.cancel()
+	return nil
+}
+
+// Results 读取已结束任务的结果，结果顺序与提交顺序一致
+func (c *localBatchClient) Results(ctx context.Context, jobID string) (BatchResultIterator, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictLocked(c.now())
+
+	job, ok := c.jobs[jobID]
+	if !ok {
+		return nil, NewError(ErrorTypeInvalidRequest, fmt.Sprintf("批处理任务不存在: %s", jobID), 0, nil)
+	}
+	if !job.job.Done() {
+		return nil, NewError(ErrorTypeInvalidRequest, fmt.Sprintf("批处理任务%s尚未结束", jobID), 0, nil)
+	}
+	return &sliceBatchResultIterator{results: job.results}, nil
+}
+
+// sliceBatchResultIterator 遍历内存中的批处理结果
+type sliceBatchResultIterator struct {
+	results []BatchResult
+	pos     int
+}
+
+// Next 读取下一条结果
+func (it *sliceBatchResultIterator) Next() (*BatchResult, error) {
+	if it.pos >= len(it.results) {
+		return nil, io.EOF
+	}
+	result := it.results[it.pos]
+	it.pos++
+	return &result, nil
+}
+
+// Close 实现BatchResultIterator接口
+func (it *sliceBatchResultIterator) Close() error {
+	return nil
+}
@@ -0,0 +1,262 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClient 是测试用的LLMClient，complete为空时回显最后一条消息
+type fakeClient struct {
+	complete func(ctx context.Context, request *Request) (*Response, error)
+
+	mu      sync.Mutex
+	calls   int
+	running int
+	peak    int
+}
+
+func (c *fakeClient) Complete(ctx context.Context, request *Request) (*Response, error) {
+	c.mu.Lock()
+	c.calls++
+	c.running++
+	if c.running > c.peak {
+		c.peak = c.running
+	}
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.running--
+		c.mu.Unlock()
+	}()
+
+	if c.complete != nil {
+		return c.complete(ctx, request)
+	}
+	content := request.Messages[len(request.Messages)-1].Content
+	return &Response{Choices: []Choice{{Message: Message{Role: RoleAssistant, Content: content}}}}, nil
+}
+
+func (c *fakeClient) CompleteStream(ctx context.Context, request *Request) (ResponseStream, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeClient) Embedding(ctx context.Context, input string) ([]float32, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeClient) stats() (calls, peak int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls, c.peak
+}
+
+// batchRequests 返回内容依次为texts的批处理请求
+func batchRequests(texts ...string) []BatchRequest {
+	requests := make([]BatchRequest, len(texts))
+	for i, text := range texts {
+		requests[i] = BatchRequest{Request: &Request{Model: "m", Messages: []Message{{Role: RoleUser, Content: text}}}}
+	}
+	return requests
+}
+
+// waitDone 轮询直到任务结束
+func waitDone(t *testing.T, client BatchClient, jobID string) *BatchJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := client.Status(context.Background(), jobID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Done() {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s not done", jobID)
+	return nil
+}
+
+// readResults 读取任务的全部结果
+func readResults(t *testing.T, client BatchClient, jobID string) []BatchResult {
+	t.Helper()
+	it, err := client.Results(context.Background(), jobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+
+	var results []BatchResult
+	for {
+		result, err := it.Next()
+		if err == io.EOF {
+			return results
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, *result)
+	}
+}
+
+func TestLocalBatchClient(t *testing.T) {
+	tests := []struct {
+		name          string
+		concurrency   int
+		texts         []string
+		fail          string
+		wantSucceeded int
+		wantFailed    int
+	}{
+		{name: "sequential", concurrency: 1, texts: []string{"a", "b", "c"}, wantSucceeded: 3},
+		{name: "concurrent", concurrency: 2, texts: []string{"a", "b", "c", "d", "e"}, wantSucceeded: 5},
+		{name: "partial failure", concurrency: 3, texts: []string{"a", "bad", "c"}, fail: "bad", wantSucceeded: 2, wantFailed: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := &fakeClient{}
+			llm.complete = func(ctx context.Context, request *Request) (*Response, error) {
+				// 留出时间让并发的请求重叠
+				time.Sleep(10 * time.Millisecond)
+				content := request.Messages[0].Content
+				if content == tt.fail {
+					return nil, NewError(ErrorTypeServer, "失败", 500, nil)
+				}
+				return &Response{Choices: []Choice{{Message: Message{Content: content}}}}, nil
+			}
+			client := NewLocalBatchClient(llm, tt.concurrency)
+
+			submitted, err := client.Submit(context.Background(), batchRequests(tt.texts...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			job := waitDone(t, client, submitted.ID)
+			if job.Status != BatchStatusCompleted || job.Succeeded != tt.wantSucceeded || job.Failed != tt.wantFailed {
+				t.Errorf("job = %+v", job)
+			}
+			if _, peak := llm.stats(); peak > tt.concurrency {
+				t.Errorf("peak concurrency %d exceeds %d", peak, tt.concurrency)
+			}
+
+			results := readResults(t, client, submitted.ID)
+			if len(results) != len(tt.texts) {
+				t.Fatalf("got %d results, want %d", len(results), len(tt.texts))
+			}
+			for i, result := range results {
+				if want := "request-" + string(rune('0'+i)); result.CustomID != want {
+					t.Errorf("result %d custom id = %q, want %q", i, result.CustomID, want)
+				}
+				if tt.texts[i] == tt.fail {
+					if result.Error == nil {
+						t.Errorf("result %d: want error", i)
+					}
+					continue
+				}
+				if result.Error != nil || result.Response.Choices[0].Message.Content != tt.texts[i] {
+					t.Errorf("result %d = %+v", i, result)
+				}
+			}
+		})
+	}
+}
+
+func TestLocalBatchClientSubmitValidation(t *testing.T) {
+	client := NewLocalBatchClient(&fakeClient{}, 1)
+	if _, err := client.Submit(context.Background(), nil); err == nil {
+		t.Error("empty batch accepted")
+	}
+	if _, err := client.Submit(context.Background(), []BatchRequest{{CustomID: "x"}}); err == nil {
+		t.Error("nil request accepted")
+	}
+}
+
+func TestLocalBatchClientCancel(t *testing.T) {
+	started := make(chan struct{}, 1)
+	llm := &fakeClient{}
+	llm.complete = func(ctx context.Context, request *Request) (*Response, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	client := NewLocalBatchClient(llm, 1)
+
+	submitted, err := client.Submit(context.Background(), batchRequests("a", "b", "c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if _, err := client.Results(context.Background(), submitted.ID); err == nil {
+		t.Error("Results succeeded before the job finished")
+	}
+	if err := client.Cancel(context.Background(), submitted.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	job := waitDone(t, client, submitted.ID)
+	if job.Status != BatchStatusCancelled || job.Failed != 3 || job.Succeeded != 0 {
+		t.Errorf("job = %+v", job)
+	}
+	// 取消后没有为剩余的请求启动调用
+	if calls, _ := llm.stats(); calls != 1 {
+		t.Errorf("Complete called %d times, want 1", calls)
+	}
+
+	results := readResults(t, client, submitted.ID)
+	for i, result := range results[1:] {
+		if result.Error == nil || !strings.Contains(result.Error.Error(), "批处理已取消") {
+			t.Errorf("result %d error = %v, want cancelled", i+1, result.Error)
+		}
+	}
+
+	// 已结束的任务再次取消不报错
+	if err := client.Cancel(context.Background(), submitted.ID); err != nil {
+		t.Errorf("cancel finished job: %v", err)
+	}
+}
+
+func TestLocalBatchClientEviction(t *testing.T) {
+	var mu sync.Mutex
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	client := NewLocalBatchClient(&fakeClient{}, 1).(*localBatchClient)
+	client.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		now = now.Add(d)
+		mu.Unlock()
+	}
+
+	submitted, err := client.Submit(context.Background(), batchRequests("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitDone(t, client, submitted.ID)
+
+	tests := []struct {
+		name      string
+		advance   time.Duration
+		wantFound bool
+	}{
+		{name: "within retention", advance: localBatchRetention, wantFound: true},
+		{name: "after retention", advance: time.Second, wantFound: false},
+	}
+	for _, tt := range tests {
+		advance(tt.advance)
+		_, err := client.Status(context.Background(), submitted.ID)
+		if found := err == nil; found != tt.wantFound {
+			t.Errorf("%s: found = %v, want %v (err %v)", tt.name, found, tt.wantFound, err)
+		}
+	}
+	if _, err := client.Results(context.Background(), submitted.ID); err == nil {
+		t.Error("results of an evicted job are still readable")
+	}
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"unicode/utf8"
+)
+
+// Scorer 为候选打分，分数越高越好。可以基于规则、对数概率，或再调用一次模型评判
+type Scorer func(ctx context.Context, choice *Choice) (float64, error)
+
+// LengthScorer 按内容的字符数打分，内容越长分数越高
+func LengthScorer(ctx context.Context, choice *Choice) (float64, error) {
+	return float64(utf8.RuneCountInString(choice.Message.Content)), nil
+}
+
+// SelectBest 用scorer为response中的每个候选打分，返回分数最高的候选，分数相同时取靠前的候选
+//
+// scorer返回错误时立即返回该错误。
+func SelectBest(ctx context.Context, response *Response, scorer Scorer) (*Choice, error) {
+	if scorer == nil {
+		return nil, NewError(ErrorTypeInvalidRequest, "scorer不能为空", 0, nil)
+	}
+	if len(response.Choices) == 0 {
+		return nil, NewError(ErrorTypeServer, "响应中没有候选", 0, nil)
+	}
+
+	var best *Choice
+	var bestScore float64
+	for i := range response.Choices {
+		choice := &response.Choices[i]
+		score, err := scorer(ctx, choice)
+		if err != nil {
+			return nil, err
+		}
+		if best == nil || score > bestScore {
+			best, bestScore = choice, score
+		}
+	}
+	return best, nil
+}
+
+// BestOf 通过Request.N请求n个候选，返回得分最高的候选和完整的响应
+func BestOf(ctx context.Context, client LLMClient, request *Request, n int, scorer Scorer) (*Choice, *Response, error) {
+	if n < 1 {
+		return nil, nil, NewError(ErrorTypeInvalidRequest, "候选数量必须大于0", 0, nil)
+	}
+
+	reqCopy := *request
+	reqCopy.N = n
+	reqCopy.Stream = false
+	response, err := client.Complete(ctx, &reqCopy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	best, err := SelectBest(ctx, response, scorer)
+	if err != nil {
+		return nil, response, err
+	}
+	return best, response, nil
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"time"
 )
 
 // LLMClient 定义了与语言模型交互的统一接口
@@ -41,4 +42,38 @@ type ClientOptions struct {
 	HTTPClient interface{} // 使用时可以转换为具体的HTTP客户端类型
 	Timeout    int
 	MaxRetries int
+
+	// StreamTimeouts 流式请求的分阶段超时。流式请求不受Timeout的总时长限制，以免输出较长时被中断
+	StreamTimeouts StreamTimeouts
+
+	// StrictParams 为true时，请求包含提供商不支持的参数会返回ErrorTypeInvalidRequest错误；
+	// 默认忽略这些参数，并在Response.Warnings（流式响应为第一个块的Warnings）中说明
+	StrictParams bool
+
+	// Extra 提供商特定的配置，键名由各提供商包定义
+	Extra map[string]interface{}
+}
+
+// DefaultStreamIdleTimeout 流式响应默认的空闲超时
+const DefaultStreamIdleTimeout = 5 * time.Minute
+
+// StreamTimeouts 定义流式请求的分阶段超时，为0时使用默认值，为负数时不限制
+type StreamTimeouts struct {
+	// Connect 从发送请求到收到响应头的最长时间，默认与ClientOptions.Timeout相同
+	Connect time.Duration
+	// FirstToken 从收到响应头到收到第一个token（内容、思考或工具调用）的最长时间，心跳不算，默认不限制（此阶段由Idle约束）
+	FirstToken time.Duration
+	// Idle 相邻两次收到数据（包括心跳）之间的最长间隔，默认为DefaultStreamIdleTimeout
+	Idle time.Duration
+}
+
+// WithDefaults 返回填充了默认值的配置，timeout为客户端的总超时
+func (t StreamTimeouts) WithDefaults(timeout time.Duration) StreamTimeouts {
+	if t.Connect == 0 {
+		t.Connect = timeout
+	}
+	if t.Idle == 0 {
+		t.Idle = DefaultStreamIdleTimeout
+	}
+	return t
 }
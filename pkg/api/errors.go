@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"time"
 )
 
 // ErrorType 定义错误类型
@@ -20,6 +21,8 @@ const (
 	ErrorTypeTimeout ErrorType = "timeout_error"
 	// ErrorTypeConnection 连接错误
 	ErrorTypeConnection ErrorType = "connection_error"
+	// ErrorTypeContentFiltered 内容被安全策略拦截
+	ErrorTypeContentFiltered ErrorType = "content_filter_error"
 	// ErrorTypeUnknown 未知错误
 	ErrorTypeUnknown ErrorType = "unknown_error"
 )
@@ -56,3 +59,37 @@ func NewError(errType ErrorType, message string, statusCode int, rawErr error) *
 		RawError:   rawErr,
 	}
 }
+
+// TimeoutPhase 定义流式请求超时发生的阶段
+type TimeoutPhase string
+
+const (
+	// TimeoutPhaseConnect 建立连接并等待响应头
+	TimeoutPhaseConnect TimeoutPhase = "connect"
+	// TimeoutPhaseFirstToken 等待第一个token
+	TimeoutPhaseFirstToken TimeoutPhase = "first_token"
+	// TimeoutPhaseIdle 等待后续数据
+	TimeoutPhaseIdle TimeoutPhase = "idle"
+)
+
+// NewTimeoutError 创建流式请求在phase阶段超时的错误，阶段记录在Code中
+func NewTimeoutError(phase TimeoutPhase, limit time.Duration, rawErr error) *Error {
+	return &Error{
+		Type:     ErrorTypeTimeout,
+		Message:  fmt.Sprintf("流式请求在%s阶段超时(%s)", phase, limit),
+		Code:     string(phase),
+		RawError: rawErr,
+	}
+}
+
+// TimeoutPhase 返回流式请求超时发生的阶段，不是流式超时错误时返回空
+func (e *Error) TimeoutPhase() TimeoutPhase {
+	if e.Type != ErrorTypeTimeout {
+		return ""
+	}
+	switch phase := TimeoutPhase(e.Code); phase {
+	case TimeoutPhaseConnect, TimeoutPhaseFirstToken, TimeoutPhaseIdle:
+		return phase
+	}
+	return ""
+}
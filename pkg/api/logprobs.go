@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+)
+
+// TokenLogprob 定义输出中单个token的对数概率
+type TokenLogprob struct {
+	Token string `json:"token"`
+	// Logprob 该token的自然对数概率
+	Logprob float64 `json:"logprob"`
+	// Bytes token的UTF-8字节，部分提供商不返回
+	Bytes []int `json:"bytes,omitempty"`
+	// TopLogprobs 该位置最可能的若干个token，仅在请求设置TopLogprobs时返回
+	TopLogprobs []TopLogprob `json:"top_logprobs,omitempty"`
+}
+
+// TopLogprob 定义某个位置上的一个候选token
+type TopLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
+	Bytes   []int   `json:"bytes,omitempty"`
+}
+
+// Probability 返回该token的概率
+func (t TokenLogprob) Probability() float64 {
+	return math.Exp(t.Logprob)
+}
+
+// SequenceLogprob 返回token序列的联合对数概率，即各token对数概率之和
+func SequenceLogprob(tokens []TokenLogprob) float64 {
+	var sum float64
+	for _, token := range tokens {
+		sum += token.Logprob
+	}
+	return sum
+}
+
+// SequenceProbability 返回token序列的联合概率
+func SequenceProbability(tokens []TokenLogprob) float64 {
+	return math.Exp(SequenceLogprob(tokens))
+}
+
+// MeanLogprobScorer 按输出token的平均对数概率打分，可用于BestOf选择模型最有把握的候选
+//
+// 候选没有对数概率时返回错误，请求需要开启Logprobs。
+func MeanLogprobScorer(ctx context.Context, choice *Choice) (float64, error) {
+	if len(choice.Logprobs) == 0 {
+		return 0, NewError(ErrorTypeInvalidRequest, "候选中没有对数概率，请在请求中开启Logprobs", 0, nil)
+	}
+	return SequenceLogprob(choice.Logprobs) / float64(len(choice.Logprobs)), nil
+}
+
+// LabelProbability 定义某个标签的概率
+type LabelProbability struct {
+	Label string `json:"label"`
+	// Probability 标签在第一个输出位置上的概率（在模型整个词表上），未出现在候选token中时为0
+	Probability float64 `json:"probability"`
+	// Normalized 只在给定标签之间归一化后的概率，所有标签之和为1
+	Normalized float64 `json:"normalized"`
+}
+
+// ChooseLabel 根据第一个输出位置的对数概率在给定标签中做选择，用于分类等受限输出的场景
+//
+// 标签与token比较时忽略首尾空白和大小写，同一标签匹配多个token时概率相加。
+// 请求应开启Logprobs并设置足够大的TopLogprobs，且提示模型只输出一个标签；
+// 标签通常应为单个token。返回的结果按概率从高到低排列，第一个即为选择的标签。
+// 所有标签都没有出现在候选token中时返回错误。
+func ChooseLabel(logprobs []TokenLogprob, labels []string) ([]LabelProbability, error) {
+	if len(labels) == 0 {
+		return nil, NewError(ErrorTypeInvalidRequest, "标签不能为空", 0, nil)
+	}
+	if len(logprobs) == 0 {
+		return nil, NewError(ErrorTypeInvalidRequest, "没有对数概率，请在请求中开启Logprobs", 0, nil)
+	}
+
+	// 第一个位置实际输出的token和其他候选token，实际输出的token可能也出现在候选中
+	first := logprobs[0]
+	candidates := map[string]float64{first.Token: first.Logprob}
+	for _, top := range first.TopLogprobs {
+		candidates[top.Token] = top.Logprob
+	}
+
+	results := make([]LabelProbability, len(labels))
+	var total float64
+	for i, label := range labels {
+		key := normalizeLabel(label)
+		var probability float64
+		for token, logprob := range candidates {
+			if normalizeLabel(token) == key {
+				probability += math.Exp(logprob)
+			}
+		}
+		results[i] = LabelProbability{Label: label, Probability: probability}
+		total += probability
+	}
+	if total == 0 {
+		return nil, NewError(ErrorTypeServer, "候选token中没有匹配的标签", 0, nil)
+	}
+
+	for i := range results {
+		results[i].Normalized = results[i].Probability / total
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Probability > results[j].Probability
+	})
+	return results, nil
+}
+
+// normalizeLabel 返回用于比较标签和token的规范形式
+func normalizeLabel(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"context"
+)
+
+// Model 定义提供商模型列表接口返回的模型
+type Model struct {
+	ID string `json:"id"`
+	// DisplayName 便于阅读的名称，部分提供商不返回
+	DisplayName string `json:"display_name,omitempty"`
+	// Created 模型的创建或发布时间（Unix秒），部分提供商不返回
+	Created int64  `json:"created,omitempty"`
+	OwnedBy string `json:"owned_by,omitempty"`
+
+	// InputTokenLimit 上下文窗口的token数，部分提供商不返回
+	InputTokenLimit int `json:"input_token_limit,omitempty"`
+	// OutputTokenLimit 单次输出的最大token数，部分提供商不返回
+	OutputTokenLimit int `json:"output_token_limit,omitempty"`
+
+	// Capabilities 模型的能力，取值与models包的能力常量相同，仅在提供商返回相关信息时填写
+	Capabilities []string `json:"capabilities,omitempty"`
+}
+
+// ModelLister 由能够查询可用模型的客户端实现，可以通过类型断言使用
+//
+//	if lister, ok := client.(api.ModelLister); ok {
+//		list, err := lister.ListModels(ctx)
+//	}
+type ModelLister interface {
+	// ListModels 返回当前API密钥可以使用的所有模型，分页的接口会读取所有页
+	ListModels(ctx context.Context) ([]Model, error)
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Param 标识Request中的一个可选参数，取值为参数在OpenAI格式中的名称
+type Param string
+
+// Request中的可选参数
+const (
+	ParamTemperature       Param = "temperature"
+	ParamTopP              Param = "top_p"
+	ParamTopK              Param = "top_k"
+	ParamMaxTokens         Param = "max_tokens"
+	ParamPresencePenalty   Param = "presence_penalty"
+	ParamFrequencyPenalty  Param = "frequency_penalty"
+	ParamStop              Param = "stop"
+	ParamN                 Param = "n"
+	ParamSeed              Param = "seed"
+	ParamLogitBias         Param = "logit_bias"
+	ParamLogprobs          Param = "logprobs"
+	ParamTopLogprobs       Param = "top_logprobs"
+	ParamUser              Param = "user"
+	ParamTools             Param = "tools"
+	ParamToolChoice        Param = "tool_choice"
+	ParamParallelToolCalls Param = "parallel_tool_calls"
+	ParamResponseFormat    Param = "response_format"
+	ParamReasoningEffort   Param = "reasoning_effort"
+	ParamThinking          Param = "thinking"
+	ParamSafetySettings    Param = "safety_settings"
+	ParamMetadata          Param = "metadata"
+)
+
+// ErrorCodeUnsupportedParam 严格模式下请求包含提供商不支持的参数时的错误码
+const ErrorCodeUnsupportedParam = "unsupported_parameter"
+
+// RequestParams 返回request中设置了的可选参数，按上面声明的顺序排列
+//
+// 字段为零值（指针为nil、切片或映射为空、N不大于1）时视为未设置。ExtraParams不在检查范围内。
+func RequestParams(request *Request) []Param {
+	var params []Param
+	add := func(set bool, param Param) {
+		if set {
+			params = append(params, param)
+		}
+	}
+
+	add(request.Temperature != nil, ParamTemperature)
+	add(request.TopP != nil, ParamTopP)
+	add(request.TopK != nil, ParamTopK)
+	add(request.MaxTokens != nil, ParamMaxTokens)
+	add(request.PresencePenalty != nil, ParamPresencePenalty)
+	add(request.FrequencyPenalty != nil, ParamFrequencyPenalty)
+	add(len(request.Stop) > 0, ParamStop)
+	add(request.N > 1, ParamN)
+	add(request.Seed != nil, ParamSeed)
+	add(len(request.LogitBias) > 0, ParamLogitBias)
+	add(request.Logprobs, ParamLogprobs)
+	add(request.TopLogprobs > 0, ParamTopLogprobs)
+	add(request.User != "", ParamUser)
+	add(len(request.Tools) > 0, ParamTools)
+	add(request.ToolChoice != nil, ParamToolChoice)
+	add(request.ParallelToolCalls != nil, ParamParallelToolCalls)
+	add(request.ResponseFormat != nil, ParamResponseFormat)
+	add(request.ReasoningEffort != "", ParamReasoningEffort)
+	add(request.Thinking != nil, ParamThinking)
+	add(len(request.SafetySettings) > 0, ParamSafetySettings)
+	add(len(request.Metadata) > 0, ParamMetadata)
+	return params
+}
+
+// ParamSet 定义提供商支持的可选参数集合
+type ParamSet map[Param]bool
+
+// NewParamSet 创建包含params的参数集合
+func NewParamSet(params ...Param) ParamSet {
+	set := make(ParamSet, len(params))
+	for _, param := range params {
+		set[param] = true
+	}
+	return set
+}
+
+// Unsupported 返回request中设置了但不在集合中的参数
+func (s ParamSet) Unsupported(request *Request) []Param {
+	var unsupported []Param
+	for _, param := range RequestParams(request) {
+		if !s[param] {
+			unsupported = append(unsupported, param)
+		}
+	}
+	return unsupported
+}
+
+// CheckParams 检查request中provider不支持的参数
+//
+// strict为true时，存在不支持的参数则返回ErrorTypeInvalidRequest错误，Param为第一个不支持的参数，
+// Code为ErrorCodeUnsupportedParam；否则为每个不支持的参数返回一条警告，这些参数会被忽略。
+func CheckParams(provider string, request *Request, supported ParamSet, strict bool) ([]string, error) {
+	unsupported := supported.Unsupported(request)
+	if len(unsupported) == 0 {
+		return nil, nil
+	}
+
+	if strict {
+		names := make([]string, len(unsupported))
+		for i, param := range unsupported {
+			names[i] = string(param)
+		}
+		return nil, &Error{
+			Type:    ErrorTypeInvalidRequest,
+			Message: fmt.Sprintf("%s不支持参数: %s", provider, strings.Join(names, ", ")),
+			Param:   string(unsupported[0]),
+			Code:    ErrorCodeUnsupportedParam,
+		}
+	}
+
+	warnings := make([]string, len(unsupported))
+	for i, param := range unsupported {
+		warnings[i] = fmt.Sprintf("%s不支持参数%s，已忽略", provider, param)
+	}
+	return warnings, nil
+}
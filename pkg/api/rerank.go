@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+)
+
+// Reranker 定义了文档重排序的统一接口
+//
+// Cohere、Jina、Voyage或通过HTTP暴露的本地交叉编码器等都可以实现该接口。
+type Reranker interface {
+	// Rerank 根据与查询的相关性对文档重新排序
+	Rerank(ctx context.Context, request *RerankRequest) (*RerankResponse, error)
+}
+
+// RerankRequest 定义重排序请求参数
+type RerankRequest struct {
+	// 必填字段
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+
+	// TopN 只返回相关性最高的前N个结果，为空时返回全部
+	TopN *int `json:"top_n,omitempty"`
+
+	// ReturnDocuments 是否在结果中附带文档原文
+	ReturnDocuments bool `json:"return_documents,omitempty"`
+
+	// 自定义字段，用于提供商特定的参数
+	ExtraParams map[string]interface{} `json:"-"`
+}
+
+// RerankResponse 定义重排序响应
+type RerankResponse struct {
+	ID      string         `json:"id"`
+	Model   string         `json:"model"`
+	Results []RerankResult `json:"results"`
+}
+
+// RerankResult 定义单个文档的重排序结果，按相关性从高到低排列
+type RerankResult struct {
+	// Index 文档在请求Documents中的下标
+	Index          int     `json:"index"`
+	RelevanceScore float64 `json:"relevance_score"`
+	Document       string  `json:"document,omitempty"`
+}
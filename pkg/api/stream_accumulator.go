@@ -0,0 +1,237 @@
+package api
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+// StreamAccumulator 将流式响应块累积为与Complete返回结构相同的完整Response
+//
+// 所有候选、结束原因、工具调用、思考内容、对数概率、用量和警告都会被保留。可以在接收过程中逐块调用Add，
+// 并随时通过Response查看当前的结果；也可以用AccumulateStream一次性读取整个流。
+// StreamAccumulator不能被多个goroutine同时使用。
+type StreamAccumulator struct {
+	id      string
+	object  string
+	created int64
+	model   string
+	usage   Usage
+	// warnings 各响应块中的警告，去除重复
+	warnings []string
+
+	// choices 按候选序号累积的内容
+	choices map[int]*choiceAccumulator
+}
+
+// choiceAccumulator 累积单个候选的内容
+type choiceAccumulator struct {
+	role             Role
+	content          strings.Builder
+	reasoningContent strings.Builder
+	thinkingBlocks   []ThinkingBlock
+	toolCalls        []ToolCall
+	// toolIndexes 流式增量中的工具调用序号到toolCalls下标的映射
+	toolIndexes map[int]int
+
+	finishReason    FinishReason
+	rawFinishReason string
+	safetyRatings   []SafetyRating
+	logprobs        []TokenLogprob
+}
+
+// NewStreamAccumulator 创建一个新的流式响应累积器
+func NewStreamAccumulator() *StreamAccumulator {
+	return &StreamAccumulator{
+		choices: map[int]*choiceAccumulator{},
+	}
+}
+
+// Add 累积一个响应块
+func (a *StreamAccumulator) Add(chunk *ResponseChunk) {
+	if chunk == nil {
+		return
+	}
+	if a.id == "" {
+		a.id = chunk.ID
+	}
+	if a.object == "" {
+		a.object = strings.TrimSuffix(chunk.Object, ".chunk")
+	}
+	if a.created == 0 {
+		a.created = chunk.Created
+	}
+	if a.model == "" {
+		a.model = chunk.Model
+	}
+	// 用量是累计值，以最后一次出现的为准
+	if chunk.Usage != nil {
+		a.usage = *chunk.Usage
+	}
+	for _, warning := range chunk.Warnings {
+		a.addWarning(warning)
+	}
+
+	for i := range chunk.Choices {
+		a.addChoice(&chunk.Choices[i])
+	}
+}
+
+// addWarning 添加一条之前没有出现过的警告
+func (a *StreamAccumulator) addWarning(warning string) {
+	for _, existing := range a.warnings {
+		if existing == warning {
+			return
+		}
+	}
+	a.warnings = append(a.warnings, warning)
+}
+
+// addChoice 累积一个候选的增量
+func (a *StreamAccumulator) addChoice(choice *ChunkChoice) {
+	acc, ok := a.choices[choice.Index]
+	if !ok {
+		acc = &choiceAccumulator{toolIndexes: map[int]int{}}
+		a.choices[choice.Index] = acc
+	}
+
+	delta := &choice.Delta
+	if acc.role == "" {
+		acc.role = delta.Role
+	}
+	acc.content.WriteString(delta.Content)
+	acc.reasoningContent.WriteString(delta.ReasoningContent)
+	acc.thinkingBlocks = append(acc.thinkingBlocks, delta.ThinkingBlocks...)
+	for _, call := range delta.ToolCalls {
+		acc.addToolCall(call)
+	}
+	acc.logprobs = append(acc.logprobs, choice.Logprobs...)
+
+	if choice.FinishReason != "" {
+		acc.finishReason = choice.FinishReason
+	}
+	if choice.RawFinishReason != "" {
+		acc.rawFinishReason = choice.RawFinishReason
+	}
+	if len(choice.SafetyRatings) > 0 {
+		acc.safetyRatings = choice.SafetyRatings
+	}
+}
+
+// addToolCall 累积工具调用的增量：带Index的片段拼接到同一调用上，不带Index的视为完整的调用
+func (acc *choiceAccumulator) addToolCall(call ToolCall) {
+	if call.Index == nil {
+		acc.toolCalls = append(acc.toolCalls, call)
+		return
+	}
+
+	pos, ok := acc.toolIndexes[*call.Index]
+	if !ok {
+		acc.toolIndexes[*call.Index] = len(acc.toolCalls)
+		call.Index = nil
+		acc.toolCalls = append(acc.toolCalls, call)
+		return
+	}
+
+	existing := &acc.toolCalls[pos]
+	if call.ID != "" {
+		existing.ID = call.ID
+	}
+	if call.Type != "" {
+		existing.Type = call.Type
+	}
+	if existing.Function.Name == "" {
+		existing.Function.Name = call.Function.Name
+	}
+	existing.Function.Arguments += call.Function.Arguments
+}
+
+// Response 返回目前为止累积的完整响应，候选按序号排列
+//
+// 返回的Response是一份快照，之后继续调用Add不会修改它。
+func (a *StreamAccumulator) Response() *Response {
+	object := a.object
+	if object == "" {
+		object = "chat.completion"
+	}
+
+	indexes := make([]int, 0, len(a.choices))
+	for index := range a.choices {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	choices := make([]Choice, 0, len(indexes))
+	for _, index := range indexes {
+		acc := a.choices[index]
+		role := acc.role
+		if role == "" {
+			role = RoleAssistant
+		}
+
+		message := Message{
+			Role:             role,
+			Content:          acc.content.String(),
+			ReasoningContent: acc.reasoningContent.String(),
+		}
+		if len(acc.thinkingBlocks) > 0 {
+			message.ThinkingBlocks = append([]ThinkingBlock(nil), acc.thinkingBlocks...)
+		}
+		if len(acc.toolCalls) > 0 {
+			message.ToolCalls = make([]ToolCall, len(acc.toolCalls))
+			for i, call := range acc.toolCalls {
+				if call.Type == "" {
+					call.Type = ToolTypeFunction
+				}
+				message.ToolCalls[i] = call
+			}
+		}
+		var logprobs []TokenLogprob
+		if len(acc.logprobs) > 0 {
+			logprobs = append(logprobs, acc.logprobs...)
+		}
+
+		choices = append(choices, Choice{
+			Index:           index,
+			Message:         message,
+			FinishReason:    acc.finishReason,
+			RawFinishReason: acc.rawFinishReason,
+			SafetyRatings:   acc.safetyRatings,
+			Logprobs:        logprobs,
+		})
+	}
+
+	var warnings []string
+	if len(a.warnings) > 0 {
+		warnings = append(warnings, a.warnings...)
+	}
+
+	return &Response{
+		ID:       a.id,
+		Object:   object,
+		Created:  a.created,
+		Model:    a.model,
+		Choices:  choices,
+		Usage:    a.usage,
+		Warnings: warnings,
+	}
+}
+
+// AccumulateStream 读取整个流并返回累积的完整响应，读取结束后流会被关闭
+//
+// 流中途出错时返回已经累积的部分响应和错误。
+func AccumulateStream(stream ResponseStream) (*Response, error) {
+	defer stream.Close()
+
+	acc := NewStreamAccumulator()
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return acc.Response(), nil
+		}
+		if err != nil {
+			return acc.Response(), err
+		}
+		acc.Add(chunk)
+	}
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestStreamAccumulator(t *testing.T) {
+	index := func(i int) *int { return &i }
+
+	tests := []struct {
+		name   string
+		chunks []*ResponseChunk
+		check  func(t *testing.T, response *Response)
+	}{
+		{
+			name: "content and metadata",
+			chunks: []*ResponseChunk{
+				{ID: "resp-1", Object: "chat.completion.chunk", Created: 10, Model: "m", Choices: []ChunkChoice{{Delta: Message{Role: RoleAssistant, Content: "你"}}}},
+				{ID: "ignored", Model: "other", Choices: []ChunkChoice{{Delta: Message{Content: "好"}, FinishReason: FinishReasonStop, RawFinishReason: "end_turn"}}},
+			},
+			check: func(t *testing.T, response *Response) {
+				if response.ID != "resp-1" || response.Object != "chat.completion" || response.Created != 10 || response.Model != "m" {
+					t.Errorf("metadata = %q %q %d %q", response.ID, response.Object, response.Created, response.Model)
+				}
+				choice := response.Choices[0]
+				if choice.Message.Content != "你好" || choice.Message.Role != RoleAssistant {
+					t.Errorf("message = %+v", choice.Message)
+				}
+				if choice.FinishReason != FinishReasonStop || choice.RawFinishReason != "end_turn" {
+					t.Errorf("finish reason = %q %q", choice.FinishReason, choice.RawFinishReason)
+				}
+			},
+		},
+		{
+			name: "tool calls merged by index",
+			chunks: []*ResponseChunk{
+				{Choices: []ChunkChoice{{Delta: Message{ToolCalls: []ToolCall{
+					{Index: index(0), ID: "call_a", Type: ToolTypeFunction, Function: FunctionCall{Name: "weather", Arguments: `{"ci`}},
+				}}}}},
+				{Choices: []ChunkChoice{{Delta: Message{ToolCalls: []ToolCall{
+					{Index: index(1), ID: "call_b", Function: FunctionCall{Name: "time", Arguments: `{}`}},
+					{Index: index(0), Function: FunctionCall{Arguments: `ty":"北京"}`}},
+				}}}}},
+				{Choices: []ChunkChoice{{Delta: Message{ToolCalls: []ToolCall{
+					{ID: "call_c", Type: ToolTypeFunction, Function: FunctionCall{Name: "whole", Arguments: `{"x":1}`}},
+				}}}}},
+			},
+			check: func(t *testing.T, response *Response) {
+				want := []ToolCall{
+					{ID: "call_a", Type: ToolTypeFunction, Function: FunctionCall{Name: "weather", Arguments: `{"city":"北京"}`}},
+					{ID: "call_b", Type: ToolTypeFunction, Function: FunctionCall{Name: "time", Arguments: `{}`}},
+					{ID: "call_c", Type: ToolTypeFunction, Function: FunctionCall{Name: "whole", Arguments: `{"x":1}`}},
+				}
+				if got := response.Choices[0].Message.ToolCalls; !reflect.DeepEqual(got, want) {
+					t.Errorf("tool calls = %+v, want %+v", got, want)
+				}
+			},
+		},
+		{
+			name: "candidates ordered by index",
+			chunks: []*ResponseChunk{
+				{Choices: []ChunkChoice{{Index: 2, Delta: Message{Content: "c"}}, {Index: 0, Delta: Message{Content: "a"}}}},
+				{Choices: []ChunkChoice{{Index: 1, Delta: Message{Content: "b"}}, {Index: 0, Delta: Message{Content: "a"}}}},
+			},
+			check: func(t *testing.T, response *Response) {
+				var got []string
+				for _, choice := range response.Choices {
+					got = append(got, string(rune('0'+choice.Index))+choice.Message.Content)
+				}
+				if want := []string{"0aa", "1b", "2c"}; !reflect.DeepEqual(got, want) {
+					t.Errorf("choices = %q, want %q", got, want)
+				}
+			},
+		},
+		{
+			name: "warnings de-duplicated",
+			chunks: []*ResponseChunk{
+				{Warnings: []string{"w1", "w2"}},
+				{Warnings: []string{"w2", "w3"}},
+				{Warnings: []string{"w1"}},
+			},
+			check: func(t *testing.T, response *Response) {
+				if want := []string{"w1", "w2", "w3"}; !reflect.DeepEqual(response.Warnings, want) {
+					t.Errorf("warnings = %q, want %q", response.Warnings, want)
+				}
+			},
+		},
+		{
+			name: "last usage wins",
+			chunks: []*ResponseChunk{
+				{Usage: &Usage{PromptTokens: 5, TotalTokens: 5}},
+				{Choices: []ChunkChoice{{Delta: Message{Content: "x"}}}},
+				{Usage: &Usage{PromptTokens: 5, CompletionTokens: 3, TotalTokens: 8}},
+			},
+			check: func(t *testing.T, response *Response) {
+				if want := (Usage{PromptTokens: 5, CompletionTokens: 3, TotalTokens: 8}); response.Usage != want {
+					t.Errorf("usage = %+v, want %+v", response.Usage, want)
+				}
+			},
+		},
+		{
+			name: "reasoning and thinking blocks",
+			chunks: []*ResponseChunk{
+				{Choices: []ChunkChoice{{Delta: Message{ReasoningContent: "想"}}}},
+				{Choices: []ChunkChoice{{Delta: Message{ReasoningContent: "一想"}}}},
+				{Choices: []ChunkChoice{{Delta: Message{ThinkingBlocks: []ThinkingBlock{{Type: ThinkingTypeThinking, Thinking: "想一想", Signature: "sig"}}}}}},
+			},
+			check: func(t *testing.T, response *Response) {
+				message := response.Choices[0].Message
+				if message.ReasoningContent != "想一想" || len(message.ThinkingBlocks) != 1 || message.ThinkingBlocks[0].Signature != "sig" {
+					t.Errorf("message = %+v", message)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := NewStreamAccumulator()
+			for _, chunk := range tt.chunks {
+				acc.Add(chunk)
+			}
+			tt.check(t, acc.Response())
+		})
+	}
+}
+
+func TestStreamAccumulatorResponseIsSnapshot(t *testing.T) {
+	acc := NewStreamAccumulator()
+	acc.Add(&ResponseChunk{Warnings: []string{"w1"}, Choices: []ChunkChoice{{Delta: Message{Content: "a"}}}})
+	first := acc.Response()
+	acc.Add(&ResponseChunk{Warnings: []string{"w2"}, Choices: []ChunkChoice{{Delta: Message{Content: "b"}}}})
+
+	if first.Choices[0].Message.Content != "a" || len(first.Warnings) != 1 {
+		t.Errorf("snapshot changed: %+v", first)
+	}
+	if second := acc.Response(); second.Choices[0].Message.Content != "ab" || len(second.Warnings) != 2 {
+		t.Errorf("second response = %+v", second)
+	}
+}
+
+func TestAccumulateStream(t *testing.T) {
+	upstreamErr := errors.New("upstream failed")
+
+	source := newFakeStream(textChunks("a", "b")...)
+	response, err := AccumulateStream(source)
+	if err != nil || response.Choices[0].Message.Content != "ab" {
+		t.Errorf("got %+v, %v", response, err)
+	}
+	if got := source.closeCount(); got != 1 {
+		t.Errorf("source closed %d times, want 1", got)
+	}
+
+	// 中途出错时返回部分响应
+	source = newFakeStream(textChunks("a")...)
+	source.err = upstreamErr
+	response, err = AccumulateStream(source)
+	if err != upstreamErr || response.Choices[0].Message.Content != "a" {
+		t.Errorf("got %+v, %v", response, err)
+	}
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrStreamClosed 表示在已关闭的流上调用了Recv
+var ErrStreamClosed = errors.New("llm-sdk: stream closed")
+
+// StreamResult 是通过通道传递的流式结果，Chunk和Err中只有一个不为空
+type StreamResult struct {
+	Chunk *ResponseChunk
+	Err   error
+}
+
+// StreamChannel 在新的goroutine中读取流，并通过通道逐个发送响应块，buffer为通道的缓冲大小
+//
+// 流正常结束时通道被关闭；出错时先发送一个Err不为空的结果再关闭通道。
+// ctx被取消时流会被关闭以中断阻塞的读取，通道随即关闭，调用方可通过ctx.Err()判断。
+// 通道关闭时流已被关闭，调用方不需要再关闭流。
+func StreamChannel(ctx context.Context, stream ResponseStream, buffer int) <-chan StreamResult {
+	results := make(chan StreamResult, buffer)
+
+	go func() {
+		defer close(results)
+
+		var closeOnce sync.Once
+		closeStream := func() {
+			closeOnce.Do(func() { stream.Close() })
+		}
+		defer closeStream()
+
+		// ctx被取消时关闭流，使阻塞的Recv立即返回
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-ctx.Done():
+				closeStream()
+			case <-done:
+			}
+		}()
+
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				select {
+				case results <- StreamResult{Err: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			select {
+			case results <- StreamResult{Chunk: chunk}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return results
+}
+
+// TeeStream 将一个流分发给n个消费者，每个消费者都会按顺序收到全部响应块
+//
+// 每个消费者有独立的缓冲区：读得快的消费者不会被读得慢的阻塞，没有被读取的响应块会一直保留在缓冲区中，
+// 直到该消费者读取或关闭。上游的错误（包括io.EOF）会在各自的缓冲区读完后返回给每个消费者。
+// 所有消费者都关闭后上游的流才会被关闭。各消费者收到的是同一个*ResponseChunk，不应修改。
+// n不大于0时没有消费者能关闭上游，因此直接关闭stream并返回nil。
+func TeeStream(stream ResponseStream, n int) []ResponseStream {
+	if n <= 0 {
+		stream.Close()
+		return nil
+	}
+
+	source := &teeSource{
+		source: stream,
+		open:   n,
+	}
+	source.cond = sync.NewCond(&source.mu)
+
+	branches := make([]ResponseStream, n)
+	source.branches = make([]*teeBranch, n)
+	for i := range branches {
+		branch := &teeBranch{source: source}
+		source.branches[i] = branch
+		branches[i] = branch
+	}
+	return branches
+}
+
+// teeSource 是TeeStream中共享的上游
+type teeSource struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	source   ResponseStream
+	branches []*teeBranch
+
+	// reading 是否有消费者正在从上游读取
+	reading bool
+	// err 上游返回的错误，包括io.EOF
+	err  error
+	open int
+}
+
+// teeBranch 是TeeStream返回的单个消费者
+type teeBranch struct {
+	source *teeSource
+	queue  []*ResponseChunk
+	closed bool
+}
+
+// Recv 实现ResponseStream接口，缓冲区为空时从上游读取并分发给所有未关闭的消费者
+func (b *teeBranch) Recv() (*ResponseChunk, error) {
+	s := b.source
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		if b.closed {
+			return nil, ErrStreamClosed
+		}
+		if len(b.queue) > 0 {
+			chunk := b.queue[0]
+			b.queue[0] = nil
+			b.queue = b.queue[1:]
+			return chunk, nil
+		}
+		if s.err != nil {
+			return nil, s.err
+		}
+
+		// 其他消费者正在读取上游，等待其完成
+		if s.reading {
+			s.cond.Wait()
+			continue
+		}
+
+		s.reading = true
+		s.mu.Unlock()
+		chunk, err := s.source.Recv()
+		s.mu.Lock()
+		s.reading = false
+
+		if err != nil {
+			s.err = err
+		} else {
+			for _, branch := range s.branches {
+				if !branch.closed {
+					branch.queue = append(branch.queue, chunk)
+				}
+			}
+		}
+		s.cond.Broadcast()
+	}
+}
+
+// Close 实现ResponseStream接口，丢弃该消费者的缓冲区，最后一个消费者关闭时关闭上游的流
+func (b *teeBranch) Close() error {
+	s := b.source
+	s.mu.Lock()
+	if b.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.queue = nil
+	s.open--
+	last := s.open == 0
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	if last {
+		return s.source.Close()
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeStream 依次返回chunks，之后返回err（为空时返回io.EOF）
+//
+// block不为空时，chunks读完后Recv阻塞直到block被关闭或流被关闭。
+type fakeStream struct {
+	mu     sync.Mutex
+	chunks []*ResponseChunk
+	err    error
+	block  chan struct{}
+	closed chan struct{}
+	closes int
+	recvs  int
+}
+
+func newFakeStream(chunks ...*ResponseChunk) *fakeStream {
+	return &fakeStream{chunks: chunks, closed: make(chan struct{})}
+}
+
+func (s *fakeStream) Recv() (*ResponseChunk, error) {
+	s.mu.Lock()
+	s.recvs++
+	if len(s.chunks) > 0 {
+		chunk := s.chunks[0]
+		s.chunks = s.chunks[1:]
+		s.mu.Unlock()
+		return chunk, nil
+	}
+	block := s.block
+	s.mu.Unlock()
+
+	if block != nil {
+		select {
+		case <-block:
+		case <-s.closed:
+			return nil, ErrStreamClosed
+		}
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return nil, io.EOF
+}
+
+func (s *fakeStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closes++
+	if s.closes == 1 {
+		close(s.closed)
+	}
+	return nil
+}
+
+func (s *fakeStream) closeCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closes
+}
+
+// textChunks 返回每个文本对应一个响应块的切片
+func textChunks(texts ...string) []*ResponseChunk {
+	chunks := make([]*ResponseChunk, len(texts))
+	for i, text := range texts {
+		chunks[i] = &ResponseChunk{Choices: []ChunkChoice{{Delta: Message{Content: text}}}}
+	}
+	return chunks
+}
+
+// readTexts 读取流直到出错，返回各响应块的文本和最后的错误
+func readTexts(stream ResponseStream) ([]string, error) {
+	var texts []string
+	for {
+		chunk, err := stream.Recv()
+		if err != nil {
+			return texts, err
+		}
+		texts = append(texts, chunk.Choices[0].Delta.Content)
+	}
+}
+
+func TestTeeStream(t *testing.T) {
+	upstreamErr := errors.New("upstream failed")
+	tests := []struct {
+		name    string
+		n       int
+		texts   []string
+		err     error
+		wantErr error
+	}{
+		{name: "single branch", n: 1, texts: []string{"a", "b"}, wantErr: io.EOF},
+		{name: "three branches", n: 3, texts: []string{"a", "b", "c", "d"}, wantErr: io.EOF},
+		{name: "upstream error reaches every branch", n: 2, texts: []string{"a"}, err: upstreamErr, wantErr: upstreamErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := newFakeStream(textChunks(tt.texts...)...)
+			source.err = tt.err
+			branches := TeeStream(source, tt.n)
+			if len(branches) != tt.n {
+				t.Fatalf("got %d branches, want %d", len(branches), tt.n)
+			}
+
+			// 各消费者并发读取，都应收到完整且有序的内容
+			var wg sync.WaitGroup
+			results := make([][]string, tt.n)
+			errs := make([]error, tt.n)
+			for i, branch := range branches {
+				wg.Add(1)
+				go func(i int, branch ResponseStream) {
+					defer wg.Done()
+					results[i], errs[i] = readTexts(branch)
+				}(i, branch)
+			}
+			wg.Wait()
+
+			for i := range branches {
+				if !errors.Is(errs[i], tt.wantErr) {
+					t.Errorf("branch %d: err = %v, want %v", i, errs[i], tt.wantErr)
+				}
+				if len(results[i]) != len(tt.texts) {
+					t.Fatalf("branch %d: got %q, want %q", i, results[i], tt.texts)
+				}
+				for j := range tt.texts {
+					if results[i][j] != tt.texts[j] {
+						t.Errorf("branch %d: got %q, want %q", i, results[i], tt.texts)
+						break
+					}
+				}
+			}
+
+			// 上游只在所有消费者关闭后关闭一次
+			for i, branch := range branches {
+				if got := source.closeCount(); got != 0 {
+					t.Fatalf("source closed %d times before branch %d closed", got, i)
+				}
+				branch.Close()
+				branch.Close()
+			}
+			if got := source.closeCount(); got != 1 {
+				t.Errorf("source closed %d times, want 1", got)
+			}
+		})
+	}
+}
+
+func TestTeeStreamSlowBranchKeepsBuffer(t *testing.T) {
+	source := newFakeStream(textChunks("a", "b", "c")...)
+	branches := TeeStream(source, 2)
+
+	fast, err := readTexts(branches[0])
+	if err != io.EOF || len(fast) != 3 {
+		t.Fatalf("fast branch got %q, %v", fast, err)
+	}
+	slow, err := readTexts(branches[1])
+	if err != io.EOF || len(slow) != 3 {
+		t.Fatalf("slow branch got %q, %v", slow, err)
+	}
+	if source.recvs != 4 {
+		t.Errorf("upstream read %d times, want 4", source.recvs)
+	}
+}
+
+func TestTeeStreamClosedBranch(t *testing.T) {
+	source := newFakeStream(textChunks("a", "b")...)
+	branches := TeeStream(source, 2)
+	branches[1].Close()
+
+	if _, err := branches[1].Recv(); err != ErrStreamClosed {
+		t.Errorf("closed branch err = %v, want ErrStreamClosed", err)
+	}
+	texts, err := readTexts(branches[0])
+	if err != io.EOF || len(texts) != 2 {
+		t.Errorf("open branch got %q, %v", texts, err)
+	}
+	branches[0].Close()
+	if got := source.closeCount(); got != 1 {
+		t.Errorf("source closed %d times, want 1", got)
+	}
+}
+
+func TestTeeStreamNoBranches(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		source := newFakeStream()
+		if branches := TeeStream(source, n); branches != nil {
+			t.Errorf("n=%d: got %d branches, want nil", n, len(branches))
+		}
+		if got := source.closeCount(); got != 1 {
+			t.Errorf("n=%d: source closed %d times, want 1", n, got)
+		}
+	}
+}
+
+func TestStreamChannel(t *testing.T) {
+	upstreamErr := errors.New("upstream failed")
+	tests := []struct {
+		name    string
+		texts   []string
+		err     error
+		wantErr error
+	}{
+		{name: "eof", texts: []string{"a", "b"}},
+		{name: "error", texts: []string{"a"}, err: upstreamErr, wantErr: upstreamErr},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := newFakeStream(textChunks(tt.texts...)...)
+			source.err = tt.err
+
+			var texts []string
+			var gotErr error
+			for result := range StreamChannel(context.Background(), source, 0) {
+				if result.Err != nil {
+					gotErr = result.Err
+					continue
+				}
+				texts = append(texts, result.Chunk.Choices[0].Delta.Content)
+			}
+
+			if gotErr != tt.wantErr {
+				t.Errorf("err = %v, want %v", gotErr, tt.wantErr)
+			}
+			if len(texts) != len(tt.texts) {
+				t.Errorf("got %q, want %q", texts, tt.texts)
+			}
+			if got := source.closeCount(); got != 1 {
+				t.Errorf("source closed %d times, want 1", got)
+			}
+		})
+	}
+}
+
+func TestStreamChannelCancel(t *testing.T) {
+	source := newFakeStream(textChunks("a")...)
+	source.block = make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := StreamChannel(ctx, source, 0)
+	if result := <-results; result.Chunk == nil {
+		t.Fatalf("first result = %+v, want a chunk", result)
+	}
+
+	// 取消后阻塞的Recv被关闭流打断，通道关闭且不发送错误
+	cancel()
+	select {
+	case result, ok := <-results:
+		if ok {
+			t.Errorf("got %+v after cancel, want closed channel", result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+	if got := source.closeCount(); got != 1 {
+		t.Errorf("source closed %d times, want 1", got)
+	}
+}
@@ -0,0 +1,60 @@
+//go:build go1.23
+
+package api
+
+import (
+	"io"
+	"iter"
+)
+
+// Chunks 返回遍历流中响应块的迭代器，用于range-over-func（需要Go 1.23）
+//
+//	for chunk, err := range api.Chunks(stream) {
+//		if err != nil {
+//			return err
+//		}
+//		fmt.Print(chunk.Choices[0].Delta.Content)
+//	}
+//
+// 流正常结束时遍历结束；出错时产生一次(nil, err)后结束。遍历结束（包括提前break）时流会被关闭。
+func Chunks(stream ResponseStream) iter.Seq2[*ResponseChunk, error] {
+	return func(yield func(*ResponseChunk, error) bool) {
+		defer stream.Close()
+
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(chunk, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Texts 返回只遍历文本内容的迭代器，产生序号为0的候选的每个非空文本增量
+//
+// 出错时产生一次("", err)后结束。遍历结束时流会被关闭。
+func Texts(stream ResponseStream) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for chunk, err := range Chunks(stream) {
+			if err != nil {
+				yield("", err)
+				return
+			}
+			for _, choice := range chunk.Choices {
+				if choice.Index != 0 || choice.Delta.Content == "" {
+					continue
+				}
+				if !yield(choice.Delta.Content, nil) {
+					return
+				}
+			}
+		}
+	}
+}
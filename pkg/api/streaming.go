@@ -2,6 +2,7 @@ package api
 
 import (
 	"io"
+	"strings"
 )
 
 // StreamHandler 是一个流式响应处理器函数类型
@@ -15,13 +16,25 @@ type StreamOptions struct {
 	// OnComplete 当流处理完成时被调用
 	OnComplete func(err error)
 
-	// OnText 当接收到纯文本内容时被调用（便于直接处理文本内容）
+	// OnText 当接收到纯文本内容时被调用（便于直接处理文本内容），只包含序号为0的候选
 	OnText func(text string) error
 
+	// OnCandidateText 当接收到任一候选的文本内容时被调用，index为候选序号，用于请求了多个候选（Request.N）的场景
+	OnCandidateText func(index int, text string) error
+
+	// OnPing 当收到服务端心跳事件时被调用，流需要实现PingNotifier接口
+	OnPing func()
+
 	// AutoClose 是否在接收完所有事件后自动关闭流，默认为true
 	AutoClose bool
 }
 
+// PingNotifier 由能够上报服务端心跳事件的流实现，如Anthropic的ping事件
+type PingNotifier interface {
+	// SetPingHandler 设置收到心跳事件时的回调，需要在Recv之前调用
+	SetPingHandler(handler func())
+}
+
 // DefaultStreamOptions 返回默认的流式选项
 func DefaultStreamOptions() *StreamOptions {
 	return &StreamOptions{
@@ -58,6 +71,12 @@ func (p *DefaultStreamProcessor) Process(stream ResponseStream, options *StreamO
 		}
 	}()
 
+	if options.OnPing != nil {
+		if notifier, ok := stream.(PingNotifier); ok {
+			notifier.SetPingHandler(options.OnPing)
+		}
+	}
+
 	for {
 		chunk, err := stream.Recv()
 		if err == io.EOF {
@@ -83,21 +102,35 @@ func (p *DefaultStreamProcessor) Process(stream ResponseStream, options *StreamO
 			}
 		}
 
-		// 如果有纯文本处理回调，提取并传递文本内容
-		if options.OnText != nil && len(chunk.Choices) > 0 {
-			content := chunk.Choices[0].Delta.Content
-			if content != "" {
-				if err := options.OnText(content); err != nil {
-					if options.OnComplete != nil {
-						options.OnComplete(err)
-					}
-					return err
+		// 按候选序号分发文本内容；多候选时同一个响应块可能只包含其中部分候选
+		for i := range chunk.Choices {
+			if err := dispatchText(options, &chunk.Choices[i]); err != nil {
+				if options.OnComplete != nil {
+					options.OnComplete(err)
 				}
+				return err
 			}
 		}
 	}
 }
 
+// dispatchText 将候选的文本增量交给OnText和OnCandidateText
+func dispatchText(options *StreamOptions, choice *ChunkChoice) error {
+	content := choice.Delta.Content
+	if content == "" {
+		return nil
+	}
+	if options.OnText != nil && choice.Index == 0 {
+		if err := options.OnText(content); err != nil {
+			return err
+		}
+	}
+	if options.OnCandidateText != nil {
+		return options.OnCandidateText(choice.Index, content)
+	}
+	return nil
+}
+
 // ProcessWithHandler 实现了简化的流式处理，只关注文本内容
 func (p *DefaultStreamProcessor) ProcessWithHandler(stream ResponseStream, handler func(text string) error) error {
 	return p.Process(stream, &StreamOptions{
@@ -108,14 +141,16 @@ func (p *DefaultStreamProcessor) ProcessWithHandler(stream ResponseStream, handl
 
 // 以下是流式响应相关的便捷函数
 
-// CollectFullContent 从流式响应中收集完整内容
+// CollectFullContent 从流式响应中收集第一个候选的完整文本内容
+//
+// 需要结束原因、工具调用、用量等完整信息时，使用AccumulateStream。
 func CollectFullContent(stream ResponseStream) (string, error) {
-	var fullContent string
+	var fullContent strings.Builder
 	err := NewStreamProcessor().ProcessWithHandler(stream, func(text string) error {
-		fullContent += text
+		fullContent.WriteString(text)
 		return nil
 	})
-	return fullContent, err
+	return fullContent.String(), err
 }
 
 // StreamToWriter 将流式响应输出到一个io.Writer
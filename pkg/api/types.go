@@ -10,12 +10,83 @@ const (
 	RoleUser Role = "user"
 	// RoleAssistant 助手消息角色
 	RoleAssistant Role = "assistant"
+	// RoleTool 工具调用结果消息角色
+	RoleTool Role = "tool"
 )
 
 // Message 定义对话消息
 type Message struct {
 	Role    Role   `json:"role"`
 	Content string `json:"content"`
+
+	// Name 工具消息对应的函数名称（部分提供商要求）
+	Name string `json:"name,omitempty"`
+	// ToolCalls 助手消息中模型发起的工具调用
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID 工具消息所响应的工具调用ID
+	ToolCallID string `json:"tool_call_id,omitempty"`
+
+	// ReasoningContent 模型的思考/推理文本，与Content分开存放
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+	// ThinkingBlocks 原始思考块（含签名），多轮对话中需随助手消息原样回传
+	ThinkingBlocks []ThinkingBlock `json:"thinking_blocks,omitempty"`
+
+	// Parts 分段的消息内容，设置后代替Content发送，以便为单独的段落设置缓存断点（目前仅Anthropic支持）
+	Parts []ContentPart `json:"-"`
+	// CacheControl 在该消息末尾设置提示缓存断点（目前仅Anthropic支持）
+	CacheControl *CacheControl `json:"-"`
+}
+
+// ContentPart 定义消息中的一段内容
+type ContentPart struct {
+	// Type 目前只支持ContentPartTypeText
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+	// CacheControl 在该段落末尾设置提示缓存断点
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+}
+
+// ContentPartTypeText 文本类型的内容段
+const ContentPartTypeText = "text"
+
+// CacheControl 定义提示缓存断点，断点之前的内容会被提供商缓存以便后续请求复用
+type CacheControl struct {
+	// Type 目前只支持CacheControlEphemeral
+	Type string `json:"type"`
+	// TTL 缓存有效期，如"5m"或"1h"，为空时使用提供商的默认值
+	TTL string `json:"ttl,omitempty"`
+}
+
+// CacheControlEphemeral 短期缓存
+const CacheControlEphemeral = "ephemeral"
+
+// ThinkingBlock 定义模型输出的思考块
+type ThinkingBlock struct {
+	// Type 为ThinkingTypeThinking或ThinkingTypeRedacted
+	Type      string `json:"type"`
+	Thinking  string `json:"thinking,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	// Data 被安全系统加密的思考内容，仅redacted_thinking类型使用
+	Data string `json:"data,omitempty"`
+}
+
+// 思考块类型
+const (
+	ThinkingTypeThinking = "thinking"
+	ThinkingTypeRedacted = "redacted_thinking"
+)
+
+// 推理强度
+const (
+	ReasoningEffortLow    = "low"
+	ReasoningEffortMedium = "medium"
+	ReasoningEffortHigh   = "high"
+)
+
+// ThinkingConfig 定义扩展思考配置
+type ThinkingConfig struct {
+	// BudgetTokens 思考过程可使用的最大token数
+	BudgetTokens int `json:"budget_tokens"`
 }
 
 // Request 定义请求参数
@@ -33,10 +104,157 @@ type Request struct {
 	Stop             []string `json:"stop,omitempty"`
 	Stream           bool     `json:"stream,omitempty"`
 
+	// TopK 只从概率最高的K个token中采样（Anthropic、Gemini、Cohere和通义千问支持）
+	TopK *int `json:"top_k,omitempty"`
+	// Seed 随机种子，相同的种子和参数尽量产生相同的输出
+	Seed *int `json:"seed,omitempty"`
+	// LogitBias 调整指定token ID出现的概率，取值范围-100到100
+	LogitBias map[string]float64 `json:"logit_bias,omitempty"`
+	// User 终端用户的唯一标识，用于提供商的滥用监控
+	User string `json:"user,omitempty"`
+
+	// N 生成的候选数量，为0或1时只生成一个候选。
+	// OpenAI和Gemini原生支持，Anthropic和DeepSeek通过并行请求模拟（用量为各次请求之和）
+	N int `json:"n,omitempty"`
+
+	// Logprobs 返回输出token的对数概率（OpenAI、DeepSeek和Gemini支持）
+	Logprobs bool `json:"logprobs,omitempty"`
+	// TopLogprobs 每个位置额外返回的最可能token数量，需要同时开启Logprobs
+	TopLogprobs int `json:"top_logprobs,omitempty"`
+
+	// 函数调用
+	Tools []Tool `json:"tools,omitempty"`
+	// ToolChoice 可以是"auto"、"none"、"any"/"required"等字符串，或提供商支持的对象结构
+	ToolChoice interface{} `json:"tool_choice,omitempty"`
+	// ParallelToolCalls 是否允许模型在一次回复中调用多个工具，为空时使用提供商的默认行为
+	ParallelToolCalls *bool `json:"parallel_tool_calls,omitempty"`
+
+	// ResponseFormat 指定输出格式（如JSON模式）
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+
+	// Thinking 开启扩展思考，为空时不开启
+	Thinking *ThinkingConfig `json:"thinking,omitempty"`
+	// ReasoningEffort 推理模型的推理强度，取值为ReasoningEffortLow等
+	ReasoningEffort string `json:"reasoning_effort,omitempty"`
+
+	// SafetySettings 指定内容安全过滤阈值，为空时使用提供商的默认配置
+	SafetySettings []SafetySetting `json:"safety_settings,omitempty"`
+
+	// Metadata 随请求附带的键值对，用于在提供商的控制台中检索和统计
+	Metadata map[string]string `json:"metadata,omitempty"`
+
 	// 自定义字段，用于提供商特定的参数
 	ExtraParams map[string]interface{} `json:"-"`
 }
 
+// Tool 定义模型可调用的工具
+type Tool struct {
+	Type     string             `json:"type"`
+	Function FunctionDefinition `json:"function"`
+
+	// CacheControl 在该工具定义末尾设置提示缓存断点（目前仅Anthropic支持）
+	CacheControl *CacheControl `json:"-"`
+}
+
+// FunctionDefinition 定义函数工具的描述
+type FunctionDefinition struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Parameters  interface{} `json:"parameters,omitempty"` // JSON Schema
+}
+
+// ToolCall 定义模型发起的工具调用
+type ToolCall struct {
+	// Index 仅在流式增量中使用，用于拼接同一调用的多个片段
+	Index    *int         `json:"index,omitempty"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type"`
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall 定义函数调用的名称和参数
+type FunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"` // JSON编码的参数
+}
+
+// ToolTypeFunction 函数类型的工具
+const ToolTypeFunction = "function"
+
+// ResponseFormat 定义响应格式
+type ResponseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema interface{} `json:"json_schema,omitempty"`
+}
+
+// 响应格式类型
+const (
+	// ResponseFormatText 普通文本输出
+	ResponseFormatText = "text"
+	// ResponseFormatJSONObject JSON模式输出
+	ResponseFormatJSONObject = "json_object"
+	// ResponseFormatJSONSchema 按JSON Schema输出
+	ResponseFormatJSONSchema = "json_schema"
+)
+
+// SafetySetting 定义某一类有害内容的拦截阈值
+type SafetySetting struct {
+	Category  string `json:"category"`
+	Threshold string `json:"threshold"`
+}
+
+// SafetyRating 定义模型对某一类有害内容的评估结果
+type SafetyRating struct {
+	Category    string `json:"category"`
+	Probability string `json:"probability"`
+	Blocked     bool   `json:"blocked,omitempty"`
+}
+
+// PromptFeedback 定义提供商对输入提示的安全反馈
+type PromptFeedback struct {
+	// BlockReason 不为空时表示提示被拦截，没有生成任何候选
+	BlockReason   string         `json:"block_reason,omitempty"`
+	SafetyRatings []SafetyRating `json:"safety_ratings,omitempty"`
+}
+
+// FinishReason 定义标准化的结束原因，各提供商的原始值会被映射到以下取值之一
+type FinishReason string
+
+const (
+	// FinishReasonStop 自然结束或命中停止序列
+	FinishReasonStop FinishReason = "stop"
+	// FinishReasonLength 达到最大令牌数或上下文长度限制
+	FinishReasonLength FinishReason = "length"
+	// FinishReasonToolCalls 模型请求调用工具
+	FinishReasonToolCalls FinishReason = "tool_calls"
+	// FinishReasonContentFilter 输出因内容安全策略被拦截
+	FinishReasonContentFilter FinishReason = "content_filter"
+	// FinishReasonError 提供商在生成过程中出错
+	FinishReasonError FinishReason = "error"
+	// FinishReasonOther 无法归类的其他原因，原始值见RawFinishReason
+	FinishReasonOther FinishReason = "other"
+)
+
+// NormalizeFinishReason 将OpenAI兼容格式的结束原因映射为标准值
+//
+// 空字符串表示尚未结束，返回空值；无法识别的取值返回FinishReasonOther。
+func NormalizeFinishReason(raw string) FinishReason {
+	switch raw {
+	case "":
+		return ""
+	case "stop":
+		return FinishReasonStop
+	case "length":
+		return FinishReasonLength
+	case "tool_calls", "function_call":
+		return FinishReasonToolCalls
+	case "content_filter":
+		return FinishReasonContentFilter
+	default:
+		return FinishReasonOther
+	}
+}
+
 // Response 定义完整响应
 type Response struct {
 	ID      string   `json:"id"`
@@ -45,6 +263,12 @@ type Response struct {
 	Model   string   `json:"model"`
 	Choices []Choice `json:"choices"`
 	Usage   Usage    `json:"usage"`
+
+	// PromptFeedback 输入提示的安全反馈，仅部分提供商返回
+	PromptFeedback *PromptFeedback `json:"prompt_feedback,omitempty"`
+
+	// Warnings 请求中被提供商忽略的参数等提示，严格模式下这些情况会直接返回错误
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // ResponseChunk 定义流式响应的数据块
@@ -54,20 +278,41 @@ type ResponseChunk struct {
 	Created int64         `json:"created"`
 	Model   string        `json:"model"`
 	Choices []ChunkChoice `json:"choices"`
+	// Usage 令牌使用情况，通常只在最后一个响应块中返回
+	Usage *Usage `json:"usage,omitempty"`
+
+	// Warnings 请求中被提供商忽略的参数等提示，通常只出现在第一个响应块中
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // Choice 定义响应中的选择
 type Choice struct {
-	Index        int     `json:"index"`
-	Message      Message `json:"message"`
-	FinishReason string  `json:"finish_reason"`
+	Index        int          `json:"index"`
+	Message      Message      `json:"message"`
+	FinishReason FinishReason `json:"finish_reason"`
+	// RawFinishReason 提供商返回的原始结束原因
+	RawFinishReason string `json:"raw_finish_reason,omitempty"`
+
+	// SafetyRatings 该候选的安全评估，仅部分提供商返回
+	SafetyRatings []SafetyRating `json:"safety_ratings,omitempty"`
+
+	// Logprobs 输出token的对数概率，仅在请求开启Logprobs时返回
+	Logprobs []TokenLogprob `json:"logprobs,omitempty"`
 }
 
 // ChunkChoice 定义流式响应中的选择
 type ChunkChoice struct {
-	Index        int     `json:"index"`
-	Delta        Message `json:"delta"`
-	FinishReason string  `json:"finish_reason,omitempty"`
+	Index        int          `json:"index"`
+	Delta        Message      `json:"delta"`
+	FinishReason FinishReason `json:"finish_reason,omitempty"`
+	// RawFinishReason 提供商返回的原始结束原因
+	RawFinishReason string `json:"raw_finish_reason,omitempty"`
+
+	// SafetyRatings 该候选的安全评估，仅部分提供商返回
+	SafetyRatings []SafetyRating `json:"safety_ratings,omitempty"`
+
+	// Logprobs 输出token的对数概率，仅在请求开启Logprobs时返回
+	Logprobs []TokenLogprob `json:"logprobs,omitempty"`
 }
 
 // Usage 定义令牌使用情况
@@ -75,4 +320,12 @@ type Usage struct {
 	PromptTokens     int `json:"prompt_tokens"`
 	CompletionTokens int `json:"completion_tokens"`
 	TotalTokens      int `json:"total_tokens"`
+
+	// ReasoningTokens 推理模型用于思考的token数，已包含在CompletionTokens中
+	ReasoningTokens int `json:"reasoning_tokens,omitempty"`
+
+	// CacheCreationTokens 本次写入提示缓存的输入token数，已包含在PromptTokens中
+	CacheCreationTokens int `json:"cache_creation_tokens,omitempty"`
+	// CacheReadTokens 命中提示缓存的输入token数，已包含在PromptTokens中
+	CacheReadTokens int `json:"cache_read_tokens,omitempty"`
 }
@@ -0,0 +1,117 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// LoadCompleted 从已有的输出中读取执行成功的记录ID
+//
+// 无法解析的行（例如进程中断时写了一半的最后一行）会被忽略，对应的记录在续跑时重新执行。
+func LoadCompleted(r io.Reader) (map[string]bool, error) {
+	completed := map[string]bool{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), defaultMaxLineSize)
+	for scanner.Scan() {
+		var result Result
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			continue
+		}
+		if result.Succeeded() {
+			completed[result.ID] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取检查点失败: %w", err)
+	}
+	return completed, nil
+}
+
+// RunFile 执行inputPath中的记录并把结果写入outputPath
+//
+// resume为true且输出文件已存在时，输出文件作为检查点：已成功的记录被跳过，新结果追加到文件末尾，
+// 同一ID以最后一条记录为准；否则输出文件会被覆盖。
+func (r *Runner) RunFile(ctx context.Context, inputPath, outputPath string, resume bool) (*Summary, error) {
+	in, err := os.Open(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("打开输入文件失败: %w", err)
+	}
+	defer in.Close()
+
+	completed := map[string]bool{}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resume {
+		completed, err = loadCheckpoint(outputPath)
+		if err != nil {
+			return nil, err
+		}
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	out, err := os.OpenFile(outputPath, flags, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("打开输出文件失败: %w", err)
+	}
+	defer out.Close()
+
+	if resume {
+		if err := terminateLastLine(out, outputPath); err != nil {
+			return nil, err
+		}
+	}
+
+	// 每条结果直接写入文件，不做缓冲，保证中断时已完成的记录都已落盘
+	summary, runErr := r.Run(ctx, in, out, completed)
+	if err := out.Sync(); err != nil && runErr == nil {
+		runErr = fmt.Errorf("同步输出文件失败: %w", err)
+	}
+	return summary, runErr
+}
+
+// loadCheckpoint 读取输出文件中已成功的记录，文件不存在时返回空集合
+func loadCheckpoint(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return map[string]bool{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开检查点失败: %w", err)
+	}
+	defer f.Close()
+	return LoadCompleted(f)
+}
+
+// terminateLastLine 如果输出文件的最后一行没有换行符（中断时只写了一半），补上换行，
+// 避免新结果与残缺的行拼接在一起
+func terminateLastLine(out *os.File, path string) error {
+	info, err := out.Stat()
+	if err != nil {
+		return fmt.Errorf("读取输出文件信息失败: %w", err)
+	}
+	if info.Size() == 0 {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("打开输出文件失败: %w", err)
+	}
+	defer f.Close()
+
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, info.Size()-1); err != nil {
+		return fmt.Errorf("读取输出文件失败: %w", err)
+	}
+	if !bytes.Equal(last, []byte("\n")) {
+		if _, err := out.Write([]byte("\n")); err != nil {
+			return fmt.Errorf("写入输出文件失败: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package batch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadCompleted(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   map[string]bool
+	}{
+		{name: "empty", output: "", want: map[string]bool{}},
+		{
+			name: "only successes",
+			output: `{"id":"a","response":{"id":"r"},"attempts":1}` + "\n" +
+				`{"id":"b","error":{"type":"server_error","message":"x"},"attempts":3}` + "\n",
+			want: map[string]bool{"a": true},
+		},
+		{
+			name: "truncated last line ignored",
+			output: `{"id":"a","response":{"id":"r"},"attempts":1}` + "\n" +
+				`{"id":"b","response":{"id":"r"`,
+			want: map[string]bool{"a": true},
+		},
+		{
+			name: "later failure keeps earlier success",
+			output: `{"id":"a","response":{"id":"r"},"attempts":1}` + "\n" +
+				`{"id":"a","error":{"type":"server_error","message":"x"},"attempts":1}` + "\n",
+			want: map[string]bool{"a": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadCompleted(strings.NewReader(tt.output))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunFileResume(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "input.jsonl")
+	outputPath := filepath.Join(dir, "output.jsonl")
+
+	if err := os.WriteFile(inputPath, []byte(inputLines("a", "b", "c")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// 上次运行完成了a，写b时被中断，最后一行没有换行符
+	previous := `{"id":"a","response":{"id":"r"},"attempts":1}` + "\n" + `{"id":"b","resp`
+	if err := os.WriteFile(outputPath, []byte(previous), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &fakeClient{}
+	summary, err := NewRunner(client).RunFile(context.Background(), inputPath, outputPath, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Skipped != 1 || summary.Succeeded != 2 {
+		t.Errorf("summary = %+v", summary)
+	}
+	if client.callCount("a") != 0 {
+		t.Error("completed record was executed again")
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 4 || lines[1] != `{"id":"b","resp` {
+		t.Fatalf("output lines = %q, want the truncated line kept on its own line", lines)
+	}
+
+	completed, err := LoadCompleted(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"a": true, "b": true, "c": true}; !reflect.DeepEqual(completed, want) {
+		t.Errorf("completed = %v, want %v", completed, want)
+	}
+}
+
+func TestRunFileOverwrite(t *testing.T) {
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "input.jsonl")
+	outputPath := filepath.Join(dir, "output.jsonl")
+
+	if err := os.WriteFile(inputPath, []byte(inputLines("a")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(outputPath, []byte(`{"id":"a","response":{"id":"old"},"attempts":1}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &fakeClient{}
+	if _, err := NewRunner(client).RunFile(context.Background(), inputPath, outputPath, false); err != nil {
+		t.Fatal(err)
+	}
+	if client.callCount("a") != 1 {
+		t.Error("record not executed without resume")
+	}
+	data, _ := os.ReadFile(outputPath)
+	if strings.Contains(string(data), "old") || strings.Count(string(data), "\n") != 1 {
+		t.Errorf("output not overwritten: %s", data)
+	}
+}
@@ -0,0 +1,373 @@
+// Package batch 提供在本地并发执行JSONL请求文件的批处理工具
+//
+// 输入文件每行是一个Record，输出文件每行是一个Result。输出文件同时作为检查点：
+// 以续跑模式运行时，已成功的记录会被跳过，不会重复计费。
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// 默认配置
+const (
+	defaultConcurrency    = 4
+	defaultMaxRetries     = 3
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = time.Minute
+	defaultMaxLineSize    = 10 * 1024 * 1024
+)
+
+// Record 定义输入文件中的一行
+type Record struct {
+	ID      string       `json:"id"`
+	Request *api.Request `json:"request"`
+}
+
+// Result 定义输出文件中的一行
+type Result struct {
+	ID       string        `json:"id"`
+	Response *api.Response `json:"response,omitempty"`
+	Error    *ErrorInfo    `json:"error,omitempty"`
+	// Attempts 实际发送请求的次数
+	Attempts int `json:"attempts"`
+	// DurationMS 包含重试在内的总耗时（毫秒）
+	DurationMS int64 `json:"duration_ms"`
+}
+
+// Succeeded 判断该记录是否执行成功
+func (r *Result) Succeeded() bool {
+	return r.Error == nil && r.Response != nil
+}
+
+// ErrorInfo 定义写入输出文件的错误信息
+type ErrorInfo struct {
+	Type       string `json:"type"`
+	Message    string `json:"message"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Code       string `json:"code,omitempty"`
+}
+
+// Summary 定义一次运行的统计
+type Summary struct {
+	// Total 已读取的记录数，运行被取消时可能小于输入文件的总行数
+	Total int
+	// Skipped 续跑时因已成功而跳过的记录数
+	Skipped   int
+	Succeeded int
+	Failed    int
+}
+
+// Options 定义批处理运行配置
+type Options struct {
+	// Concurrency 并发执行的请求数
+	Concurrency int
+	// MaxRetries 可重试错误（限流、服务端错误、超时、连接错误）的最大重试次数
+	MaxRetries int
+	// InitialBackoff 和 MaxBackoff 控制指数退避的初始和最大间隔
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// RequestsPerSecond 每秒最多发起的请求数，为0时不限制
+	RequestsPerSecond float64
+	// MaxLineSize 输入文件单行的最大长度
+	MaxLineSize int
+	// OnResult 每条记录完成时的回调，可用于展示进度
+	OnResult func(result *Result)
+}
+
+// Option 定义批处理配置选项
+type Option func(options *Options)
+
+// Runner 通过LLMClient并发执行批处理请求
+type Runner struct {
+	client  api.LLMClient
+	options Options
+
+	// pauseUntil 遇到限流时所有worker暂停到该时间
+	mu         sync.Mutex
+	pauseUntil time.Time
+}
+
+// NewRunner 创建批处理执行器
+func NewRunner(client api.LLMClient, options ...Option) *Runner {
+	opts := Options{
+		Concurrency:    defaultConcurrency,
+		MaxRetries:     defaultMaxRetries,
+		InitialBackoff: defaultInitialBackoff,
+		MaxBackoff:     defaultMaxBackoff,
+		MaxLineSize:    defaultMaxLineSize,
+	}
+
+	// 应用选项
+	for _, option := range options {
+		option(&opts)
+	}
+
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = defaultConcurrency
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = defaultInitialBackoff
+	}
+	if opts.MaxBackoff < opts.InitialBackoff {
+		opts.MaxBackoff = opts.InitialBackoff
+	}
+	if opts.MaxLineSize <= 0 {
+		opts.MaxLineSize = defaultMaxLineSize
+	}
+
+	return &Runner{
+		client:  client,
+		options: opts,
+	}
+}
+
+// Run 读取in中的记录并发执行，结果逐行写入out
+//
+// completed中的ID会被跳过。ctx被取消时停止分发新记录，正在执行的记录不会写入结果，
+// 续跑时会重新执行。
+func (r *Runner) Run(ctx context.Context, in io.Reader, out io.Writer, completed map[string]bool) (*Summary, error) {
+	summary := &Summary{}
+	records := make(chan Record)
+	results := make(chan *Result)
+
+	// 限速器
+	var ticks <-chan time.Time
+	if r.options.RequestsPerSecond > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / r.options.RequestsPerSecond))
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	// 启动worker
+	var wg sync.WaitGroup
+	for i := 0; i < r.options.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for record := range records {
+				result := r.execute(ctx, record, ticks)
+				if result == nil {
+					// 因取消而中断，不写入结果
+					continue
+				}
+				results <- result
+			}
+		}()
+	}
+
+	// 写入结果
+	var writeErr error
+	writeDone := make(chan struct{})
+	go func() {
+		defer close(writeDone)
+		encoder := json.NewEncoder(out)
+		for result := range results {
+			if result.Succeeded() {
+				summary.Succeeded++
+			} else {
+				summary.Failed++
+			}
+			if writeErr == nil {
+				if err := encoder.Encode(result); err != nil {
+					writeErr = fmt.Errorf("写入结果失败: %w", err)
+				}
+			}
+			if r.options.OnResult != nil {
+				r.options.OnResult(result)
+			}
+		}
+	}()
+
+	// 读取输入并分发
+	readErr := r.dispatch(ctx, in, records, results, completed, summary)
+	close(records)
+	wg.Wait()
+	close(results)
+	<-writeDone
+
+	if readErr != nil {
+		return summary, readErr
+	}
+	if writeErr != nil {
+		return summary, writeErr
+	}
+	return summary, ctx.Err()
+}
+
+// dispatch 逐行读取输入并把待执行的记录发送给worker
+func (r *Runner) dispatch(ctx context.Context, in io.Reader, records chan<- Record, results chan<- *Result, completed map[string]bool, summary *Summary) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), r.options.MaxLineSize)
+
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		summary.Total++
+
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil || record.ID == "" || record.Request == nil {
+			id := record.ID
+			if id == "" {
+				id = fmt.Sprintf("line-%d", lineNo)
+			}
+			message := fmt.Sprintf("第%d行不是有效的记录，需要包含id和request字段", lineNo)
+			if err != nil {
+				message = fmt.Sprintf("第%d行解析失败: %v", lineNo, err)
+			}
+			select {
+			case results <- &Result{ID: id, Error: &ErrorInfo{Type: string(api.ErrorTypeInvalidRequest), Message: message}}:
+			case <-ctx.Done():
+				return nil
+			}
+			continue
+		}
+
+		if completed[record.ID] {
+			summary.Skipped++
+			continue
+		}
+
+		select {
+		case records <- record:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("读取输入失败(第%d行之后): %w", lineNo, err)
+	}
+	return nil
+}
+
+// execute 执行单条记录，必要时重试；ctx被取消时返回nil
+func (r *Runner) execute(ctx context.Context, record Record, ticks <-chan time.Time) *Result {
+	start := time.Now()
+	result := &Result{ID: record.ID}
+
+	for attempt := 0; ; attempt++ {
+		// 等待限流暂停和限速
+		if err := r.wait(ctx, ticks); err != nil {
+			return nil
+		}
+
+		result.Attempts++
+		response, err := r.client.Complete(ctx, record.Request)
+		if err == nil {
+			result.Response = response
+			break
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if attempt >= r.options.MaxRetries || !retryable(err) {
+			result.Error = errorInfo(err)
+			break
+		}
+
+		backoff := r.backoff(attempt)
+		var apiErr *api.Error
+		if errors.As(err, &apiErr) && apiErr.Type == api.ErrorTypeRateLimit {
+			// 限流时让所有worker一起暂停
+			r.pause(backoff)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	result.DurationMS = time.Since(start).Milliseconds()
+	return result
+}
+
+// wait 等待限流暂停结束并获取限速令牌
+func (r *Runner) wait(ctx context.Context, ticks <-chan time.Time) error {
+	r.mu.Lock()
+	delay := time.Until(r.pauseUntil)
+	r.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if ticks != nil {
+		select {
+		case <-ticks:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return ctx.Err()
+}
+
+// pause 让所有worker暂停一段时间
+func (r *Runner) pause(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if until := time.Now().Add(d); until.After(r.pauseUntil) {
+		r.pauseUntil = until
+	}
+}
+
+// backoff 计算第attempt次重试前的等待时间，带随机抖动
+func (r *Runner) backoff(attempt int) time.Duration {
+	d := r.options.InitialBackoff << uint(attempt)
+	if d <= 0 || d > r.options.MaxBackoff {
+		d = r.options.MaxBackoff
+	}
+	// 在[d/2, d)之间随机，避免多个worker同时重试
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryable 判断错误是否值得重试
+func retryable(err error) bool {
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.Type {
+	case api.ErrorTypeRateLimit, api.ErrorTypeServer, api.ErrorTypeTimeout, api.ErrorTypeConnection:
+		return true
+	}
+	return false
+}
+
+// errorInfo 将错误转换为可序列化的错误信息
+func errorInfo(err error) *ErrorInfo {
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) {
+		return &ErrorInfo{
+			Type:       string(apiErr.Type),
+			Message:    apiErr.Message,
+			StatusCode: apiErr.StatusCode,
+			Code:       apiErr.Code,
+		}
+	}
+	return &ErrorInfo{
+		Type:    string(api.ErrorTypeUnknown),
+		Message: err.Error(),
+	}
+}
@@ -0,0 +1,225 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// fakeClient 是测试用的LLMClient，按记录内容决定每次调用的结果
+type fakeClient struct {
+	complete func(ctx context.Context, request *api.Request, attempt int) (*api.Response, error)
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (c *fakeClient) Complete(ctx context.Context, request *api.Request) (*api.Response, error) {
+	content := request.Messages[0].Content
+	c.mu.Lock()
+	if c.calls == nil {
+		c.calls = map[string]int{}
+	}
+	c.calls[content]++
+	attempt := c.calls[content]
+	c.mu.Unlock()
+
+	if c.complete != nil {
+		return c.complete(ctx, request, attempt)
+	}
+	return &api.Response{Choices: []api.Choice{{Message: api.Message{Content: content}}}}, nil
+}
+
+func (c *fakeClient) CompleteStream(ctx context.Context, request *api.Request) (api.ResponseStream, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeClient) Embedding(ctx context.Context, input string) ([]float32, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeClient) callCount(content string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.calls[content]
+}
+
+// inputLines 返回记录ID和内容都为ids的输入文件
+func inputLines(ids ...string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, id := range ids {
+		encoder.Encode(Record{ID: id, Request: &api.Request{Model: "m", Messages: []api.Message{{Role: api.RoleUser, Content: id}}}})
+	}
+	return buf.String()
+}
+
+// parseResults 解析输出文件中的结果，按ID索引
+func parseResults(t *testing.T, output string) map[string]Result {
+	t.Helper()
+	results := map[string]Result{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		if line == "" {
+			continue
+		}
+		var result Result
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			t.Fatalf("invalid output line %q: %v", line, err)
+		}
+		results[result.ID] = result
+	}
+	return results
+}
+
+// fastRetry 使用很短的退避时间，避免测试变慢
+func fastRetry(options *Options) {
+	options.InitialBackoff = time.Millisecond
+	options.MaxBackoff = 2 * time.Millisecond
+}
+
+func TestRunnerRetry(t *testing.T) {
+	rateLimited := api.NewError(api.ErrorTypeRateLimit, "限流", 429, nil)
+	invalid := api.NewError(api.ErrorTypeInvalidRequest, "参数错误", 400, nil)
+
+	tests := []struct {
+		name         string
+		maxRetries   int
+		failures     int
+		err          error
+		wantAttempts int
+		wantErrType  api.ErrorType
+	}{
+		{name: "success", maxRetries: 3, wantAttempts: 1},
+		{name: "retry until success", maxRetries: 3, failures: 2, err: rateLimited, wantAttempts: 3},
+		{name: "retries exhausted", maxRetries: 2, failures: 10, err: rateLimited, wantAttempts: 3, wantErrType: api.ErrorTypeRateLimit},
+		{name: "server error retried", maxRetries: 1, failures: 1, err: api.NewError(api.ErrorTypeServer, "内部错误", 500, nil), wantAttempts: 2},
+		{name: "not retryable", maxRetries: 3, failures: 10, err: invalid, wantAttempts: 1, wantErrType: api.ErrorTypeInvalidRequest},
+		{name: "plain error not retryable", maxRetries: 3, failures: 10, err: errors.New("boom"), wantAttempts: 1, wantErrType: api.ErrorTypeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeClient{complete: func(ctx context.Context, request *api.Request, attempt int) (*api.Response, error) {
+				if attempt <= tt.failures {
+					return nil, tt.err
+				}
+				return &api.Response{Choices: []api.Choice{{Message: api.Message{Content: "ok"}}}}, nil
+			}}
+			runner := NewRunner(client, fastRetry, func(options *Options) { options.MaxRetries = tt.maxRetries })
+
+			var out bytes.Buffer
+			summary, err := runner.Run(context.Background(), strings.NewReader(inputLines("r1")), &out, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := parseResults(t, out.String())["r1"]
+			if result.Attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", result.Attempts, tt.wantAttempts)
+			}
+			if tt.wantErrType == "" {
+				if !result.Succeeded() || summary.Succeeded != 1 {
+					t.Errorf("result = %+v, summary = %+v", result, summary)
+				}
+				return
+			}
+			if result.Error == nil || result.Error.Type != string(tt.wantErrType) || summary.Failed != 1 {
+				t.Errorf("result = %+v, summary = %+v", result, summary)
+			}
+		})
+	}
+}
+
+func TestRunnerBackoff(t *testing.T) {
+	runner := NewRunner(&fakeClient{}, func(options *Options) {
+		options.InitialBackoff = 100 * time.Millisecond
+		options.MaxBackoff = time.Second
+	})
+
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 0, max: 100 * time.Millisecond},
+		{attempt: 1, max: 200 * time.Millisecond},
+		{attempt: 3, max: 800 * time.Millisecond},
+		{attempt: 4, max: time.Second},
+		{attempt: 70, max: time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			if d := runner.backoff(tt.attempt); d < tt.max/2 || d > tt.max {
+				t.Errorf("backoff(%d) = %v, want in [%v, %v]", tt.attempt, d, tt.max/2, tt.max)
+			}
+		}
+	}
+}
+
+func TestRunnerSkipsCompletedAndInvalidLines(t *testing.T) {
+	client := &fakeClient{}
+	runner := NewRunner(client)
+	input := inputLines("a", "b") + "not json\n\n" + `{"id":"c"}` + "\n" + inputLines("d")
+
+	var out bytes.Buffer
+	summary, err := runner.Run(context.Background(), strings.NewReader(input), &out, map[string]bool{"b": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Summary{Total: 5, Skipped: 1, Succeeded: 2, Failed: 2}
+	if *summary != want {
+		t.Errorf("summary = %+v, want %+v", *summary, want)
+	}
+	if client.callCount("b") != 0 {
+		t.Error("completed record was executed again")
+	}
+	results := parseResults(t, out.String())
+	for _, id := range []string{"line-3", "c"} {
+		if result, ok := results[id]; !ok || result.Error == nil || result.Error.Type != string(api.ErrorTypeInvalidRequest) {
+			t.Errorf("result %s = %+v", id, result)
+		}
+	}
+}
+
+func TestRunnerCancelDropsInflightResults(t *testing.T) {
+	started := make(chan struct{})
+	client := &fakeClient{complete: func(ctx context.Context, request *api.Request, attempt int) (*api.Response, error) {
+		if request.Messages[0].Content == "fast" {
+			return &api.Response{}, nil
+		}
+		close(started)
+		<-ctx.Done()
+		return nil, api.NewError(api.ErrorTypeConnection, "请求被取消", 0, ctx.Err())
+	}}
+	runner := NewRunner(client, func(options *Options) { options.Concurrency = 1 })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	var out bytes.Buffer
+	summary, err := runner.Run(ctx, strings.NewReader(inputLines("fast", "slow", "never")), &out, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+
+	results := parseResults(t, out.String())
+	if _, ok := results["fast"]; !ok || len(results) != 1 {
+		t.Errorf("results = %+v, want only the finished record", results)
+	}
+	if client.callCount("never") != 0 {
+		t.Error("record dispatched after cancel was executed")
+	}
+	if summary.Succeeded != 1 || summary.Failed != 0 {
+		t.Errorf("summary = %+v", summary)
+	}
+}
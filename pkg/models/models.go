@@ -1,5 +1,9 @@
 package models
 
+import (
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
 // 定义不同提供商的模型常量
 
 // OpenAI 模型
@@ -27,26 +31,53 @@ const (
 // Anthropic 模型
 const (
 	// ClaudeHaiku 是Anthropic的轻量级Claude模型
-	ClaudeHaiku = "claude-haiku"
+	//
+	// Deprecated: "claude-haiku"不是有效的模型ID，现指向Claude3Haiku，请直接使用带日期的模型常量。
+	ClaudeHaiku = Claude3Haiku
 	// ClaudeSonnet 是Anthropic的中型Claude模型
-	ClaudeSonnet = "claude-sonnet"
+	//
+	// Deprecated: "claude-sonnet"不是有效的模型ID，现指向ClaudeSonnet4，请直接使用带日期的模型常量。
+	ClaudeSonnet = ClaudeSonnet4
 	// ClaudeOpus 是Anthropic的高能力Claude模型
-	ClaudeOpus = "claude-opus"
+	//
+	// Deprecated: "claude-opus"不是有效的模型ID，现指向ClaudeOpus4，请直接使用带日期的模型常量。
+	ClaudeOpus = ClaudeOpus4
 	// Claude3Haiku 是Anthropic的Claude 3 Haiku模型
-	Claude3Haiku = "claude-3-haiku"
+	Claude3Haiku = "claude-3-haiku-20240307"
 	// Claude3Sonnet 是Anthropic的Claude 3 Sonnet模型
-	Claude3Sonnet = "claude-3-sonnet"
+	Claude3Sonnet = "claude-3-sonnet-20240229"
 	// Claude3Opus 是Anthropic的Claude 3 Opus模型
-	Claude3Opus = "claude-3-opus"
+	Claude3Opus = "claude-3-opus-20240229"
+	// Claude37Sonnet 是Anthropic的Claude 3.7 Sonnet模型，支持扩展思考
+	Claude37Sonnet = "claude-3-7-sonnet-20250219"
+	// ClaudeSonnet4 是Anthropic的Claude Sonnet 4模型，支持扩展思考
+	ClaudeSonnet4 = "claude-sonnet-4-20250514"
+	// ClaudeOpus4 是Anthropic的Claude Opus 4模型，支持扩展思考
+	ClaudeOpus4 = "claude-opus-4-20250514"
 )
 
 // Google 模型
 const (
+	// Gemini25Pro 是Google的Gemini 2.5 Pro模型，支持思考
+	Gemini25Pro = "gemini-2.5-pro"
+	// Gemini25Flash 是Google的Gemini 2.5 Flash模型，支持思考
+	Gemini25Flash = "gemini-2.5-flash"
+	// Gemini20Flash 是Google的Gemini 2.0 Flash模型
+	Gemini20Flash = "gemini-2.0-flash"
+	// Gemini15Pro 是Google的Gemini 1.5 Pro模型
+	Gemini15Pro = "gemini-1.5-pro"
+
 	// GeminiPro 是Google的Gemini Pro模型
+	//
+	// Deprecated: 该模型已下线，请使用Gemini25Pro或Gemini25Flash。
 	GeminiPro = "gemini-pro"
 	// GeminiProVision 是Google的Gemini Pro Vision模型
+	//
+	// Deprecated: 该模型已下线，Gemini 1.5及以后的模型均支持图像输入。
 	GeminiProVision = "gemini-pro-vision"
 	// GeminiUltra 是Google的Gemini Ultra模型
+	//
+	// Deprecated: 该模型未在API中开放，请使用Gemini25Pro。
 	GeminiUltra = "gemini-ultra"
 )
 
@@ -56,6 +87,8 @@ const (
 	DeepSeekCoder = "deepseek-coder"
 	// DeepSeekChat 是DeepSeek的通用聊天模型
 	DeepSeekChat = "deepseek-chat"
+	// DeepSeekReasoner 是DeepSeek的推理模型，会返回独立的思维链内容
+	DeepSeekReasoner = "deepseek-reasoner"
 	// DeepSeekLlama270B 是DeepSeek的70B大模型
 	DeepSeekLlama270B = "deepseek-llama-70b"
 	// DeepSeekLlama7B 是DeepSeek的7B模型
@@ -66,14 +99,153 @@ const (
 	DeepSeekEmbedding = "deepseek-embedding"
 )
 
+// Mistral 模型
+const (
+	// MistralLarge 是Mistral的旗舰模型
+	MistralLarge = "mistral-large-latest"
+	// MistralMedium 是Mistral的中型模型
+	MistralMedium = "mistral-medium-latest"
+	// MistralSmall 是Mistral的轻量级模型
+	MistralSmall = "mistral-small-latest"
+	// MistralNemo 是Mistral的开源Nemo模型
+	MistralNemo = "open-mistral-nemo"
+	// Codestral 是Mistral的代码模型，支持FIM补全
+	Codestral = "codestral-latest"
+	// MistralEmbed 是Mistral的嵌入模型
+	MistralEmbed = "mistral-embed"
+)
+
+// Cohere 模型
+const (
+	// CommandA 是Cohere的旗舰Command A模型
+	CommandA = "command-a-03-2025"
+	// CommandRPlus 是Cohere的Command R+模型
+	CommandRPlus = "command-r-plus-08-2024"
+	// CommandR 是Cohere的Command R模型
+	CommandR = "command-r-08-2024"
+	// EmbedMultilingualV3 是Cohere的多语言嵌入模型
+	EmbedMultilingualV3 = "embed-multilingual-v3.0"
+	// EmbedEnglishV3 是Cohere的英文嵌入模型
+	EmbedEnglishV3 = "embed-english-v3.0"
+	// RerankV35 是Cohere的多语言重排序模型
+	RerankV35 = "rerank-v3.5"
+	// RerankMultilingualV3 是Cohere的多语言重排序模型（v3）
+	RerankMultilingualV3 = "rerank-multilingual-v3.0"
+)
+
+// 智谱 GLM 模型
+const (
+	// GLM4Plus 是智谱的GLM-4-Plus模型
+	GLM4Plus = "glm-4-plus"
+	// GLM4Air 是智谱的高性价比GLM-4-Air模型
+	GLM4Air = "glm-4-air"
+	// GLM4Flash 是智谱的免费GLM-4-Flash模型
+	GLM4Flash = "glm-4-flash"
+	// GLM4V 是智谱的视觉模型
+	GLM4V = "glm-4v-plus"
+	// ZhipuEmbedding3 是智谱的嵌入模型
+	ZhipuEmbedding3 = "embedding-3"
+)
+
+// 通义千问 模型
+const (
+	// QwenMax 是通义千问的旗舰模型
+	QwenMax = "qwen-max"
+	// QwenPlus 是通义千问的均衡模型
+	QwenPlus = "qwen-plus"
+	// QwenTurbo 是通义千问的快速模型
+	QwenTurbo = "qwen-turbo"
+	// QwenLong 是通义千问的长文本模型
+	QwenLong = "qwen-long"
+	// QwenTextEmbeddingV3 是通义的嵌入模型
+	QwenTextEmbeddingV3 = "text-embedding-v3"
+)
+
+// Moonshot 模型
+const (
+	// MoonshotV18K 是Moonshot的8K上下文模型
+	MoonshotV18K = "moonshot-v1-8k"
+	// MoonshotV132K 是Moonshot的32K上下文模型
+	MoonshotV132K = "moonshot-v1-32k"
+	// MoonshotV1128K 是Moonshot的128K上下文模型
+	MoonshotV1128K = "moonshot-v1-128k"
+)
+
+// 豆包 模型，实际调用时需映射为方舟推理接入点ID
+const (
+	// DoubaoPro32K 是豆包的Pro 32K模型
+	DoubaoPro32K = "doubao-1-5-pro-32k"
+	// DoubaoPro256K 是豆包的Pro 256K模型
+	DoubaoPro256K = "doubao-1-5-pro-256k"
+	// DoubaoLite32K 是豆包的Lite 32K模型
+	DoubaoLite32K = "doubao-1-5-lite-32k"
+	// DoubaoEmbedding 是豆包的嵌入模型
+	DoubaoEmbedding = "doubao-embedding"
+)
+
+// 计价币种
+const (
+	CurrencyUSD = "USD"
+	CurrencyCNY = "CNY"
+)
+
 // ModelInfo 存储模型相关信息
 type ModelInfo struct {
-	ID           string
-	Provider     string
-	MaxTokens    int
-	InputPrice   float64 // 每1000个输入token的价格（美元）
-	OutputPrice  float64 // 每1000个输出token的价格（美元）
-	Capabilities []string
+	ID              string
+	DisplayName     string // 便于阅读的名称，通常来自提供商的模型列表接口
+	Provider        string
+	MaxTokens       int     // 上下文窗口的token数
+	MaxOutputTokens int     // 单次输出的最大token数，为0时表示未知
+	InputPrice      float64 // 每1000个输入token的价格（币种见Currency）
+	OutputPrice     float64 // 每1000个输出token的价格（币种见Currency）
+	Currency        string  // 计价币种，为空时表示美元
+	Capabilities    []string
+
+	CacheReadPrice  float64 // 每1000个命中缓存的输入token的价格，为0时按InputPrice计算
+	CacheWritePrice float64 // 每1000个写入缓存的输入token的价格，为0时按InputPrice计算
+
+	// Discovered 为true表示模型出现在提供商的模型列表接口中，见Registry.Discover
+	Discovered bool
+}
+
+// HasCapability 判断模型是否具有指定能力
+func (m *ModelInfo) HasCapability(capability string) bool {
+	for _, c := range m.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// PriceCurrency 返回模型的计价币种
+func (m *ModelInfo) PriceCurrency() string {
+	if m.Currency == "" {
+		return CurrencyUSD
+	}
+	return m.Currency
+}
+
+// EstimateCost 根据token用量估算费用，币种见PriceCurrency
+func (m *ModelInfo) EstimateCost(promptTokens, completionTokens int) float64 {
+	return float64(promptTokens)/1000*m.InputPrice + float64(completionTokens)/1000*m.OutputPrice
+}
+
+// EstimateUsageCost 根据响应中的用量估算费用，命中和写入缓存的token按缓存价格计算
+func (m *ModelInfo) EstimateUsageCost(usage api.Usage) float64 {
+	readPrice := m.CacheReadPrice
+	if readPrice == 0 {
+		readPrice = m.InputPrice
+	}
+	writePrice := m.CacheWritePrice
+	if writePrice == 0 {
+		writePrice = m.InputPrice
+	}
+
+	uncached := usage.PromptTokens - usage.CacheReadTokens - usage.CacheCreationTokens
+	return m.EstimateCost(uncached, usage.CompletionTokens) +
+		float64(usage.CacheReadTokens)/1000*readPrice +
+		float64(usage.CacheCreationTokens)/1000*writePrice
 }
 
 // 模型能力常量
@@ -83,18 +255,14 @@ const (
 	CapabilityFunction  = "function"
 	CapabilityEmbedding = "embedding"
 	CapabilityCoding    = "coding"
+	CapabilityJSONMode  = "json_mode"
+	CapabilityFIM       = "fim"
+	CapabilityRerank    = "rerank"
+	CapabilityThinking  = "thinking"
 )
 
-// GetModelInfo 返回指定模型的信息
-func GetModelInfo(modelID string) *ModelInfo {
-	if info, ok := modelRegistry[modelID]; ok {
-		return &info
-	}
-	return nil
-}
-
-// 模型注册表
-var modelRegistry = map[string]ModelInfo{
+// 内置的模型信息，是每个Registry的初始内容
+var builtinModels = map[string]ModelInfo{
 	GPT4: {
 		ID:           GPT4,
 		Provider:     "openai",
@@ -120,44 +288,100 @@ var modelRegistry = map[string]ModelInfo{
 		Capabilities: []string{CapabilityChat, CapabilityFunction},
 	},
 	Claude3Opus: {
-		ID:           Claude3Opus,
-		Provider:     "anthropic",
-		MaxTokens:    200000,
-		InputPrice:   0.015,
-		OutputPrice:  0.075,
-		Capabilities: []string{CapabilityChat, CapabilityVision},
+		ID:              Claude3Opus,
+		Provider:        "anthropic",
+		MaxTokens:       200000,
+		InputPrice:      0.015,
+		OutputPrice:     0.075,
+		Capabilities:    []string{CapabilityChat, CapabilityVision},
+		CacheReadPrice:  0.0015,
+		CacheWritePrice: 0.01875,
 	},
 	Claude3Sonnet: {
-		ID:           Claude3Sonnet,
-		Provider:     "anthropic",
-		MaxTokens:    200000,
-		InputPrice:   0.003,
-		OutputPrice:  0.015,
-		Capabilities: []string{CapabilityChat, CapabilityVision},
+		ID:              Claude3Sonnet,
+		Provider:        "anthropic",
+		MaxTokens:       200000,
+		InputPrice:      0.003,
+		OutputPrice:     0.015,
+		Capabilities:    []string{CapabilityChat, CapabilityVision},
+		CacheReadPrice:  0.0003,
+		CacheWritePrice: 0.00375,
 	},
 	Claude3Haiku: {
-		ID:           Claude3Haiku,
-		Provider:     "anthropic",
-		MaxTokens:    200000,
-		InputPrice:   0.00025,
-		OutputPrice:  0.00125,
-		Capabilities: []string{CapabilityChat, CapabilityVision},
+		ID:              Claude3Haiku,
+		Provider:        "anthropic",
+		MaxTokens:       200000,
+		InputPrice:      0.00025,
+		OutputPrice:     0.00125,
+		Capabilities:    []string{CapabilityChat, CapabilityVision},
+		CacheReadPrice:  0.00003,
+		CacheWritePrice: 0.0003,
 	},
-	GeminiPro: {
-		ID:           GeminiPro,
-		Provider:     "google",
-		MaxTokens:    32768,
-		InputPrice:   0.00125,
-		OutputPrice:  0.00125,
-		Capabilities: []string{CapabilityChat, CapabilityFunction},
+	Claude37Sonnet: {
+		ID:              Claude37Sonnet,
+		Provider:        "anthropic",
+		MaxTokens:       200000,
+		InputPrice:      0.003,
+		OutputPrice:     0.015,
+		Capabilities:    []string{CapabilityChat, CapabilityVision, CapabilityFunction, CapabilityThinking},
+		CacheReadPrice:  0.0003,
+		CacheWritePrice: 0.00375,
 	},
-	GeminiUltra: {
-		ID:           GeminiUltra,
-		Provider:     "google",
-		MaxTokens:    32768,
-		InputPrice:   0.00375,
-		OutputPrice:  0.01125,
-		Capabilities: []string{CapabilityChat, CapabilityVision, CapabilityFunction},
+	ClaudeSonnet4: {
+		ID:              ClaudeSonnet4,
+		Provider:        "anthropic",
+		MaxTokens:       200000,
+		InputPrice:      0.003,
+		OutputPrice:     0.015,
+		Capabilities:    []string{CapabilityChat, CapabilityVision, CapabilityFunction, CapabilityThinking},
+		CacheReadPrice:  0.0003,
+		CacheWritePrice: 0.00375,
+	},
+	ClaudeOpus4: {
+		ID:              ClaudeOpus4,
+		Provider:        "anthropic",
+		MaxTokens:       200000,
+		InputPrice:      0.015,
+		OutputPrice:     0.075,
+		Capabilities:    []string{CapabilityChat, CapabilityVision, CapabilityFunction, CapabilityThinking},
+		CacheReadPrice:  0.0015,
+		CacheWritePrice: 0.01875,
+	},
+	Gemini25Pro: {
+		ID:              Gemini25Pro,
+		Provider:        "google",
+		MaxTokens:       1048576,
+		MaxOutputTokens: 65536,
+		InputPrice:      0.00125,
+		OutputPrice:     0.01,
+		Capabilities:    []string{CapabilityChat, CapabilityVision, CapabilityFunction, CapabilityJSONMode, CapabilityThinking},
+	},
+	Gemini25Flash: {
+		ID:              Gemini25Flash,
+		Provider:        "google",
+		MaxTokens:       1048576,
+		MaxOutputTokens: 65536,
+		InputPrice:      0.0003,
+		OutputPrice:     0.0025,
+		Capabilities:    []string{CapabilityChat, CapabilityVision, CapabilityFunction, CapabilityJSONMode, CapabilityThinking},
+	},
+	Gemini20Flash: {
+		ID:              Gemini20Flash,
+		Provider:        "google",
+		MaxTokens:       1048576,
+		MaxOutputTokens: 8192,
+		InputPrice:      0.0001,
+		OutputPrice:     0.0004,
+		Capabilities:    []string{CapabilityChat, CapabilityVision, CapabilityFunction, CapabilityJSONMode},
+	},
+	Gemini15Pro: {
+		ID:              Gemini15Pro,
+		Provider:        "google",
+		MaxTokens:       2097152,
+		MaxOutputTokens: 8192,
+		InputPrice:      0.00125,
+		OutputPrice:     0.005,
+		Capabilities:    []string{CapabilityChat, CapabilityVision, CapabilityFunction, CapabilityJSONMode},
 	},
 	DeepSeekCoder: {
 		ID:           DeepSeekCoder,
@@ -175,6 +399,15 @@ var modelRegistry = map[string]ModelInfo{
 		OutputPrice:  0.002,
 		Capabilities: []string{CapabilityChat},
 	},
+	DeepSeekReasoner: {
+		ID:             DeepSeekReasoner,
+		Provider:       "deepseek",
+		MaxTokens:      64000,
+		InputPrice:     0.00055,
+		OutputPrice:    0.00219,
+		Capabilities:   []string{CapabilityChat, CapabilityThinking},
+		CacheReadPrice: 0.00014,
+	},
 	DeepSeekLlama270B: {
 		ID:           DeepSeekLlama270B,
 		Provider:     "deepseek",
@@ -191,4 +424,258 @@ var modelRegistry = map[string]ModelInfo{
 		OutputPrice:  0,
 		Capabilities: []string{CapabilityEmbedding},
 	},
+	MistralLarge: {
+		ID:           MistralLarge,
+		Provider:     "mistral",
+		MaxTokens:    128000,
+		InputPrice:   0.002,
+		OutputPrice:  0.006,
+		Capabilities: []string{CapabilityChat, CapabilityFunction, CapabilityJSONMode},
+	},
+	MistralMedium: {
+		ID:           MistralMedium,
+		Provider:     "mistral",
+		MaxTokens:    128000,
+		InputPrice:   0.0004,
+		OutputPrice:  0.002,
+		Capabilities: []string{CapabilityChat, CapabilityVision, CapabilityFunction, CapabilityJSONMode},
+	},
+	MistralSmall: {
+		ID:           MistralSmall,
+		Provider:     "mistral",
+		MaxTokens:    32000,
+		InputPrice:   0.0002,
+		OutputPrice:  0.0006,
+		Capabilities: []string{CapabilityChat, CapabilityFunction, CapabilityJSONMode},
+	},
+	MistralNemo: {
+		ID:           MistralNemo,
+		Provider:     "mistral",
+		MaxTokens:    128000,
+		InputPrice:   0.00015,
+		OutputPrice:  0.00015,
+		Capabilities: []string{CapabilityChat, CapabilityFunction, CapabilityJSONMode},
+	},
+	Codestral: {
+		ID:           Codestral,
+		Provider:     "mistral",
+		MaxTokens:    256000,
+		InputPrice:   0.0003,
+		OutputPrice:  0.0009,
+		Capabilities: []string{CapabilityChat, CapabilityCoding, CapabilityFIM, CapabilityFunction, CapabilityJSONMode},
+	},
+	MistralEmbed: {
+		ID:           MistralEmbed,
+		Provider:     "mistral",
+		MaxTokens:    8192,
+		InputPrice:   0.0001,
+		OutputPrice:  0,
+		Capabilities: []string{CapabilityEmbedding},
+	},
+	CommandA: {
+		ID:           CommandA,
+		Provider:     "cohere",
+		MaxTokens:    256000,
+		InputPrice:   0.0025,
+		OutputPrice:  0.01,
+		Capabilities: []string{CapabilityChat, CapabilityFunction, CapabilityJSONMode},
+	},
+	CommandRPlus: {
+		ID:           CommandRPlus,
+		Provider:     "cohere",
+		MaxTokens:    128000,
+		InputPrice:   0.0025,
+		OutputPrice:  0.01,
+		Capabilities: []string{CapabilityChat, CapabilityFunction, CapabilityJSONMode},
+	},
+	CommandR: {
+		ID:           CommandR,
+		Provider:     "cohere",
+		MaxTokens:    128000,
+		InputPrice:   0.00015,
+		OutputPrice:  0.0006,
+		Capabilities: []string{CapabilityChat, CapabilityFunction, CapabilityJSONMode},
+	},
+	EmbedMultilingualV3: {
+		ID:           EmbedMultilingualV3,
+		Provider:     "cohere",
+		MaxTokens:    512,
+		InputPrice:   0.0001,
+		OutputPrice:  0,
+		Capabilities: []string{CapabilityEmbedding},
+	},
+	EmbedEnglishV3: {
+		ID:           EmbedEnglishV3,
+		Provider:     "cohere",
+		MaxTokens:    512,
+		InputPrice:   0.0001,
+		OutputPrice:  0,
+		Capabilities: []string{CapabilityEmbedding},
+	},
+	// 重排序按搜索次数计费（每千次约2美元），不按token计价
+	RerankV35: {
+		ID:           RerankV35,
+		Provider:     "cohere",
+		MaxTokens:    4096,
+		Capabilities: []string{CapabilityRerank},
+	},
+	RerankMultilingualV3: {
+		ID:           RerankMultilingualV3,
+		Provider:     "cohere",
+		MaxTokens:    4096,
+		Capabilities: []string{CapabilityRerank},
+	},
+	GLM4Plus: {
+		ID:           GLM4Plus,
+		Provider:     "zhipu",
+		MaxTokens:    128000,
+		InputPrice:   0.005,
+		OutputPrice:  0.005,
+		Currency:     CurrencyCNY,
+		Capabilities: []string{CapabilityChat, CapabilityFunction, CapabilityJSONMode},
+	},
+	GLM4Air: {
+		ID:           GLM4Air,
+		Provider:     "zhipu",
+		MaxTokens:    128000,
+		InputPrice:   0.0005,
+		OutputPrice:  0.0005,
+		Currency:     CurrencyCNY,
+		Capabilities: []string{CapabilityChat, CapabilityFunction, CapabilityJSONMode},
+	},
+	GLM4Flash: {
+		ID:           GLM4Flash,
+		Provider:     "zhipu",
+		MaxTokens:    128000,
+		InputPrice:   0,
+		OutputPrice:  0,
+		Currency:     CurrencyCNY,
+		Capabilities: []string{CapabilityChat, CapabilityFunction},
+	},
+	GLM4V: {
+		ID:           GLM4V,
+		Provider:     "zhipu",
+		MaxTokens:    8192,
+		InputPrice:   0.004,
+		OutputPrice:  0.004,
+		Currency:     CurrencyCNY,
+		Capabilities: []string{CapabilityChat, CapabilityVision},
+	},
+	ZhipuEmbedding3: {
+		ID:           ZhipuEmbedding3,
+		Provider:     "zhipu",
+		MaxTokens:    8192,
+		InputPrice:   0.0005,
+		OutputPrice:  0,
+		Currency:     CurrencyCNY,
+		Capabilities: []string{CapabilityEmbedding},
+	},
+	QwenMax: {
+		ID:           QwenMax,
+		Provider:     "qwen",
+		MaxTokens:    32768,
+		InputPrice:   0.0024,
+		OutputPrice:  0.0096,
+		Currency:     CurrencyCNY,
+		Capabilities: []string{CapabilityChat, CapabilityFunction, CapabilityJSONMode},
+	},
+	QwenPlus: {
+		ID:           QwenPlus,
+		Provider:     "qwen",
+		MaxTokens:    131072,
+		InputPrice:   0.0008,
+		OutputPrice:  0.002,
+		Currency:     CurrencyCNY,
+		Capabilities: []string{CapabilityChat, CapabilityFunction, CapabilityJSONMode},
+	},
+	QwenTurbo: {
+		ID:           QwenTurbo,
+		Provider:     "qwen",
+		MaxTokens:    1000000,
+		InputPrice:   0.0003,
+		OutputPrice:  0.0006,
+		Currency:     CurrencyCNY,
+		Capabilities: []string{CapabilityChat, CapabilityFunction, CapabilityJSONMode},
+	},
+	QwenLong: {
+		ID:           QwenLong,
+		Provider:     "qwen",
+		MaxTokens:    10000000,
+		InputPrice:   0.0005,
+		OutputPrice:  0.002,
+		Currency:     CurrencyCNY,
+		Capabilities: []string{CapabilityChat},
+	},
+	QwenTextEmbeddingV3: {
+		ID:           QwenTextEmbeddingV3,
+		Provider:     "qwen",
+		MaxTokens:    8192,
+		InputPrice:   0.0005,
+		OutputPrice:  0,
+		Currency:     CurrencyCNY,
+		Capabilities: []string{CapabilityEmbedding},
+	},
+	MoonshotV18K: {
+		ID:           MoonshotV18K,
+		Provider:     "moonshot",
+		MaxTokens:    8192,
+		InputPrice:   0.012,
+		OutputPrice:  0.012,
+		Currency:     CurrencyCNY,
+		Capabilities: []string{CapabilityChat, CapabilityFunction, CapabilityJSONMode},
+	},
+	MoonshotV132K: {
+		ID:           MoonshotV132K,
+		Provider:     "moonshot",
+		MaxTokens:    32768,
+		InputPrice:   0.024,
+		OutputPrice:  0.024,
+		Currency:     CurrencyCNY,
+		Capabilities: []string{CapabilityChat, CapabilityFunction, CapabilityJSONMode},
+	},
+	MoonshotV1128K: {
+		ID:           MoonshotV1128K,
+		Provider:     "moonshot",
+		MaxTokens:    131072,
+		InputPrice:   0.06,
+		OutputPrice:  0.06,
+		Currency:     CurrencyCNY,
+		Capabilities: []string{CapabilityChat, CapabilityFunction, CapabilityJSONMode},
+	},
+	DoubaoPro32K: {
+		ID:           DoubaoPro32K,
+		Provider:     "doubao",
+		MaxTokens:    32768,
+		InputPrice:   0.0008,
+		OutputPrice:  0.002,
+		Currency:     CurrencyCNY,
+		Capabilities: []string{CapabilityChat, CapabilityFunction},
+	},
+	DoubaoPro256K: {
+		ID:           DoubaoPro256K,
+		Provider:     "doubao",
+		MaxTokens:    262144,
+		InputPrice:   0.005,
+		OutputPrice:  0.009,
+		Currency:     CurrencyCNY,
+		Capabilities: []string{CapabilityChat, CapabilityFunction},
+	},
+	DoubaoLite32K: {
+		ID:           DoubaoLite32K,
+		Provider:     "doubao",
+		MaxTokens:    32768,
+		InputPrice:   0.0003,
+		OutputPrice:  0.0006,
+		Currency:     CurrencyCNY,
+		Capabilities: []string{CapabilityChat, CapabilityFunction},
+	},
+	DoubaoEmbedding: {
+		ID:           DoubaoEmbedding,
+		Provider:     "doubao",
+		MaxTokens:    4096,
+		InputPrice:   0.0005,
+		OutputPrice:  0,
+		Currency:     CurrencyCNY,
+		Capabilities: []string{CapabilityEmbedding},
+	},
 }
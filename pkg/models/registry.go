@@ -0,0 +1,198 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// Registry 是并发安全的模型注册表
+//
+// 注册表以内置的模型信息为初始内容，可以手动注册模型，也可以通过Discover合并提供商模型列表接口返回的模型。
+// 提供商名称与ModelInfo.Provider一致，Gemini的模型使用"google"。
+type Registry struct {
+	mu     sync.RWMutex
+	models map[string]ModelInfo
+}
+
+// NewRegistry 创建包含内置模型信息的注册表
+func NewRegistry() *Registry {
+	registry := &Registry{models: make(map[string]ModelInfo, len(builtinModels))}
+	for id, info := range builtinModels {
+		registry.models[id] = cloneModelInfo(info)
+	}
+	return registry
+}
+
+// defaultRegistry 是包级函数使用的注册表
+var defaultRegistry = NewRegistry()
+
+// Default 返回包级函数使用的默认注册表
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// Get 返回指定模型的信息，模型不存在时返回nil
+func (r *Registry) Get(modelID string) *ModelInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if info, ok := r.models[modelID]; ok {
+		info = cloneModelInfo(info)
+		return &info
+	}
+	return nil
+}
+
+// Models 返回指定提供商的所有模型，按ID排序；provider为空时返回全部模型
+func (r *Registry) Models(provider string) []ModelInfo {
+	r.mu.RLock()
+	var result []ModelInfo
+	for _, info := range r.models {
+		if provider == "" || info.Provider == provider {
+			result = append(result, cloneModelInfo(info))
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
+
+// Register 添加或替换模型信息
+func (r *Registry) Register(info ModelInfo) error {
+	if info.ID == "" {
+		return api.NewError(api.ErrorTypeInvalidRequest, "模型ID不能为空", 0, nil)
+	}
+	if info.Provider == "" {
+		return api.NewError(api.ErrorTypeInvalidRequest, "模型提供商不能为空", 0, nil)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.models[info.ID] = cloneModelInfo(info)
+	return nil
+}
+
+// Remove 删除模型信息，返回模型是否存在
+func (r *Registry) Remove(modelID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.models[modelID]; !ok {
+		return false
+	}
+	delete(r.models, modelID)
+	return true
+}
+
+// Merge 将提供商模型列表接口返回的模型合并到注册表中，返回新增的模型数
+//
+// 已有的模型保留本地的价格和能力（本地没有能力信息时使用接口返回的能力），
+// 名称和token限制以接口返回的非零值为准；新的模型只有接口返回的信息，没有价格。
+// 合并的模型都会标记为Discovered。ID已属于其他提供商的模型会被跳过。
+func (r *Registry) Merge(provider string, list []api.Model) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	added := 0
+	for _, model := range list {
+		if model.ID == "" {
+			continue
+		}
+
+		info, ok := r.models[model.ID]
+		if ok && info.Provider != provider {
+			continue
+		}
+		if !ok {
+			info = ModelInfo{ID: model.ID, Provider: provider}
+			added++
+		}
+
+		if model.DisplayName != "" {
+			info.DisplayName = model.DisplayName
+		}
+		if model.InputTokenLimit > 0 {
+			info.MaxTokens = model.InputTokenLimit
+		}
+		if model.OutputTokenLimit > 0 {
+			info.MaxOutputTokens = model.OutputTokenLimit
+		}
+		if len(info.Capabilities) == 0 && len(model.Capabilities) > 0 {
+			info.Capabilities = append([]string(nil), model.Capabilities...)
+		}
+		info.Discovered = true
+		r.models[model.ID] = info
+	}
+	return added
+}
+
+// Discover 通过lister查询提供商当前可用的模型并合并到注册表中，返回新增的模型数
+func (r *Registry) Discover(ctx context.Context, provider string, lister api.ModelLister) (int, error) {
+	list, err := lister.ListModels(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return r.Merge(provider, list), nil
+}
+
+// CheckModel 检查模型能否用于provider的对话请求
+//
+// 注册表中的模型必须属于provider，记录了能力时还必须具有对话能力；
+// 注册表中没有的模型视为有效，以便使用提供商新发布的模型。
+func (r *Registry) CheckModel(provider, modelID string) error {
+	if modelID == "" {
+		return api.NewError(api.ErrorTypeInvalidRequest, "模型不能为空", 0, nil)
+	}
+
+	info := r.Get(modelID)
+	if info == nil {
+		return nil
+	}
+	if info.Provider != provider {
+		return api.NewError(api.ErrorTypeInvalidRequest, fmt.Sprintf("模型%s属于%s，不能用于%s", modelID, info.Provider, provider), 0, nil)
+	}
+	if len(info.Capabilities) > 0 && !info.HasCapability(CapabilityChat) {
+		return api.NewError(api.ErrorTypeInvalidRequest, fmt.Sprintf("模型%s不支持对话", modelID), 0, nil)
+	}
+	return nil
+}
+
+// 复制模型信息，避免调用方修改注册表中的能力切片
+func cloneModelInfo(info ModelInfo) ModelInfo {
+	if info.Capabilities != nil {
+		info.Capabilities = append([]string(nil), info.Capabilities...)
+	}
+	return info
+}
+
+// GetModelInfo 返回默认注册表中指定模型的信息
+func GetModelInfo(modelID string) *ModelInfo {
+	return defaultRegistry.Get(modelID)
+}
+
+// ProviderModels 返回默认注册表中指定提供商的所有模型，按ID排序；provider为空时返回全部模型
+func ProviderModels(provider string) []ModelInfo {
+	return defaultRegistry.Models(provider)
+}
+
+// Register 在默认注册表中添加或替换模型信息
+func Register(info ModelInfo) error {
+	return defaultRegistry.Register(info)
+}
+
+// Discover 查询提供商当前可用的模型并合并到默认注册表中，返回新增的模型数
+func Discover(ctx context.Context, provider string, lister api.ModelLister) (int, error) {
+	return defaultRegistry.Discover(ctx, provider, lister)
+}
+
+// CheckModel 使用默认注册表检查模型能否用于provider的对话请求
+func CheckModel(provider, modelID string) error {
+	return defaultRegistry.CheckModel(provider, modelID)
+}
@@ -0,0 +1,471 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/utils"
+)
+
+// 批处理任务的处理状态
+const (
+	BatchStatusInProgress = "in_progress"
+	BatchStatusCanceling  = "canceling"
+	BatchStatusEnded      = "ended"
+)
+
+// 批处理中单个请求的结果类型
+const (
+	BatchResultSucceeded = "succeeded"
+	BatchResultErrored   = "errored"
+	BatchResultCanceled  = "canceled"
+	BatchResultExpired   = "expired"
+)
+
+// BatchRequest 定义批处理中的单个请求
+type BatchRequest struct {
+	// CustomID 用于在结果中定位该请求，同一批次内必须唯一；为空时按序号生成
+	CustomID string
+	Request  *api.Request
+}
+
+// MessageBatch 定义Anthropic的消息批处理任务
+type MessageBatch struct {
+	ID                string             `json:"id"`
+	Type              string             `json:"type"`
+	ProcessingStatus  string             `json:"processing_status"`
+	RequestCounts     BatchRequestCounts `json:"request_counts"`
+	CreatedAt         time.Time          `json:"created_at"`
+	ExpiresAt         time.Time          `json:"expires_at"`
+	EndedAt           *time.Time         `json:"ended_at,omitempty"`
+	CancelInitiatedAt *time.Time         `json:"cancel_initiated_at,omitempty"`
+	ArchivedAt        *time.Time         `json:"archived_at,omitempty"`
+	// ResultsURL 批次结束后才会返回
+	ResultsURL string `json:"results_url,omitempty"`
+
+	// Warnings 提交时被忽略的参数，每条以请求的CustomID开头，只有CreateBatch返回的任务包含
+	Warnings []string `json:"-"`
+}
+
+// BatchRequestCounts 定义批处理中各状态的请求数量
+type BatchRequestCounts struct {
+	Processing int `json:"processing"`
+	Succeeded  int `json:"succeeded"`
+	Errored    int `json:"errored"`
+	Canceled   int `json:"canceled"`
+	Expired    int `json:"expired"`
+}
+
+// BatchListOptions 定义列出批处理任务时的分页参数
+type BatchListOptions struct {
+	// Limit 每页数量，为0时使用服务端默认值
+	Limit    int
+	BeforeID string
+	AfterID  string
+}
+
+// BatchList 定义批处理任务列表
+type BatchList struct {
+	Data    []MessageBatch `json:"data"`
+	HasMore bool           `json:"has_more"`
+	FirstID string         `json:"first_id"`
+	LastID  string         `json:"last_id"`
+}
+
+// BatchResult 定义批处理中单个请求的结果
+type BatchResult struct {
+	CustomID string
+	// Type 为BatchResultSucceeded、BatchResultErrored、BatchResultCanceled或BatchResultExpired
+	Type string
+	// Response 仅在Type为succeeded时有值，与Complete返回的结构一致
+	Response *api.Response
+	// Error 仅在Type为errored时有值
+	Error *api.Error
+}
+
+// batchSupportedParams 批处理支持的可选参数，与Complete相比不支持n：批处理中无法通过并行请求模拟多个候选
+var batchSupportedParams = func() api.ParamSet {
+	set := api.NewParamSet()
+	for param := range supportedParams {
+		if param != api.ParamN {
+			set[param] = true
+		}
+	}
+	return set
+}()
+
+// CreateBatch 提交一批消息请求，返回新建的批处理任务
+//
+// 每个请求与Complete一样检查参数：严格模式下包含不支持的参数时返回错误，否则忽略这些参数并在返回任务的Warnings中说明。
+func (c *Client) CreateBatch(ctx context.Context, requests []BatchRequest) (*MessageBatch, error) {
+	if len(requests) == 0 {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "批处理请求不能为空", 0, nil)
+	}
+
+	items := make([]map[string]interface{}, 0, len(requests))
+	var warnings []string
+	for i, item := range requests {
+		if err := validateRequest(item.Request); err != nil {
+			return nil, err
+		}
+
+		customID := item.CustomID
+		if customID == "" {
+			customID = fmt.Sprintf("request-%d", i)
+		}
+
+		itemWarnings, err := api.CheckParams("Anthropic批处理", item.Request, batchSupportedParams, c.strictParams)
+		if err != nil {
+			return nil, err
+		}
+		for _, warning := range itemWarnings {
+			warnings = append(warnings, customID+": "+warning)
+		}
+
+		// 批处理不支持流式输出
+		params := adaptRequest(item.Request)
+		delete(params, "stream")
+
+		items = append(items, map[string]interface{}{
+			"custom_id": customID,
+			"params":    params,
+		})
+	}
+
+	var batch MessageBatch
+	if err := c.doJSON(ctx, http.MethodPost, "/v1/messages/batches", map[string]interface{}{"requests": items}, &batch); err != nil {
+		return nil, err
+	}
+	batch.Warnings = warnings
+	return &batch, nil
+}
+
+// GetBatch 查询批处理任务的状态
+func (c *Client) GetBatch(ctx context.Context, batchID string) (*MessageBatch, error) {
+	if batchID == "" {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "批处理ID不能为空", 0, nil)
+	}
+
+	var batch MessageBatch
+	if err := c.doJSON(ctx, http.MethodGet, "/v1/messages/batches/"+url.PathEscape(batchID), nil, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// ListBatches 列出批处理任务，按创建时间倒序
+func (c *Client) ListBatches(ctx context.Context, options *BatchListOptions) (*BatchList, error) {
+	path := "/v1/messages/batches"
+	if options != nil {
+		query := url.Values{}
+		if options.Limit > 0 {
+			query.Set("limit", strconv.Itoa(options.Limit))
+		}
+		if options.BeforeID != "" {
+			query.Set("before_id", options.BeforeID)
+		}
+		if options.AfterID != "" {
+			query.Set("after_id", options.AfterID)
+		}
+		if len(query) > 0 {
+			path += "?" + query.Encode()
+		}
+	}
+
+	var list BatchList
+	if err := c.doJSON(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// CancelBatch 取消批处理任务，已在处理中的请求仍可能完成
+func (c *Client) CancelBatch(ctx context.Context, batchID string) (*MessageBatch, error) {
+	if batchID == "" {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "批处理ID不能为空", 0, nil)
+	}
+
+	var batch MessageBatch
+	if err := c.doJSON(ctx, http.MethodPost, "/v1/messages/batches/"+url.PathEscape(batchID)+"/cancel", nil, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// GetBatchResults 获取已结束批处理任务的结果，结果以流的方式逐条读取
+//
+// 下载不限制总时长，两次收到数据的间隔超过StreamTimeouts.Idle时Next返回超时错误。
+// 调用方读取完毕后需要调用Close。
+func (c *Client) GetBatchResults(ctx context.Context, batchID string) (*BatchResultsReader, error) {
+	if batchID == "" {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "批处理ID不能为空", 0, nil)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/v1/messages/batches/"+url.PathEscape(batchID)+"/results", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// 结果文件可能很大，不限制总时长，只按StreamTimeouts限制连接和空闲时间
+	resp, err := utils.DoDownloadRequest(c.streamClient, req, c.streamTimeouts)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, parseErrorBody(body, resp.StatusCode)
+	}
+
+	return &BatchResultsReader{
+		decoder: json.NewDecoder(resp.Body),
+		body:    resp.Body,
+	}, nil
+}
+
+// BatchResultsReader 逐条读取批处理结果（JSONL格式）
+type BatchResultsReader struct {
+	decoder *json.Decoder
+	body    io.ReadCloser
+}
+
+// anthropicBatchResult 定义结果文件中的一行
+type anthropicBatchResult struct {
+	CustomID string `json:"custom_id"`
+	Result   struct {
+		Type    string             `json:"type"`
+		Message *AnthropicResponse `json:"message,omitempty"`
+		Error   *AnthropicError    `json:"error,omitempty"`
+	} `json:"result"`
+}
+
+// Next 读取下一条结果，全部读取完毕后返回io.EOF
+func (r *BatchResultsReader) Next() (*BatchResult, error) {
+	var line anthropicBatchResult
+	if err := r.decoder.Decode(&line); err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		// 下载超时等读取错误原样返回
+		var apiErr *api.Error
+		if errors.As(err, &apiErr) {
+			return nil, apiErr
+		}
+		return nil, api.NewError(api.ErrorTypeServer, "解析批处理结果失败", 0, err)
+	}
+
+	result := &BatchResult{
+		CustomID: line.CustomID,
+		Type:     line.Result.Type,
+	}
+	if line.Result.Message != nil {
+		result.Response = adaptResponse(line.Result.Message)
+	}
+	if line.Result.Error != nil {
+		result.Error = mapAnthropicError(line.Result.Error, 0)
+	}
+	return result, nil
+}
+
+// Close 关闭结果流
+func (r *BatchResultsReader) Close() error {
+	return r.body.Close()
+}
+
+// doJSON 发送请求并把成功的响应解析到out中
+func (c *Client) doJSON(ctx context.Context, method, path string, payload interface{}, out interface{}) error {
+	resp, err := c.doRequest(ctx, method, path, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// 读取响应
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return api.NewError(api.ErrorTypeServer, "读取响应失败", resp.StatusCode, err)
+	}
+
+	// 检查HTTP状态码
+	if resp.StatusCode != http.StatusOK {
+		return parseErrorBody(body, resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return api.NewError(api.ErrorTypeServer, "解析响应失败", resp.StatusCode, err)
+	}
+	return nil
+}
+
+// doRequest 构造并发送HTTP请求，payload为nil时不发送请求体
+func (c *Client) doRequest(ctx context.Context, method, path string, payload interface{}) (*http.Response, error) {
+	req, err := c.newRequest(ctx, method, path, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// 发送请求
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "HTTP请求失败", 0, err)
+	}
+	return resp, nil
+}
+
+// newRequest 构造带认证头的HTTP请求，payload为nil时不发送请求体
+func (c *Client) newRequest(ctx context.Context, method, path string, payload interface{}) (*http.Request, error) {
+	var reqBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, api.NewError(api.ErrorTypeInvalidRequest, "无法序列化请求", 0, err)
+		}
+		reqBody = bytes.NewBuffer(data)
+	}
+
+	// 创建HTTP请求
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "创建HTTP请求失败", 0, err)
+	}
+
+	// 设置请求头
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("X-Api-Key", c.apiKey)
+	req.Header.Set("Anthropic-Version", c.apiVersion)
+	return req, nil
+}
+
+// 解析错误响应体
+func parseErrorBody(body []byte, statusCode int) *api.Error {
+	var anthropicErr AnthropicError
+	if err := json.Unmarshal(body, &anthropicErr); err != nil {
+		return api.NewError(api.ErrorTypeServer, fmt.Sprintf("API错误(状态码: %d)", statusCode), statusCode, nil)
+	}
+	return mapAnthropicError(&anthropicErr, statusCode)
+}
+
+// BatchClient 返回基于Message Batches API的统一批处理客户端
+func (c *Client) BatchClient() api.BatchClient {
+	return &batchClient{client: c}
+}
+
+// batchClient 将Message Batches API适配为api.BatchClient
+type batchClient struct {
+	client *Client
+}
+
+// Submit 提交一批请求
+func (b *batchClient) Submit(ctx context.Context, requests []api.BatchRequest) (*api.BatchJob, error) {
+	items := make([]BatchRequest, len(requests))
+	for i, item := range requests {
+		items[i] = BatchRequest{CustomID: item.CustomID, Request: item.Request}
+	}
+
+	batch, err := b.client.CreateBatch(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+	return adaptBatchJob(batch), nil
+}
+
+// Status 查询任务状态
+func (b *batchClient) Status(ctx context.Context, jobID string) (*api.BatchJob, error) {
+	batch, err := b.client.GetBatch(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return adaptBatchJob(batch), nil
+}
+
+// Cancel 取消任务
+func (b *batchClient) Cancel(ctx context.Context, jobID string) error {
+	_, err := b.client.CancelBatch(ctx, jobID)
+	return err
+}
+
+// Results 读取已结束任务的结果
+func (b *batchClient) Results(ctx context.Context, jobID string) (api.BatchResultIterator, error) {
+	reader, err := b.client.GetBatchResults(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return &batchResultIterator{reader: reader}, nil
+}
+
+// batchResultIterator 将BatchResultsReader适配为api.BatchResultIterator
+type batchResultIterator struct {
+	reader *BatchResultsReader
+}
+
+// Next 读取下一条结果
+func (it *batchResultIterator) Next() (*api.BatchResult, error) {
+	result, err := it.reader.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	converted := &api.BatchResult{
+		CustomID: result.CustomID,
+		Response: result.Response,
+	}
+	switch {
+	case result.Error != nil:
+		converted.Error = result.Error
+	case result.Type == BatchResultCanceled:
+		converted.Error = api.NewError(api.ErrorTypeUnknown, "请求在执行前被取消", 0, nil)
+	case result.Type == BatchResultExpired:
+		converted.Error = api.NewError(api.ErrorTypeTimeout, "请求在批处理过期前未被执行", 0, nil)
+	}
+	return converted, nil
+}
+
+// Close 关闭结果流
+func (it *batchResultIterator) Close() error {
+	return it.reader.Close()
+}
+
+// 将MessageBatch转换为统一的任务结构
+func adaptBatchJob(batch *MessageBatch) *api.BatchJob {
+	counts := batch.RequestCounts
+	job := &api.BatchJob{
+		ID:        batch.ID,
+		RawStatus: batch.ProcessingStatus,
+		Total:     counts.Processing + counts.Succeeded + counts.Errored + counts.Canceled + counts.Expired,
+		Succeeded: counts.Succeeded,
+		Failed:    counts.Errored + counts.Canceled + counts.Expired,
+		CreatedAt: batch.CreatedAt,
+		Warnings:  batch.Warnings,
+	}
+	if batch.EndedAt != nil {
+		job.EndedAt = *batch.EndedAt
+	}
+
+	switch batch.ProcessingStatus {
+	case BatchStatusInProgress:
+		job.Status = api.BatchStatusInProgress
+	case BatchStatusCanceling:
+		job.Status = api.BatchStatusCancelling
+	case BatchStatusEnded:
+		if batch.CancelInitiatedAt != nil {
+			job.Status = api.BatchStatusCancelled
+		} else {
+			job.Status = api.BatchStatusCompleted
+		}
+	default:
+		job.Status = api.BatchStatusPending
+	}
+	return job
+}
@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/models"
 	"github.com/ojbkgo/llm-sdk/pkg/utils"
 )
 
@@ -19,7 +21,12 @@ type Client struct {
 	baseURL    string
 	httpClient *http.Client
 	maxRetries int
-	apiVersion string
+	// streamClient 用于流式请求，不设置总超时，由streamTimeouts分阶段控制
+	streamClient   *http.Client
+	streamTimeouts api.StreamTimeouts
+	// strictParams 为true时不支持的参数返回错误，否则忽略并在响应中附带警告
+	strictParams bool
+	apiVersion   string
 }
 
 // 默认配置
@@ -28,6 +35,13 @@ const (
 	defaultTimeout    = 60 * time.Second
 	defaultMaxRetries = 3
 	defaultAPIVersion = "2023-06-01"
+
+	// defaultMaxTokens 未指定MaxTokens时的默认输出上限，Anthropic要求必须传递max_tokens
+	defaultMaxTokens = 4096
+	// minThinkingBudget Anthropic允许的最小思考预算
+	minThinkingBudget = 1024
+	// minThinkingTopP 开启扩展思考时允许的最小TopP
+	minThinkingTopP = 0.95
 )
 
 // NewClient 创建一个新的Anthropic客户端
@@ -59,11 +73,14 @@ func NewClient(options ...api.ClientOption) (api.LLMClient, error) {
 	}
 
 	return &Client{
-		apiKey:     clientOptions.APIKey,
-		baseURL:    clientOptions.BaseURL,
-		httpClient: httpClient,
-		maxRetries: clientOptions.MaxRetries,
-		apiVersion: defaultAPIVersion,
+		apiKey:         clientOptions.APIKey,
+		baseURL:        clientOptions.BaseURL,
+		httpClient:     httpClient,
+		streamClient:   utils.NewStreamClient(httpClient),
+		streamTimeouts: clientOptions.StreamTimeouts.WithDefaults(time.Duration(clientOptions.Timeout) * time.Second),
+		strictParams:   clientOptions.StrictParams,
+		maxRetries:     clientOptions.MaxRetries,
+		apiVersion:     defaultAPIVersion,
 	}, nil
 }
 
@@ -74,6 +91,17 @@ func (c *Client) Complete(ctx context.Context, request *api.Request) (*api.Respo
 		return nil, err
 	}
 
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Anthropic不支持n参数，多个候选通过并行请求模拟
+	if request.N > 1 {
+		return utils.CompleteCandidates(ctx, request, c.Complete)
+	}
+
 	// 准备请求体
 	reqBody, err := json.Marshal(adaptRequest(request))
 	if err != nil {
@@ -119,7 +147,9 @@ func (c *Client) Complete(ctx context.Context, request *api.Request) (*api.Respo
 		return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", resp.StatusCode, err)
 	}
 
-	return adaptResponse(&anthropicResp), nil
+	response := adaptResponse(&anthropicResp)
+	response.Warnings = warnings
+	return response, nil
 }
 
 // CompleteStream 发送请求并获取流式响应
@@ -129,6 +159,17 @@ func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.
 		return nil, err
 	}
 
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Anthropic不支持n参数，多个候选通过并行请求模拟
+	if request.N > 1 {
+		return utils.StreamCandidates(ctx, request, c.CompleteStream)
+	}
+
 	// 设置流式标志
 	reqCopy := *request
 	reqCopy.Stream = true
@@ -152,9 +193,9 @@ func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.
 	req.Header.Set("Accept", "text/event-stream")
 
 	// 发送请求
-	resp, err := c.httpClient.Do(req)
+	resp, err := utils.DoStreamRequest(c.streamClient, req, c.streamTimeouts)
 	if err != nil {
-		return nil, api.NewError(api.ErrorTypeConnection, "HTTP请求失败", 0, err)
+		return nil, err
 	}
 
 	// 检查HTTP状态码
@@ -169,10 +210,16 @@ func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.
 		return nil, mapAnthropicError(&anthropicErr, resp.StatusCode)
 	}
 
-	return &anthropicResponseStream{
-		reader:    utils.NewSSEReader(resp.Body),
-		rawReader: resp.Body,
-	}, nil
+	stream := &anthropicResponseStream{
+		rawReader:   resp.Body,
+		blockTypes:  map[int]string{},
+		toolIndexes: map[int]int{},
+	}
+	// 流中途的错误（如overloaded_error）通过error事件下发
+	stream.StreamDecoder = utils.NewStreamDecoder(resp.Body, stream.decode, func(options *utils.StreamDecoderOptions) {
+		options.ParseError = parseStreamError
+	})
+	return utils.WithWarnings(stream, warnings), nil
 }
 
 // Embedding 获取文本的嵌入向量
@@ -193,22 +240,57 @@ func validateRequest(request *api.Request) error {
 		return api.NewError(api.ErrorTypeInvalidRequest, "消息不能为空", 0, nil)
 	}
 
-	// 验证是否为有效的Anthropic模型
-	validModels := map[string]bool{
-		"claude-3-opus":   true,
-		"claude-3-sonnet": true,
-		"claude-3-haiku":  true,
-		"claude-instant":  true,
-		"claude-2":        true,
+	// 使用模型注册表验证模型，注册表中没有的新模型也可以使用
+	if err := models.CheckModel("anthropic", request.Model); err != nil {
+		return err
 	}
 
-	if !validModels[request.Model] {
-		return api.NewError(api.ErrorTypeInvalidRequest, fmt.Sprintf("无效的Anthropic模型: %s", request.Model), 0, nil)
+	// 验证扩展思考配置
+	if request.Thinking != nil {
+		return validateThinking(request)
 	}
 
 	return nil
 }
 
+// validateThinking 验证扩展思考与其他采样参数的组合，Anthropic对这些组合直接返回400
+func validateThinking(request *api.Request) error {
+	if request.Thinking.BudgetTokens < minThinkingBudget {
+		return invalidParam(api.ParamThinking, fmt.Sprintf("思考预算不能小于%d个token", minThinkingBudget))
+	}
+	if request.MaxTokens != nil && *request.MaxTokens <= request.Thinking.BudgetTokens {
+		return invalidParam(api.ParamMaxTokens, "开启扩展思考时MaxTokens必须大于思考预算")
+	}
+	if request.Temperature != nil && *request.Temperature != 1 {
+		return invalidParam(api.ParamTemperature, "开启扩展思考时Temperature只能为1")
+	}
+	if request.TopK != nil {
+		return invalidParam(api.ParamTopK, "开启扩展思考时不能设置TopK")
+	}
+	if request.TopP != nil && (*request.TopP < minThinkingTopP || *request.TopP > 1) {
+		return invalidParam(api.ParamTopP, fmt.Sprintf("开启扩展思考时TopP必须在%.2f到1之间", minThinkingTopP))
+	}
+	return nil
+}
+
+// invalidParam 返回指明参数的请求错误
+func invalidParam(param api.Param, message string) *api.Error {
+	err := api.NewError(api.ErrorTypeInvalidRequest, message, 0, nil)
+	err.Param = string(param)
+	return err
+}
+
+// supportedParams Anthropic支持的可选参数
+var supportedParams = api.NewParamSet(
+	api.ParamTemperature, api.ParamTopP, api.ParamTopK, api.ParamMaxTokens, api.ParamStop, api.ParamN, api.ParamUser,
+	api.ParamTools, api.ParamToolChoice, api.ParamParallelToolCalls, api.ParamThinking,
+)
+
+// 检查请求中Anthropic不支持的参数，非严格模式下返回忽略这些参数的警告
+func (c *Client) checkParams(request *api.Request) ([]string, error) {
+	return api.CheckParams("Anthropic", request, supportedParams, c.strictParams)
+}
+
 // AnthropicResponse 定义Anthropic API的响应结构
 type AnthropicResponse struct {
 	ID           string         `json:"id"`
@@ -218,16 +300,33 @@ type AnthropicResponse struct {
 	Content      []ContentBlock `json:"content"`
 	StopReason   string         `json:"stop_reason"`
 	StopSequence string         `json:"stop_sequence"`
-	Usage        struct {
-		InputTokens  int `json:"input_tokens"`
-		OutputTokens int `json:"output_tokens"`
-	} `json:"usage"`
+	Usage        AnthropicUsage `json:"usage"`
+}
+
+// AnthropicUsage 定义Anthropic的令牌用量
+//
+// InputTokens 不包含写入和命中缓存的部分。
+type AnthropicUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
 }
 
 // ContentBlock 定义消息内容块
 type ContentBlock struct {
 	Type string `json:"type"`
 	Text string `json:"text,omitempty"`
+
+	// tool_use块
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// thinking和redacted_thinking块
+	Thinking  string `json:"thinking,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Data      string `json:"data,omitempty"`
 }
 
 // AnthropicError 定义Anthropic API的错误响应
@@ -241,31 +340,15 @@ type AnthropicError struct {
 
 // 将SDK的请求格式转换为Anthropic的格式
 func adaptRequest(request *api.Request) map[string]interface{} {
-	// 提取系统消息和用户消息
-	var systemPrompt string
-	var messages []map[string]interface{}
-
-	for _, msg := range request.Messages {
-		if msg.Role == api.RoleSystem {
-			systemPrompt = msg.Content
-		} else {
-			// 转换为Anthropic的消息格式
-			messages = append(messages, map[string]interface{}{
-				"role":    string(msg.Role),
-				"content": msg.Content,
-			})
-		}
-	}
-
 	// 构建请求
 	req := map[string]interface{}{
 		"model":    request.Model,
-		"messages": messages,
+		"messages": adaptMessages(request.Messages),
 	}
 
 	// 添加系统提示（如果有）
-	if systemPrompt != "" {
-		req["system"] = systemPrompt
+	if system := adaptSystem(request.Messages); system != nil {
+		req["system"] = system
 	}
 
 	// 添加可选参数
@@ -275,16 +358,48 @@ func adaptRequest(request *api.Request) map[string]interface{} {
 	if request.TopP != nil {
 		req["top_p"] = *request.TopP
 	}
+	if request.TopK != nil {
+		req["top_k"] = *request.TopK
+	}
 	if request.MaxTokens != nil {
 		req["max_tokens"] = *request.MaxTokens
+	} else if request.Thinking != nil {
+		req["max_tokens"] = request.Thinking.BudgetTokens + defaultMaxTokens
+	} else {
+		req["max_tokens"] = defaultMaxTokens
 	}
 	if len(request.Stop) > 0 {
 		req["stop_sequences"] = request.Stop
 	}
+	if request.User != "" {
+		// Anthropic的metadata只接受user_id
+		req["metadata"] = map[string]interface{}{"user_id": request.User}
+	}
 	if request.Stream {
 		req["stream"] = request.Stream
 	}
 
+	// 扩展思考
+	if request.Thinking != nil {
+		req["thinking"] = map[string]interface{}{
+			"type":          "enabled",
+			"budget_tokens": request.Thinking.BudgetTokens,
+		}
+	}
+
+	// 工具调用
+	if len(request.Tools) > 0 {
+		req["tools"] = adaptTools(request.Tools)
+	}
+	if request.ToolChoice != nil {
+		if choice := adaptToolChoice(request.ToolChoice); choice != nil {
+			req["tool_choice"] = choice
+		}
+	}
+	if request.ParallelToolCalls != nil && !*request.ParallelToolCalls && len(request.Tools) > 0 {
+		req["tool_choice"] = disableParallelToolUse(req["tool_choice"])
+	}
+
 	// 添加其他自定义参数
 	for k, v := range request.ExtraParams {
 		req[k] = v
@@ -293,25 +408,307 @@ func adaptRequest(request *api.Request) map[string]interface{} {
 	return req
 }
 
-// 将Anthropic的响应格式转换为SDK的通用格式
-func adaptResponse(anthropicResp *AnthropicResponse) *api.Response {
-	// 提取文本内容
-	var content string
-	for _, block := range anthropicResp.Content {
-		if block.Type == "text" {
-			content += block.Text
+// 将SDK的消息转换为Anthropic的消息格式
+//
+// 助手消息中的思考块必须原样放在最前面回传，否则开启扩展思考的工具调用多轮对话会被拒绝；
+// 工具结果以tool_result块的形式放入用户消息，同一轮的多个结果合并为一条消息。
+func adaptMessages(messages []api.Message) []map[string]interface{} {
+	var result []map[string]interface{}
+
+	for _, msg := range messages {
+		switch msg.Role {
+		case api.RoleSystem:
+			// 系统消息通过system字段传递
+			continue
+
+		case api.RoleTool:
+			block := map[string]interface{}{
+				"type":        "tool_result",
+				"tool_use_id": msg.ToolCallID,
+				"content":     msg.Content,
+			}
+			if msg.CacheControl != nil {
+				block["cache_control"] = adaptCacheControl(msg.CacheControl)
+			}
+			if n := len(result); n > 0 && result[n-1]["role"] == string(api.RoleUser) {
+				if blocks, ok := result[n-1]["content"].([]map[string]interface{}); ok {
+					result[n-1]["content"] = append(blocks, block)
+					continue
+				}
+			}
+			result = append(result, map[string]interface{}{
+				"role":    string(api.RoleUser),
+				"content": []map[string]interface{}{block},
+			})
+
+		case api.RoleAssistant:
+			if len(msg.ThinkingBlocks) == 0 && len(msg.ToolCalls) == 0 && !needsContentBlocks(msg) {
+				result = append(result, map[string]interface{}{
+					"role":    string(msg.Role),
+					"content": msg.Content,
+				})
+				continue
+			}
+
+			var blocks []map[string]interface{}
+			for _, thinking := range msg.ThinkingBlocks {
+				if thinking.Type == api.ThinkingTypeRedacted {
+					blocks = append(blocks, map[string]interface{}{
+						"type": api.ThinkingTypeRedacted,
+						"data": thinking.Data,
+					})
+					continue
+				}
+				blocks = append(blocks, map[string]interface{}{
+					"type":      api.ThinkingTypeThinking,
+					"thinking":  thinking.Thinking,
+					"signature": thinking.Signature,
+				})
+			}
+			blocks = append(blocks, adaptContentParts(msg)...)
+			for _, call := range msg.ToolCalls {
+				input := json.RawMessage(call.Function.Arguments)
+				if !json.Valid(input) {
+					input = json.RawMessage("{}")
+				}
+				blocks = append(blocks, map[string]interface{}{
+					"type":  "tool_use",
+					"id":    call.ID,
+					"name":  call.Function.Name,
+					"input": input,
+				})
+			}
+			result = append(result, map[string]interface{}{
+				"role":    string(msg.Role),
+				"content": applyCacheControl(blocks, msg.CacheControl),
+			})
+
+		default:
+			if needsContentBlocks(msg) {
+				result = append(result, map[string]interface{}{
+					"role":    string(msg.Role),
+					"content": applyCacheControl(adaptContentParts(msg), msg.CacheControl),
+				})
+				continue
+			}
+			result = append(result, map[string]interface{}{
+				"role":    string(msg.Role),
+				"content": msg.Content,
+			})
+		}
+	}
+
+	return result
+}
+
+// 将系统消息转换为system字段
+//
+// 没有设置缓存断点时沿用字符串形式；否则所有系统消息按顺序转换为文本块，
+// 以便在长系统提示的末尾设置断点。
+func adaptSystem(messages []api.Message) interface{} {
+	var systemMessages []api.Message
+	cached := false
+	for _, msg := range messages {
+		if msg.Role == api.RoleSystem {
+			systemMessages = append(systemMessages, msg)
+			cached = cached || needsContentBlocks(msg)
+		}
+	}
+
+	if len(systemMessages) == 0 {
+		return nil
+	}
+	if !cached {
+		// 与之前的行为一致，使用最后一条系统消息
+		if prompt := systemMessages[len(systemMessages)-1].Content; prompt != "" {
+			return prompt
+		}
+		return nil
+	}
+
+	var blocks []map[string]interface{}
+	for _, msg := range systemMessages {
+		blocks = append(blocks, applyCacheControl(adaptContentParts(msg), msg.CacheControl)...)
+	}
+	return blocks
+}
+
+// 判断消息是否需要以内容块数组的形式发送
+func needsContentBlocks(msg api.Message) bool {
+	return len(msg.Parts) > 0 || msg.CacheControl != nil
+}
+
+// 将消息的文本内容转换为text块，设置了Parts时逐段转换
+func adaptContentParts(msg api.Message) []map[string]interface{} {
+	if len(msg.Parts) == 0 {
+		if msg.Content == "" {
+			return nil
 		}
+		return []map[string]interface{}{{"type": "text", "text": msg.Content}}
 	}
 
+	blocks := make([]map[string]interface{}, 0, len(msg.Parts))
+	for _, part := range msg.Parts {
+		block := map[string]interface{}{
+			"type": api.ContentPartTypeText,
+			"text": part.Text,
+		}
+		if part.CacheControl != nil {
+			block["cache_control"] = adaptCacheControl(part.CacheControl)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// 在最后一个内容块上设置缓存断点
+func applyCacheControl(blocks []map[string]interface{}, cacheControl *api.CacheControl) []map[string]interface{} {
+	if cacheControl != nil && len(blocks) > 0 {
+		blocks[len(blocks)-1]["cache_control"] = adaptCacheControl(cacheControl)
+	}
+	return blocks
+}
+
+// 将SDK的缓存断点转换为Anthropic的格式
+func adaptCacheControl(cacheControl *api.CacheControl) map[string]interface{} {
+	cacheType := cacheControl.Type
+	if cacheType == "" {
+		cacheType = api.CacheControlEphemeral
+	}
+	result := map[string]interface{}{"type": cacheType}
+	if cacheControl.TTL != "" {
+		result["ttl"] = cacheControl.TTL
+	}
+	return result
+}
+
+// 将SDK的工具定义转换为Anthropic的格式
+func adaptTools(tools []api.Tool) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(tools))
+	for _, tool := range tools {
+		var schema interface{} = tool.Function.Parameters
+		if schema == nil {
+			schema = map[string]interface{}{"type": "object"}
+		}
+		t := map[string]interface{}{
+			"name":         tool.Function.Name,
+			"input_schema": schema,
+		}
+		if tool.Function.Description != "" {
+			t["description"] = tool.Function.Description
+		}
+		if tool.CacheControl != nil {
+			t["cache_control"] = adaptCacheControl(tool.CacheControl)
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+// 将SDK的工具选择转换为Anthropic的格式，兼容OpenAI风格的取值
+func adaptToolChoice(choice interface{}) interface{} {
+	switch v := choice.(type) {
+	case string:
+		switch v {
+		case "auto":
+			return map[string]interface{}{"type": "auto"}
+		case "any", "required":
+			return map[string]interface{}{"type": "any"}
+		case "none":
+			return map[string]interface{}{"type": "none"}
+		}
+		return nil
+	case map[string]interface{}:
+		// {"type":"function","function":{"name":"..."}} 转为 {"type":"tool","name":"..."}
+		if fn, ok := v["function"].(map[string]interface{}); ok {
+			if name, ok := fn["name"].(string); ok {
+				return map[string]interface{}{"type": "tool", "name": name}
+			}
+		}
+	}
+	return choice
+}
+
+// 在tool_choice上设置disable_parallel_tool_use以关闭并行工具调用，未指定时使用auto
+//
+// tool_choice为none时不会调用工具，保持不变；不修改调用方传入的对象。
+func disableParallelToolUse(choice interface{}) interface{} {
+	if choice == nil {
+		choice = map[string]interface{}{"type": "auto"}
+	}
+	m, ok := choice.(map[string]interface{})
+	if !ok || m["type"] == "none" {
+		return choice
+	}
+
+	result := make(map[string]interface{}, len(m)+1)
+	for k, v := range m {
+		result[k] = v
+	}
+	result["disable_parallel_tool_use"] = true
+	return result
+}
+
+// 将Anthropic的内容块转换为SDK的消息
+func adaptContentBlocks(blocks []ContentBlock) api.Message {
+	message := api.Message{Role: api.RoleAssistant}
+
+	for _, block := range blocks {
+		switch block.Type {
+		case "text":
+			message.Content += block.Text
+		case api.ThinkingTypeThinking:
+			message.ReasoningContent += block.Thinking
+			message.ThinkingBlocks = append(message.ThinkingBlocks, api.ThinkingBlock{
+				Type:      api.ThinkingTypeThinking,
+				Thinking:  block.Thinking,
+				Signature: block.Signature,
+			})
+		case api.ThinkingTypeRedacted:
+			message.ThinkingBlocks = append(message.ThinkingBlocks, api.ThinkingBlock{
+				Type: api.ThinkingTypeRedacted,
+				Data: block.Data,
+			})
+		case "tool_use":
+			arguments := string(block.Input)
+			if arguments == "" {
+				arguments = "{}"
+			}
+			message.ToolCalls = append(message.ToolCalls, api.ToolCall{
+				ID:   block.ID,
+				Type: api.ToolTypeFunction,
+				Function: api.FunctionCall{
+					Name:      block.Name,
+					Arguments: arguments,
+				},
+			})
+		}
+	}
+
+	return message
+}
+
+// 将Anthropic的令牌用量转换为SDK格式，PromptTokens包含写入和命中缓存的部分
+func adaptUsage(usage AnthropicUsage) api.Usage {
+	promptTokens := usage.InputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens
+	return api.Usage{
+		PromptTokens:        promptTokens,
+		CompletionTokens:    usage.OutputTokens,
+		TotalTokens:         promptTokens + usage.OutputTokens,
+		CacheCreationTokens: usage.CacheCreationInputTokens,
+		CacheReadTokens:     usage.CacheReadInputTokens,
+	}
+}
+
+// 将Anthropic的响应格式转换为SDK的通用格式
+func adaptResponse(anthropicResp *AnthropicResponse) *api.Response {
 	// 构建Choice
 	choices := []api.Choice{
 		{
-			Index: 0,
-			Message: api.Message{
-				Role:    api.RoleAssistant,
-				Content: content,
-			},
-			FinishReason: anthropicResp.StopReason,
+			Index:           0,
+			Message:         adaptContentBlocks(anthropicResp.Content),
+			FinishReason:    mapStopReason(anthropicResp.StopReason),
+			RawFinishReason: anthropicResp.StopReason,
 		},
 	}
 
@@ -321,11 +718,25 @@ func adaptResponse(anthropicResp *AnthropicResponse) *api.Response {
 		Created: time.Now().Unix(),
 		Model:   anthropicResp.Model,
 		Choices: choices,
-		Usage: api.Usage{
-			PromptTokens:     anthropicResp.Usage.InputTokens,
-			CompletionTokens: anthropicResp.Usage.OutputTokens,
-			TotalTokens:      anthropicResp.Usage.InputTokens + anthropicResp.Usage.OutputTokens,
-		},
+		Usage:   adaptUsage(anthropicResp.Usage),
+	}
+}
+
+// 将Anthropic的stop_reason映射为标准的结束原因
+func mapStopReason(reason string) api.FinishReason {
+	switch reason {
+	case "":
+		return ""
+	case "end_turn", "stop_sequence":
+		return api.FinishReasonStop
+	case "max_tokens":
+		return api.FinishReasonLength
+	case "tool_use":
+		return api.FinishReasonToolCalls
+	case "refusal":
+		return api.FinishReasonContentFilter
+	default:
+		return api.FinishReasonOther
 	}
 }
 
@@ -342,7 +753,7 @@ func mapAnthropicError(anthropicErr *AnthropicError, statusCode int) *api.Error
 		errType = api.ErrorTypeAuthentication
 	case "rate_limit_error":
 		errType = api.ErrorTypeRateLimit
-	case "server_error":
+	case "server_error", "api_error", "overloaded_error":
 		errType = api.ErrorTypeServer
 	}
 
@@ -355,8 +766,21 @@ func mapAnthropicError(anthropicErr *AnthropicError, statusCode int) *api.Error
 
 // anthropicResponseStream 实现流式响应接口
 type anthropicResponseStream struct {
-	reader    *utils.SSEReader
+	*utils.StreamDecoder
 	rawReader io.ReadCloser
+
+	// blockTypes 记录每个内容块的类型，用于在content_block_stop时收尾
+	blockTypes map[int]string
+	// toolIndexes 内容块下标到工具调用序号的映射
+	toolIndexes map[int]int
+	// thinking 和 signature 累积当前思考块，结束时整体输出以便多轮回传
+	thinking  strings.Builder
+	signature string
+	// usage message_start中给出的输入用量，结束时与输出用量合并
+	usage AnthropicUsage
+	// id 和 model 来自message_start，填充到之后的每个响应块中
+	id    string
+	model string
 }
 
 // AnthropicStreamResponse 定义Anthropic API的流式响应结构
@@ -366,6 +790,8 @@ type AnthropicStreamResponse struct {
 	ContentBlock *AnthropicContentBlock `json:"content_block,omitempty"`
 	Delta        *AnthropicContentDelta `json:"delta,omitempty"`
 	Index        int                    `json:"index,omitempty"`
+	// Usage 仅message_delta事件包含，给出累计的输出token数
+	Usage *AnthropicUsage `json:"usage,omitempty"`
 }
 
 // AnthropicStreamMessage 定义Anthropic流式消息结构
@@ -377,39 +803,78 @@ type AnthropicStreamMessage struct {
 	Model        string                  `json:"model"`
 	StopReason   string                  `json:"stop_reason,omitempty"`
 	StopSequence string                  `json:"stop_sequence,omitempty"`
-	Usage        struct {
-		InputTokens  int `json:"input_tokens"`
-		OutputTokens int `json:"output_tokens"`
-	} `json:"usage,omitempty"`
+	Usage        AnthropicUsage          `json:"usage,omitempty"`
 }
 
 // AnthropicContentBlock 定义Anthropic内容块结构
-type AnthropicContentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text,omitempty"`
-}
+type AnthropicContentBlock = ContentBlock
 
 // AnthropicContentDelta 定义Anthropic内容增量结构
+//
+// Type为text_delta、thinking_delta、signature_delta或input_json_delta；
+// message_delta事件的delta不带Type，只包含StopReason和StopSequence。
 type AnthropicContentDelta struct {
-	Type string `json:"type"`
-	Text string `json:"text,omitempty"`
+	Type         string `json:"type"`
+	Text         string `json:"text,omitempty"`
+	Thinking     string `json:"thinking,omitempty"`
+	Signature    string `json:"signature,omitempty"`
+	PartialJSON  string `json:"partial_json,omitempty"`
+	StopReason   string `json:"stop_reason,omitempty"`
+	StopSequence string `json:"stop_sequence,omitempty"`
 }
 
-// Recv 实现ResponseStream接口，读取下一个响应块
-func (s *anthropicResponseStream) Recv() (*api.ResponseChunk, error) {
-	event, err := s.reader.ReadEvent()
-	if err != nil {
-		if err == io.EOF {
-			return nil, io.EOF
-		}
-		return nil, api.NewError(api.ErrorTypeServer, "读取SSE事件失败", 0, err)
+// newChunk 构造只包含一个增量的响应块
+func (s *anthropicResponseStream) newChunk(index int, delta api.Message) *api.ResponseChunk {
+	delta.Role = api.RoleAssistant
+	return &api.ResponseChunk{
+		ID:      s.id,
+		Object:  "chat.completion.chunk",
+		Created: time.Now().Unix(),
+		Model:   s.model,
+		Choices: []api.ChunkChoice{
+			{
+				Index: index,
+				Delta: delta,
+			},
+		},
+	}
+}
+
+// mergeUsage 合并message_delta中的累计用量，非零字段覆盖message_start中的值
+func (s *anthropicResponseStream) mergeUsage(usage *AnthropicUsage) {
+	s.usage.OutputTokens = usage.OutputTokens
+	if usage.InputTokens > 0 {
+		s.usage.InputTokens = usage.InputTokens
+	}
+	if usage.CacheCreationInputTokens > 0 {
+		s.usage.CacheCreationInputTokens = usage.CacheCreationInputTokens
 	}
+	if usage.CacheReadInputTokens > 0 {
+		s.usage.CacheReadInputTokens = usage.CacheReadInputTokens
+	}
+}
+
+// toolIndex 返回内容块对应的工具调用序号
+func (s *anthropicResponseStream) toolIndex(blockIndex int) *int {
+	index, ok := s.toolIndexes[blockIndex]
+	if !ok {
+		index = len(s.toolIndexes)
+		s.toolIndexes[blockIndex] = index
+	}
+	return &index
+}
 
-	// 如果不是data字段，或者数据为空，则跳过
-	if event.Data == "" {
-		return s.Recv() // 递归调用直到获取到有效数据或EOF
+// parseStreamError 解析error事件中的错误，数据不是错误时返回nil
+func parseStreamError(data []byte) *api.Error {
+	var anthropicErr AnthropicError
+	if err := json.Unmarshal(data, &anthropicErr); err != nil || anthropicErr.Error.Message == "" {
+		return nil
 	}
+	return mapAnthropicError(&anthropicErr, 0)
+}
 
+// decode 将一个SSE事件解码为响应块
+func (s *anthropicResponseStream) decode(event *utils.SSEEvent) (*api.ResponseChunk, error) {
 	// 解析JSON数据
 	var streamResp AnthropicStreamResponse
 	if err := json.Unmarshal([]byte(utils.ParseSSEData(event.Data)), &streamResp); err != nil {
@@ -422,54 +887,107 @@ func (s *anthropicResponseStream) Recv() (*api.ResponseChunk, error) {
 	case "message_stop":
 		return nil, io.EOF
 
-	// 内容块事件
+	// 内容块增量事件
 	case "content_block_delta":
-		if streamResp.Delta == nil || streamResp.Delta.Type != "text" {
-			return s.Recv() // 非文本内容，继续获取下一个事件
+		if streamResp.Delta == nil {
+			return nil, nil
 		}
-		choices := []api.ChunkChoice{
-			{
-				Index: streamResp.Index,
-				Delta: api.Message{
-					Role:    api.RoleAssistant,
-					Content: streamResp.Delta.Text,
+		switch streamResp.Delta.Type {
+		case "text_delta":
+			return s.newChunk(0, api.Message{Content: streamResp.Delta.Text}), nil
+		case "thinking_delta":
+			s.thinking.WriteString(streamResp.Delta.Thinking)
+			return s.newChunk(0, api.Message{ReasoningContent: streamResp.Delta.Thinking}), nil
+		case "signature_delta":
+			s.signature += streamResp.Delta.Signature
+			return nil, nil
+		case "input_json_delta":
+			return s.newChunk(0, api.Message{
+				ToolCalls: []api.ToolCall{
+					{
+						Index:    s.toolIndex(streamResp.Index),
+						Type:     api.ToolTypeFunction,
+						Function: api.FunctionCall{Arguments: streamResp.Delta.PartialJSON},
+					},
 				},
-			},
+			}), nil
 		}
-
-		return &api.ResponseChunk{
-			ID:      "", // Anthropic流式API不在每个块中提供ID
-			Object:  "chat.completion.chunk",
-			Created: time.Now().Unix(),
-			Model:   "", // 同样，模型信息仅在消息完成后提供
-			Choices: choices,
-		}, nil
+		return nil, nil // 未识别的增量类型，继续获取下一个事件
 
 	// 内容块开始事件
 	case "content_block_start":
-		if streamResp.ContentBlock == nil || streamResp.ContentBlock.Type != "text" {
-			return s.Recv() // 非文本内容，继续获取下一个事件
+		if streamResp.ContentBlock == nil {
+			return nil, nil
+		}
+		block := streamResp.ContentBlock
+		s.blockTypes[streamResp.Index] = block.Type
+		switch block.Type {
+		case "tool_use":
+			return s.newChunk(0, api.Message{
+				ToolCalls: []api.ToolCall{
+					{
+						Index:    s.toolIndex(streamResp.Index),
+						ID:       block.ID,
+						Type:     api.ToolTypeFunction,
+						Function: api.FunctionCall{Name: block.Name},
+					},
+				},
+			}), nil
+		case api.ThinkingTypeThinking:
+			s.thinking.Reset()
+			s.signature = ""
+		case api.ThinkingTypeRedacted:
+			// 加密的思考块不会有增量，直接整体输出
+			return s.newChunk(0, api.Message{
+				ThinkingBlocks: []api.ThinkingBlock{{Type: api.ThinkingTypeRedacted, Data: block.Data}},
+			}), nil
+		}
+		// 文本块开始事件通常不包含实际文本内容，可以跳过
+		return nil, nil
+
+	// 内容块结束事件
+	case "content_block_stop":
+		if s.blockTypes[streamResp.Index] == api.ThinkingTypeThinking {
+			// 输出带签名的完整思考块，便于调用方在下一轮对话中回传
+			return s.newChunk(0, api.Message{
+				ThinkingBlocks: []api.ThinkingBlock{
+					{
+						Type:      api.ThinkingTypeThinking,
+						Thinking:  s.thinking.String(),
+						Signature: s.signature,
+					},
+				},
+			}), nil
 		}
-		// 通常这个事件不包含实际文本内容，可以跳过
-		return s.Recv()
+		return nil, nil
 
 	// 消息开始事件
 	case "message_start":
-		// 消息开始事件不包含内容，可以跳过
-		return s.Recv()
+		// 消息开始事件不包含内容，记录消息ID、模型和输入用量后跳过
+		s.id = streamResp.Message.ID
+		s.model = streamResp.Message.Model
+		s.usage = streamResp.Message.Usage
+		return nil, nil
 
-	// 消息完成事件
+	// 消息增量事件，停止原因在delta中给出
 	case "message_delta":
-		// 如果消息包含停止原因，返回EOF
-		if streamResp.Message.StopReason != "" {
-			return nil, io.EOF
+		if streamResp.Delta == nil || streamResp.Delta.StopReason == "" {
+			return nil, nil
+		}
+		// 输出携带结束原因的最后一个块，随后的message_stop返回EOF
+		chunk := s.newChunk(0, api.Message{})
+		chunk.Choices[0].FinishReason = mapStopReason(streamResp.Delta.StopReason)
+		chunk.Choices[0].RawFinishReason = streamResp.Delta.StopReason
+		if streamResp.Usage != nil {
+			s.mergeUsage(streamResp.Usage)
 		}
-		// 否则继续接收
-		return s.Recv()
+		usage := adaptUsage(s.usage)
+		chunk.Usage = &usage
+		return chunk, nil
 
 	// 未识别的事件类型
 	default:
-		return s.Recv()
+		return nil, nil
 	}
 }
 
@@ -0,0 +1,56 @@
+package anthropic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/models"
+)
+
+func TestValidateRequestThinking(t *testing.T) {
+	float := func(v float64) *float64 { return &v }
+	integer := func(v int) *int { return &v }
+
+	tests := []struct {
+		name      string
+		modify    func(request *api.Request)
+		wantParam string
+	}{
+		{name: "valid", modify: func(request *api.Request) {}},
+		{name: "temperature 1", modify: func(request *api.Request) { request.Temperature = float(1) }},
+		{name: "top_p in range", modify: func(request *api.Request) { request.TopP = float(0.95) }},
+		{name: "budget too small", modify: func(request *api.Request) { request.Thinking.BudgetTokens = 512 }, wantParam: "thinking"},
+		{name: "max_tokens equals budget", modify: func(request *api.Request) { request.MaxTokens = integer(2048) }, wantParam: "max_tokens"},
+		{name: "temperature not 1", modify: func(request *api.Request) { request.Temperature = float(0.7) }, wantParam: "temperature"},
+		{name: "top_k", modify: func(request *api.Request) { request.TopK = integer(40) }, wantParam: "top_k"},
+		{name: "top_p too small", modify: func(request *api.Request) { request.TopP = float(0.9) }, wantParam: "top_p"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &api.Request{
+				Model:     models.ClaudeSonnet4,
+				Messages:  []api.Message{{Role: api.RoleUser, Content: "hi"}},
+				MaxTokens: integer(8192),
+				Thinking:  &api.ThinkingConfig{BudgetTokens: 2048},
+			}
+			tt.modify(request)
+
+			err := validateRequest(request)
+			if tt.wantParam == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var apiErr *api.Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want *api.Error", err)
+			}
+			if apiErr.Type != api.ErrorTypeInvalidRequest || apiErr.Param != tt.wantParam {
+				t.Errorf("got type %s param %q, want invalid_request param %q", apiErr.Type, apiErr.Param, tt.wantParam)
+			}
+		})
+	}
+}
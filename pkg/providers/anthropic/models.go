@@ -0,0 +1,67 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/models"
+)
+
+// modelsPageSize 每次查询模型列表的数量，为接口允许的最大值
+const modelsPageSize = "1000"
+
+// AnthropicModel 定义/v1/models接口返回的模型
+type AnthropicModel struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	DisplayName string    `json:"display_name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AnthropicModelList 定义/v1/models接口的响应
+type AnthropicModelList struct {
+	Data    []AnthropicModel `json:"data"`
+	HasMore bool             `json:"has_more"`
+	FirstID string           `json:"first_id"`
+	LastID  string           `json:"last_id"`
+}
+
+// ListModels 实现api.ModelLister接口，读取/v1/models的所有分页，按发布时间从新到旧排列
+//
+// 列表中只有带日期后缀的模型ID，不包含claude-sonnet-4-0等别名。
+func (c *Client) ListModels(ctx context.Context) ([]api.Model, error) {
+	var result []api.Model
+	afterID := ""
+	for {
+		query := url.Values{}
+		query.Set("limit", modelsPageSize)
+		if afterID != "" {
+			query.Set("after_id", afterID)
+		}
+
+		var list AnthropicModelList
+		if err := c.doJSON(ctx, http.MethodGet, "/v1/models?"+query.Encode(), nil, &list); err != nil {
+			return nil, err
+		}
+		for _, model := range list.Data {
+			info := api.Model{
+				ID:           model.ID,
+				DisplayName:  model.DisplayName,
+				OwnedBy:      "anthropic",
+				Capabilities: []string{models.CapabilityChat},
+			}
+			if !model.CreatedAt.IsZero() {
+				info.Created = model.CreatedAt.Unix()
+			}
+			result = append(result, info)
+		}
+
+		if !list.HasMore || list.LastID == "" {
+			return result, nil
+		}
+		afterID = list.LastID
+	}
+}
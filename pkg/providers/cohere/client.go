@@ -0,0 +1,763 @@
+package cohere
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/utils"
+)
+
+// Client 实现了Cohere v2的API客户端
+//
+// Client同时实现了api.LLMClient和api.Reranker接口，
+// 带input_type的批量嵌入见Embed。
+type Client struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	// streamClient 用于流式请求，不设置总超时，由streamTimeouts分阶段控制
+	streamClient   *http.Client
+	streamTimeouts api.StreamTimeouts
+	// strictParams 为true时不支持的参数返回错误，否则忽略并在响应中附带警告
+	strictParams bool
+}
+
+// 确保Client实现了重排序接口
+var _ api.Reranker = (*Client)(nil)
+
+// 默认配置
+const (
+	defaultBaseURL        = "https://api.cohere.com"
+	defaultTimeout        = 30 * time.Second
+	defaultMaxRetries     = 3
+	defaultEmbeddingModel = "embed-multilingual-v3.0"
+)
+
+// 嵌入输入类型，v3及以上的嵌入模型必须指定
+const (
+	InputTypeSearchDocument = "search_document"
+	InputTypeSearchQuery    = "search_query"
+	InputTypeClassification = "classification"
+	InputTypeClustering     = "clustering"
+)
+
+// NewClient 创建一个新的Cohere客户端
+func NewClient(options ...api.ClientOption) (api.LLMClient, error) {
+	clientOptions := &api.ClientOptions{
+		BaseURL:    defaultBaseURL,
+		Timeout:    int(defaultTimeout.Seconds()),
+		MaxRetries: defaultMaxRetries,
+	}
+
+	// 应用选项
+	for _, option := range options {
+		option(clientOptions)
+	}
+
+	// 验证必要的配置
+	if clientOptions.APIKey == "" {
+		return nil, api.NewError(api.ErrorTypeAuthentication, "API密钥不能为空", 0, nil)
+	}
+
+	// 创建HTTP客户端
+	httpClient := &http.Client{
+		Timeout: time.Duration(clientOptions.Timeout) * time.Second,
+	}
+	if clientOptions.HTTPClient != nil {
+		if client, ok := clientOptions.HTTPClient.(*http.Client); ok {
+			httpClient = client
+		}
+	}
+
+	return &Client{
+		apiKey:         clientOptions.APIKey,
+		baseURL:        clientOptions.BaseURL,
+		httpClient:     httpClient,
+		streamClient:   utils.NewStreamClient(httpClient),
+		streamTimeouts: clientOptions.StreamTimeouts.WithDefaults(time.Duration(clientOptions.Timeout) * time.Second),
+		strictParams:   clientOptions.StrictParams,
+		maxRetries:     clientOptions.MaxRetries,
+	}, nil
+}
+
+// Complete 发送请求并获取完整的响应
+func (c *Client) Complete(ctx context.Context, request *api.Request) (*api.Response, error) {
+	// 验证请求
+	if err := validateRequest(request); err != nil {
+		return nil, err
+	}
+
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.post(ctx, "/v2/chat", adaptRequest(request))
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析响应
+	var cohereResp CohereResponse
+	if err := json.Unmarshal(body, &cohereResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", http.StatusOK, err)
+	}
+
+	response := adaptResponse(&cohereResp, request.Model)
+	response.Warnings = warnings
+	return response, nil
+}
+
+// CompleteStream 发送请求并获取流式响应
+func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.ResponseStream, error) {
+	// 验证请求
+	if err := validateRequest(request); err != nil {
+		return nil, err
+	}
+
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// 设置流式标志
+	reqCopy := *request
+	reqCopy.Stream = true
+
+	resp, err := c.doRequest(ctx, "/v2/chat", adaptRequest(&reqCopy), true)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查HTTP状态码
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, parseErrorBody(body, resp.StatusCode)
+	}
+
+	stream := &cohereResponseStream{
+		rawReader: resp.Body,
+		model:     request.Model,
+	}
+	stream.StreamDecoder = utils.NewStreamDecoder(resp.Body, stream.decode)
+	return utils.WithWarnings(stream, warnings), nil
+}
+
+// Embedding 获取文本的嵌入向量，使用默认的多语言嵌入模型和search_document输入类型
+func (c *Client) Embedding(ctx context.Context, input string) ([]float32, error) {
+	embeddings, err := c.Embed(ctx, &EmbedRequest{
+		Texts:     []string{input},
+		InputType: InputTypeSearchDocument,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// EmbedRequest 定义Cohere嵌入请求参数
+type EmbedRequest struct {
+	// Model 为空时使用embed-multilingual-v3.0
+	Model string   `json:"model"`
+	Texts []string `json:"texts"`
+	// InputType 取值见InputType*常量，为空时使用search_document
+	InputType string `json:"input_type"`
+	// Truncate 超长输入的截断方式：NONE、START或END
+	Truncate string `json:"truncate,omitempty"`
+}
+
+// Embed 批量获取文本的嵌入向量，返回结果与Texts一一对应
+func (c *Client) Embed(ctx context.Context, request *EmbedRequest) ([][]float32, error) {
+	if request == nil || len(request.Texts) == 0 {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "嵌入文本不能为空", 0, nil)
+	}
+
+	reqCopy := *request
+	if reqCopy.Model == "" {
+		reqCopy.Model = defaultEmbeddingModel
+	}
+	if reqCopy.InputType == "" {
+		reqCopy.InputType = InputTypeSearchDocument
+	}
+
+	req := map[string]interface{}{
+		"model":           reqCopy.Model,
+		"texts":           reqCopy.Texts,
+		"input_type":      reqCopy.InputType,
+		"embedding_types": []string{"float"},
+	}
+	if reqCopy.Truncate != "" {
+		req["truncate"] = reqCopy.Truncate
+	}
+
+	body, err := c.post(ctx, "/v2/embed", req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析嵌入响应
+	var embedResp struct {
+		ID         string `json:"id"`
+		Embeddings struct {
+			Float [][]float32 `json:"float"`
+		} `json:"embeddings"`
+	}
+	if err := json.Unmarshal(body, &embedResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析嵌入响应失败", http.StatusOK, err)
+	}
+
+	if len(embedResp.Embeddings.Float) != len(reqCopy.Texts) {
+		return nil, api.NewError(api.ErrorTypeServer, "未收到有效的嵌入结果", http.StatusOK, nil)
+	}
+
+	return embedResp.Embeddings.Float, nil
+}
+
+// Rerank 实现api.Reranker接口，根据与查询的相关性对文档重新排序
+func (c *Client) Rerank(ctx context.Context, request *api.RerankRequest) (*api.RerankResponse, error) {
+	if request == nil {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "请求不能为空", 0, nil)
+	}
+	if request.Model == "" {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "模型不能为空", 0, nil)
+	}
+	if request.Query == "" {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "查询不能为空", 0, nil)
+	}
+	if len(request.Documents) == 0 {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "文档不能为空", 0, nil)
+	}
+
+	req := map[string]interface{}{
+		"model":     request.Model,
+		"query":     request.Query,
+		"documents": request.Documents,
+	}
+	if request.TopN != nil {
+		req["top_n"] = *request.TopN
+	}
+	for k, v := range request.ExtraParams {
+		req[k] = v
+	}
+
+	body, err := c.post(ctx, "/v2/rerank", req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析重排序响应
+	var rerankResp struct {
+		ID      string `json:"id"`
+		Results []struct {
+			Index          int     `json:"index"`
+			RelevanceScore float64 `json:"relevance_score"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &rerankResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析重排序响应失败", http.StatusOK, err)
+	}
+
+	results := make([]api.RerankResult, len(rerankResp.Results))
+	for i, result := range rerankResp.Results {
+		results[i] = api.RerankResult{
+			Index:          result.Index,
+			RelevanceScore: result.RelevanceScore,
+		}
+		// v2接口不再返回文档原文，按需从请求中回填
+		if request.ReturnDocuments && result.Index >= 0 && result.Index < len(request.Documents) {
+			results[i].Document = request.Documents[result.Index]
+		}
+	}
+
+	return &api.RerankResponse{
+		ID:      rerankResp.ID,
+		Model:   request.Model,
+		Results: results,
+	}, nil
+}
+
+// post 发送JSON请求并返回成功响应的响应体
+func (c *Client) post(ctx context.Context, path string, payload interface{}) ([]byte, error) {
+	resp, err := c.doRequest(ctx, path, payload, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// 读取响应
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "读取响应失败", resp.StatusCode, err)
+	}
+
+	// 检查HTTP状态码
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseErrorBody(body, resp.StatusCode)
+	}
+
+	return body, nil
+}
+
+// doRequest 构造并发送HTTP请求
+func (c *Client) doRequest(ctx context.Context, path string, payload interface{}, stream bool) (*http.Response, error) {
+	// 准备请求体
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "无法序列化请求", 0, err)
+	}
+
+	// 创建HTTP请求
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "创建HTTP请求失败", 0, err)
+	}
+
+	// 设置请求头
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	// 流式请求分阶段控制超时
+	if stream {
+		return utils.DoStreamRequest(c.streamClient, req, c.streamTimeouts)
+	}
+
+	// 发送请求
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "HTTP请求失败", 0, err)
+	}
+	return resp, nil
+}
+
+// 验证请求参数
+func validateRequest(request *api.Request) error {
+	if request == nil {
+		return api.NewError(api.ErrorTypeInvalidRequest, "请求不能为空", 0, nil)
+	}
+	if request.Model == "" {
+		return api.NewError(api.ErrorTypeInvalidRequest, "模型不能为空", 0, nil)
+	}
+	if len(request.Messages) == 0 {
+		return api.NewError(api.ErrorTypeInvalidRequest, "消息不能为空", 0, nil)
+	}
+	return nil
+}
+
+// supportedParams Cohere支持的可选参数
+var supportedParams = api.NewParamSet(
+	api.ParamTemperature, api.ParamTopP, api.ParamTopK, api.ParamMaxTokens, api.ParamPresencePenalty, api.ParamFrequencyPenalty, api.ParamStop,
+	api.ParamSeed, api.ParamTools, api.ParamToolChoice, api.ParamResponseFormat,
+)
+
+// 检查请求中Cohere不支持的参数，非严格模式下返回忽略这些参数的警告
+func (c *Client) checkParams(request *api.Request) ([]string, error) {
+	return api.CheckParams("Cohere", request, supportedParams, c.strictParams)
+}
+
+// CohereResponse 定义Cohere v2 chat接口的响应结构
+type CohereResponse struct {
+	ID string `json:"id"`
+	// FinishReason 可能为COMPLETE、STOP_SEQUENCE、MAX_TOKENS、TOOL_CALL或ERROR
+	FinishReason string        `json:"finish_reason"`
+	Message      CohereMessage `json:"message"`
+	Usage        CohereUsage   `json:"usage"`
+}
+
+// CohereMessage 定义Cohere的助手消息结构
+type CohereMessage struct {
+	Role      string           `json:"role"`
+	Content   []CohereContent  `json:"content"`
+	ToolPlan  string           `json:"tool_plan,omitempty"`
+	ToolCalls []CohereToolCall `json:"tool_calls,omitempty"`
+}
+
+// CohereContent 定义Cohere的内容块
+type CohereContent struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+// CohereToolCall 定义Cohere的工具调用结构
+type CohereToolCall struct {
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments,omitempty"`
+	} `json:"function"`
+}
+
+// CohereUsage 定义Cohere的用量信息
+type CohereUsage struct {
+	BilledUnits struct {
+		InputTokens  float64 `json:"input_tokens"`
+		OutputTokens float64 `json:"output_tokens"`
+	} `json:"billed_units"`
+	Tokens struct {
+		InputTokens  float64 `json:"input_tokens"`
+		OutputTokens float64 `json:"output_tokens"`
+	} `json:"tokens"`
+}
+
+// CohereError 定义Cohere API的错误响应
+type CohereError struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+// 将SDK的请求格式转换为Cohere v2的格式
+func adaptRequest(request *api.Request) map[string]interface{} {
+	req := map[string]interface{}{
+		"model":    request.Model,
+		"messages": adaptMessages(request.Messages),
+	}
+
+	// 添加可选参数
+	if request.Temperature != nil {
+		req["temperature"] = *request.Temperature
+	}
+	if request.TopP != nil {
+		req["p"] = *request.TopP
+	}
+	if request.TopK != nil {
+		req["k"] = *request.TopK
+	}
+	if request.MaxTokens != nil {
+		req["max_tokens"] = *request.MaxTokens
+	}
+	if request.PresencePenalty != nil {
+		req["presence_penalty"] = *request.PresencePenalty
+	}
+	if request.FrequencyPenalty != nil {
+		req["frequency_penalty"] = *request.FrequencyPenalty
+	}
+	if len(request.Stop) > 0 {
+		req["stop_sequences"] = request.Stop
+	}
+	if request.Seed != nil {
+		req["seed"] = *request.Seed
+	}
+	if request.Stream {
+		req["stream"] = request.Stream
+	}
+
+	// 函数调用
+	if len(request.Tools) > 0 {
+		req["tools"] = request.Tools
+	}
+	if choice := adaptToolChoice(request.ToolChoice); choice != "" {
+		req["tool_choice"] = choice
+	}
+
+	// JSON模式
+	if request.ResponseFormat != nil {
+		req["response_format"] = adaptResponseFormat(request.ResponseFormat)
+	}
+
+	// 添加其他自定义参数（如documents、citation_options）
+	for k, v := range request.ExtraParams {
+		req[k] = v
+	}
+
+	return req
+}
+
+// 将SDK的消息转换为Cohere v2的消息格式
+func adaptMessages(messages []api.Message) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(messages))
+	for _, msg := range messages {
+		m := map[string]interface{}{
+			"role": string(msg.Role),
+		}
+		if msg.Content != "" || len(msg.ToolCalls) == 0 {
+			m["content"] = msg.Content
+		}
+		if len(msg.ToolCalls) > 0 {
+			toolCalls := make([]map[string]interface{}, len(msg.ToolCalls))
+			for i, call := range msg.ToolCalls {
+				toolCalls[i] = map[string]interface{}{
+					"id":   call.ID,
+					"type": api.ToolTypeFunction,
+					"function": map[string]interface{}{
+						"name":      call.Function.Name,
+						"arguments": call.Function.Arguments,
+					},
+				}
+			}
+			m["tool_calls"] = toolCalls
+		}
+		if msg.ToolCallID != "" {
+			m["tool_call_id"] = msg.ToolCallID
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+// Cohere只支持REQUIRED和NONE两种强制策略，auto为默认行为无需传递
+func adaptToolChoice(choice interface{}) string {
+	s, ok := choice.(string)
+	if !ok {
+		return ""
+	}
+	switch strings.ToLower(s) {
+	case "required", "any":
+		return "REQUIRED"
+	case "none":
+		return "NONE"
+	}
+	return ""
+}
+
+// Cohere的json_schema直接传入Schema本身，这里兼容OpenAI风格的{name, schema}包装
+func adaptResponseFormat(format *api.ResponseFormat) map[string]interface{} {
+	if format.Type == api.ResponseFormatText {
+		return map[string]interface{}{"type": "text"}
+	}
+
+	result := map[string]interface{}{"type": "json_object"}
+	if format.JSONSchema != nil {
+		schema := format.JSONSchema
+		if wrapped, ok := schema.(map[string]interface{}); ok {
+			if inner, ok := wrapped["schema"]; ok {
+				schema = inner
+			}
+		}
+		result["json_schema"] = schema
+	}
+	return result
+}
+
+// 将Cohere的用量转换为SDK的通用格式，优先使用实际计费的token数
+func adaptUsage(usage CohereUsage) api.Usage {
+	input := int(usage.BilledUnits.InputTokens)
+	output := int(usage.BilledUnits.OutputTokens)
+	if input == 0 && output == 0 {
+		input = int(usage.Tokens.InputTokens)
+		output = int(usage.Tokens.OutputTokens)
+	}
+	return api.Usage{
+		PromptTokens:     input,
+		CompletionTokens: output,
+		TotalTokens:      input + output,
+	}
+}
+
+// 将Cohere的响应格式转换为SDK的通用格式
+func adaptResponse(cohereResp *CohereResponse, modelName string) *api.Response {
+	// 提取文本内容
+	var content string
+	for _, block := range cohereResp.Message.Content {
+		if block.Type == "text" {
+			content += block.Text
+		}
+	}
+
+	var toolCalls []api.ToolCall
+	for _, call := range cohereResp.Message.ToolCalls {
+		toolCalls = append(toolCalls, api.ToolCall{
+			ID:   call.ID,
+			Type: api.ToolTypeFunction,
+			Function: api.FunctionCall{
+				Name:      call.Function.Name,
+				Arguments: call.Function.Arguments,
+			},
+		})
+	}
+
+	choices := []api.Choice{
+		{
+			Index: 0,
+			Message: api.Message{
+				Role:      api.RoleAssistant,
+				Content:   content,
+				ToolCalls: toolCalls,
+			},
+			FinishReason:    mapFinishReason(cohereResp.FinishReason),
+			RawFinishReason: cohereResp.FinishReason,
+		},
+	}
+
+	return &api.Response{
+		ID:      cohereResp.ID,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   modelName,
+		Choices: choices,
+		Usage:   adaptUsage(cohereResp.Usage),
+	}
+}
+
+// parseErrorBody 解析错误响应体
+func parseErrorBody(body []byte, statusCode int) *api.Error {
+	var cohereErr CohereError
+	if err := json.Unmarshal(body, &cohereErr); err != nil || cohereErr.Message == "" {
+		return api.NewError(mapStatusCode(statusCode), fmt.Sprintf("API错误(状态码: %d)", statusCode), statusCode, nil)
+	}
+	return &api.Error{
+		Type:       mapStatusCode(statusCode),
+		Message:    cohereErr.Message,
+		StatusCode: statusCode,
+	}
+}
+
+// 将Cohere的结束原因映射为标准值
+func mapFinishReason(reason string) api.FinishReason {
+	switch reason {
+	case "":
+		return ""
+	case "COMPLETE", "STOP_SEQUENCE":
+		return api.FinishReasonStop
+	case "MAX_TOKENS":
+		return api.FinishReasonLength
+	case "TOOL_CALL":
+		return api.FinishReasonToolCalls
+	case "ERROR_TOXIC":
+		return api.FinishReasonContentFilter
+	case "ERROR", "ERROR_LIMIT":
+		return api.FinishReasonError
+	default:
+		return api.FinishReasonOther
+	}
+}
+
+// Cohere的错误响应不含错误类型，只能按状态码映射
+func mapStatusCode(statusCode int) api.ErrorType {
+	switch {
+	case statusCode == 400 || statusCode == 404 || statusCode == 422:
+		return api.ErrorTypeInvalidRequest
+	case statusCode == 401 || statusCode == 403 || statusCode == 498:
+		return api.ErrorTypeAuthentication
+	case statusCode == 429:
+		return api.ErrorTypeRateLimit
+	case statusCode == 499:
+		return api.ErrorTypeConnection
+	case statusCode == 504:
+		return api.ErrorTypeTimeout
+	case statusCode >= 500:
+		return api.ErrorTypeServer
+	}
+	return api.ErrorTypeUnknown
+}
+
+// cohereResponseStream 实现流式响应接口
+type cohereResponseStream struct {
+	*utils.StreamDecoder
+	rawReader io.ReadCloser
+	model     string
+	id        string
+}
+
+// CohereStreamEvent 定义Cohere v2的流式事件结构
+//
+// 事件类型包括message-start、content-start、content-delta、content-end、
+// tool-plan-delta、tool-call-start、tool-call-delta、tool-call-end、citation-start、
+// citation-end和message-end。
+type CohereStreamEvent struct {
+	Type  string `json:"type"`
+	ID    string `json:"id,omitempty"`
+	Index int    `json:"index"`
+	Delta struct {
+		Message struct {
+			Role    string `json:"role,omitempty"`
+			Content struct {
+				Type string `json:"type,omitempty"`
+				Text string `json:"text,omitempty"`
+			} `json:"content"`
+			ToolPlan  string         `json:"tool_plan,omitempty"`
+			ToolCalls CohereToolCall `json:"tool_calls"`
+		} `json:"message"`
+		FinishReason string       `json:"finish_reason,omitempty"`
+		Usage        *CohereUsage `json:"usage,omitempty"`
+		Error        string       `json:"error,omitempty"`
+	} `json:"delta"`
+}
+
+// decode 将一个SSE事件解码为响应块
+func (s *cohereResponseStream) decode(event *utils.SSEEvent) (*api.ResponseChunk, error) {
+	// 解析JSON数据
+	var streamEvent CohereStreamEvent
+	if err := json.Unmarshal([]byte(utils.ParseSSEData(event.Data)), &streamEvent); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析流式响应失败", 0, err)
+	}
+
+	var delta api.Message
+	var finishReason string
+	var usage *api.Usage
+
+	switch streamEvent.Type {
+	case "message-start":
+		s.id = streamEvent.ID
+		delta.Role = api.RoleAssistant
+
+	case "content-delta":
+		if streamEvent.Delta.Message.Content.Text == "" {
+			return nil, nil
+		}
+		delta.Content = streamEvent.Delta.Message.Content.Text
+
+	case "tool-call-start", "tool-call-delta":
+		index := streamEvent.Index
+		call := streamEvent.Delta.Message.ToolCalls
+		delta.ToolCalls = []api.ToolCall{
+			{
+				Index: &index,
+				ID:    call.ID,
+				Type:  api.ToolTypeFunction,
+				Function: api.FunctionCall{
+					Name:      call.Function.Name,
+					Arguments: call.Function.Arguments,
+				},
+			},
+		}
+
+	case "message-end":
+		if streamEvent.Delta.Error != "" {
+			return nil, api.NewError(api.ErrorTypeServer, streamEvent.Delta.Error, 0, nil)
+		}
+		finishReason = streamEvent.Delta.FinishReason
+		if streamEvent.Delta.Usage != nil {
+			adapted := adaptUsage(*streamEvent.Delta.Usage)
+			usage = &adapted
+		}
+
+	default:
+		// content-start、content-end、tool-plan-delta、引用等事件不产生输出
+		return nil, nil
+	}
+
+	return &api.ResponseChunk{
+		ID:      s.id,
+		Object:  "chat.completion.chunk",
+		Created: time.Now().Unix(),
+		Model:   s.model,
+		Choices: []api.ChunkChoice{
+			{
+				Index:           0,
+				Delta:           delta,
+				FinishReason:    mapFinishReason(finishReason),
+				RawFinishReason: finishReason,
+			},
+		},
+		Usage: usage,
+	}, nil
+}
+
+// Close 关闭流
+func (s *cohereResponseStream) Close() error {
+	return s.rawReader.Close()
+}
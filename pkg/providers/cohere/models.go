@@ -0,0 +1,91 @@
+package cohere
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/models"
+	"github.com/ojbkgo/llm-sdk/pkg/utils"
+)
+
+// modelsPageSize 每次查询模型列表的数量，为接口允许的最大值
+const modelsPageSize = "1000"
+
+// CohereModel 定义/v1/models接口返回的模型
+type CohereModel struct {
+	Name string `json:"name"`
+	// Endpoints 模型可用的接口，如chat、embed、rerank
+	Endpoints     []string `json:"endpoints"`
+	Finetuned     bool     `json:"finetuned,omitempty"`
+	ContextLength float64  `json:"context_length,omitempty"`
+}
+
+// CohereModelList 定义/v1/models接口的响应
+type CohereModelList struct {
+	Models        []CohereModel `json:"models"`
+	NextPageToken string        `json:"next_page_token,omitempty"`
+}
+
+// ListModels 实现api.ModelLister接口，读取/v1/models的所有分页，能力由模型可用的接口推断
+func (c *Client) ListModels(ctx context.Context) ([]api.Model, error) {
+	config := utils.DefaultHTTPConfig()
+	config.MaxRetries = c.maxRetries
+
+	var result []api.Model
+	pageToken := ""
+	for {
+		query := url.Values{}
+		query.Set("page_size", modelsPageSize)
+		if pageToken != "" {
+			query.Set("page_token", pageToken)
+		}
+
+		body, statusCode, err := utils.DoHTTPRequest(ctx, c.httpClient, http.MethodGet, c.baseURL+"/v1/models?"+query.Encode(), nil, utils.MakeAuthHeader(c.apiKey, "bearer"), config)
+		if err != nil {
+			return nil, err
+		}
+
+		// 检查HTTP状态码
+		if statusCode != http.StatusOK {
+			return nil, parseErrorBody(body, statusCode)
+		}
+
+		var list CohereModelList
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", statusCode, err)
+		}
+		for _, model := range list.Models {
+			result = append(result, adaptModel(model))
+		}
+
+		if list.NextPageToken == "" {
+			return result, nil
+		}
+		pageToken = list.NextPageToken
+	}
+}
+
+// 将Cohere的模型转换为SDK格式
+func adaptModel(model CohereModel) api.Model {
+	var capabilities []string
+	for _, endpoint := range model.Endpoints {
+		switch endpoint {
+		case "chat":
+			capabilities = append(capabilities, models.CapabilityChat)
+		case "embed":
+			capabilities = append(capabilities, models.CapabilityEmbedding)
+		case "rerank":
+			capabilities = append(capabilities, models.CapabilityRerank)
+		}
+	}
+
+	return api.Model{
+		ID:              model.Name,
+		OwnedBy:         "cohere",
+		InputTokenLimit: int(model.ContextLength),
+		Capabilities:    capabilities,
+	}
+}
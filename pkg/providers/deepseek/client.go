@@ -19,6 +19,11 @@ type Client struct {
 	baseURL    string
 	httpClient *http.Client
 	maxRetries int
+	// streamClient 用于流式请求，不设置总超时，由streamTimeouts分阶段控制
+	streamClient   *http.Client
+	streamTimeouts api.StreamTimeouts
+	// strictParams 为true时不支持的参数返回错误，否则忽略并在响应中附带警告
+	strictParams bool
 }
 
 // 默认配置
@@ -57,10 +62,13 @@ func NewClient(options ...api.ClientOption) (api.LLMClient, error) {
 	}
 
 	return &Client{
-		apiKey:     clientOptions.APIKey,
-		baseURL:    clientOptions.BaseURL,
-		httpClient: httpClient,
-		maxRetries: clientOptions.MaxRetries,
+		apiKey:         clientOptions.APIKey,
+		baseURL:        clientOptions.BaseURL,
+		httpClient:     httpClient,
+		streamClient:   utils.NewStreamClient(httpClient),
+		streamTimeouts: clientOptions.StreamTimeouts.WithDefaults(time.Duration(clientOptions.Timeout) * time.Second),
+		strictParams:   clientOptions.StrictParams,
+		maxRetries:     clientOptions.MaxRetries,
 	}, nil
 }
 
@@ -71,6 +79,17 @@ func (c *Client) Complete(ctx context.Context, request *api.Request) (*api.Respo
 		return nil, err
 	}
 
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// DeepSeek不支持n参数，多个候选通过并行请求模拟
+	if request.N > 1 {
+		return utils.CompleteCandidates(ctx, request, c.Complete)
+	}
+
 	// 准备请求体
 	reqBody, err := json.Marshal(adaptRequest(request))
 	if err != nil {
@@ -115,7 +134,9 @@ func (c *Client) Complete(ctx context.Context, request *api.Request) (*api.Respo
 		return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", resp.StatusCode, err)
 	}
 
-	return adaptResponse(&deepseekResp), nil
+	response := adaptResponse(&deepseekResp)
+	response.Warnings = warnings
+	return response, nil
 }
 
 // CompleteStream 发送请求并获取流式响应
@@ -125,6 +146,17 @@ func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.
 		return nil, err
 	}
 
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// DeepSeek不支持n参数，多个候选通过并行请求模拟
+	if request.N > 1 {
+		return utils.StreamCandidates(ctx, request, c.CompleteStream)
+	}
+
 	// 设置流式标志
 	reqCopy := *request
 	reqCopy.Stream = true
@@ -147,9 +179,9 @@ func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.
 	req.Header.Set("Accept", "text/event-stream")
 
 	// 发送请求
-	resp, err := c.httpClient.Do(req)
+	resp, err := utils.DoStreamRequest(c.streamClient, req, c.streamTimeouts)
 	if err != nil {
-		return nil, api.NewError(api.ErrorTypeConnection, "HTTP请求失败", 0, err)
+		return nil, err
 	}
 
 	// 检查HTTP状态码
@@ -164,10 +196,15 @@ func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.
 		return nil, mapDeepSeekError(&deepseekErr, resp.StatusCode)
 	}
 
-	return &deepseekResponseStream{
-		reader:    utils.NewSSEReader(resp.Body),
+	stream := &deepseekResponseStream{
+		StreamDecoder: utils.NewStreamDecoder(resp.Body, decodeStreamEvent, func(options *utils.StreamDecoderOptions) {
+			options.DoneData = "[DONE]"
+			options.ParseError = parseStreamError
+			options.InlineErrors = true
+		}),
 		rawReader: resp.Body,
-	}, nil
+	}
+	return utils.WithWarnings(stream, warnings), nil
 }
 
 // Embedding 获取文本的嵌入向量
@@ -249,6 +286,17 @@ func validateRequest(request *api.Request) error {
 	return nil
 }
 
+// supportedParams DeepSeek支持的可选参数
+var supportedParams = api.NewParamSet(
+	api.ParamTemperature, api.ParamTopP, api.ParamMaxTokens, api.ParamPresencePenalty, api.ParamFrequencyPenalty, api.ParamStop,
+	api.ParamN, api.ParamLogprobs, api.ParamTopLogprobs, api.ParamTools, api.ParamToolChoice, api.ParamResponseFormat,
+)
+
+// 检查请求中DeepSeek不支持的参数，非严格模式下返回忽略这些参数的警告
+func (c *Client) checkParams(request *api.Request) ([]string, error) {
+	return api.CheckParams("DeepSeek", request, supportedParams, c.strictParams)
+}
+
 // DeepSeekResponse 定义DeepSeek API的响应结构
 type DeepSeekResponse struct {
 	ID      string `json:"id"`
@@ -256,18 +304,12 @@ type DeepSeekResponse struct {
 	Created int64  `json:"created"`
 	Model   string `json:"model"`
 	Choices []struct {
-		Index   int `json:"index"`
-		Message struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
-		} `json:"message"`
-		FinishReason string `json:"finish_reason"`
+		Index        int               `json:"index"`
+		Message      DeepSeekMessage   `json:"message"`
+		FinishReason string            `json:"finish_reason"`
+		Logprobs     *DeepSeekLogprobs `json:"logprobs,omitempty"`
 	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage"`
+	Usage DeepSeekUsage `json:"usage"`
 }
 
 // DeepSeekStreamResponse 定义DeepSeek API的流式响应结构
@@ -277,13 +319,48 @@ type DeepSeekStreamResponse struct {
 	Created int64  `json:"created"`
 	Model   string `json:"model"`
 	Choices []struct {
-		Index int `json:"index"`
-		Delta struct {
-			Content string `json:"content,omitempty"`
-			Role    string `json:"role,omitempty"`
-		} `json:"delta"`
-		FinishReason string `json:"finish_reason,omitempty"`
+		Index        int               `json:"index"`
+		Delta        DeepSeekMessage   `json:"delta"`
+		FinishReason string            `json:"finish_reason,omitempty"`
+		Logprobs     *DeepSeekLogprobs `json:"logprobs,omitempty"`
 	} `json:"choices"`
+	// Usage 仅在开启stream_options.include_usage时出现在最后一个数据块中
+	Usage *DeepSeekUsage `json:"usage,omitempty"`
+}
+
+// DeepSeekMessage 定义DeepSeek的消息结构
+type DeepSeekMessage struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content"`
+	// ReasoningContent deepseek-reasoner输出的思维链内容
+	ReasoningContent string         `json:"reasoning_content,omitempty"`
+	ToolCalls        []api.ToolCall `json:"tool_calls,omitempty"`
+}
+
+// DeepSeekLogprobs 定义候选中输出token的对数概率
+type DeepSeekLogprobs struct {
+	Content []api.TokenLogprob `json:"content"`
+}
+
+// tokens 返回各token的对数概率，未返回对数概率时为nil
+func (l *DeepSeekLogprobs) tokens() []api.TokenLogprob {
+	if l == nil {
+		return nil
+	}
+	return l.Content
+}
+
+// DeepSeekUsage 定义DeepSeek的令牌使用情况
+type DeepSeekUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+	// PromptCacheHitTokens 命中上下文硬盘缓存的token数，与未命中部分之和为PromptTokens
+	PromptCacheHitTokens    int `json:"prompt_cache_hit_tokens"`
+	PromptCacheMissTokens   int `json:"prompt_cache_miss_tokens"`
+	CompletionTokensDetails struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details"`
 }
 
 // DeepSeekError 定义DeepSeek API的错误响应
@@ -301,7 +378,7 @@ func adaptRequest(request *api.Request) map[string]interface{} {
 	// DeepSeek的API格式与OpenAI类似，这里可以直接适配
 	req := map[string]interface{}{
 		"model":    request.Model,
-		"messages": request.Messages,
+		"messages": adaptMessages(request.Messages),
 	}
 
 	// 添加可选参数
@@ -323,8 +400,29 @@ func adaptRequest(request *api.Request) map[string]interface{} {
 	if len(request.Stop) > 0 {
 		req["stop"] = request.Stop
 	}
+	if request.Logprobs {
+		req["logprobs"] = true
+		if request.TopLogprobs > 0 {
+			req["top_logprobs"] = request.TopLogprobs
+		}
+	}
 	if request.Stream {
 		req["stream"] = request.Stream
+		// 让最后一个数据块携带令牌用量
+		req["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
+
+	// 函数调用
+	if len(request.Tools) > 0 {
+		req["tools"] = request.Tools
+	}
+	if request.ToolChoice != nil {
+		req["tool_choice"] = request.ToolChoice
+	}
+
+	// JSON模式，DeepSeek只支持text和json_object
+	if request.ResponseFormat != nil {
+		req["response_format"] = map[string]interface{}{"type": request.ResponseFormat.Type}
 	}
 
 	// 添加其他自定义参数
@@ -335,17 +433,62 @@ func adaptRequest(request *api.Request) map[string]interface{} {
 	return req
 }
 
+// 将SDK的消息转换为DeepSeek的消息格式
+//
+// DeepSeek要求历史消息中不能包含reasoning_content，否则返回400，
+// 因此这里只保留角色、内容和工具调用相关字段，丢弃之前轮次的思维链。
+func adaptMessages(messages []api.Message) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(messages))
+	for _, msg := range messages {
+		m := map[string]interface{}{
+			"role":    string(msg.Role),
+			"content": msg.Content,
+		}
+		if len(msg.ToolCalls) > 0 {
+			m["tool_calls"] = msg.ToolCalls
+		}
+		if msg.ToolCallID != "" {
+			m["tool_call_id"] = msg.ToolCallID
+		}
+		if msg.Name != "" {
+			m["name"] = msg.Name
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+// 将DeepSeek的消息转换为SDK的通用格式
+func adaptMessage(msg DeepSeekMessage) api.Message {
+	return api.Message{
+		Role:             api.Role(msg.Role),
+		Content:          msg.Content,
+		ReasoningContent: msg.ReasoningContent,
+		ToolCalls:        msg.ToolCalls,
+	}
+}
+
+// 将DeepSeek的用量转换为SDK的通用格式
+func adaptUsage(usage DeepSeekUsage) api.Usage {
+	return api.Usage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+		ReasoningTokens:  usage.CompletionTokensDetails.ReasoningTokens,
+		CacheReadTokens:  usage.PromptCacheHitTokens,
+	}
+}
+
 // 将DeepSeek的响应格式转换为SDK的通用格式
 func adaptResponse(deepseekResp *DeepSeekResponse) *api.Response {
 	choices := make([]api.Choice, len(deepseekResp.Choices))
 	for i, choice := range deepseekResp.Choices {
 		choices[i] = api.Choice{
-			Index: choice.Index,
-			Message: api.Message{
-				Role:    api.Role(choice.Message.Role),
-				Content: choice.Message.Content,
-			},
-			FinishReason: choice.FinishReason,
+			Index:           choice.Index,
+			Message:         adaptMessage(choice.Message),
+			FinishReason:    mapFinishReason(choice.FinishReason),
+			RawFinishReason: choice.FinishReason,
+			Logprobs:        choice.Logprobs.tokens(),
 		}
 	}
 
@@ -355,12 +498,17 @@ func adaptResponse(deepseekResp *DeepSeekResponse) *api.Response {
 		Created: deepseekResp.Created,
 		Model:   deepseekResp.Model,
 		Choices: choices,
-		Usage: api.Usage{
-			PromptTokens:     deepseekResp.Usage.PromptTokens,
-			CompletionTokens: deepseekResp.Usage.CompletionTokens,
-			TotalTokens:      deepseekResp.Usage.TotalTokens,
-		},
+		Usage:   adaptUsage(deepseekResp.Usage),
+	}
+}
+
+// 将DeepSeek的结束原因映射为标准值
+func mapFinishReason(reason string) api.FinishReason {
+	// insufficient_system_resource 表示推理资源不足导致生成中断
+	if reason == "insufficient_system_resource" {
+		return api.FinishReasonError
 	}
+	return api.NormalizeFinishReason(reason)
 }
 
 // 将DeepSeek的错误映射到SDK的错误类型
@@ -388,28 +536,21 @@ func mapDeepSeekError(deepseekErr *DeepSeekError, statusCode int) *api.Error {
 
 // deepseekResponseStream 实现流式响应接口
 type deepseekResponseStream struct {
-	reader    *utils.SSEReader
+	*utils.StreamDecoder
 	rawReader io.ReadCloser
 }
 
-// Recv 实现ResponseStream接口，读取下一个响应块
-func (s *deepseekResponseStream) Recv() (*api.ResponseChunk, error) {
-	event, err := s.reader.ReadEvent()
-	if err != nil {
-		if err == io.EOF {
-			return nil, io.EOF
-		}
-		return nil, api.NewError(api.ErrorTypeServer, "读取SSE事件失败", 0, err)
-	}
-
-	// 如果不是data字段，或者数据为空，则跳过
-	if event.Data == "" || event.Data == "[DONE]" {
-		if event.Data == "[DONE]" {
-			return nil, io.EOF
-		}
-		return s.Recv() // 递归调用直到获取到有效数据或EOF
+// parseStreamError 解析流中途返回的错误，数据不是错误时返回nil
+func parseStreamError(data []byte) *api.Error {
+	var deepseekErr DeepSeekError
+	if err := json.Unmarshal(data, &deepseekErr); err != nil || deepseekErr.Error.Message == "" {
+		return nil
 	}
+	return mapDeepSeekError(&deepseekErr, 0)
+}
 
+// decodeStreamEvent 将一个SSE事件解码为响应块
+func decodeStreamEvent(event *utils.SSEEvent) (*api.ResponseChunk, error) {
 	// 解析JSON数据
 	var streamResp DeepSeekStreamResponse
 	if err := json.Unmarshal([]byte(utils.ParseSSEData(event.Data)), &streamResp); err != nil {
@@ -420,22 +561,27 @@ func (s *deepseekResponseStream) Recv() (*api.ResponseChunk, error) {
 	choices := make([]api.ChunkChoice, len(streamResp.Choices))
 	for i, choice := range streamResp.Choices {
 		choices[i] = api.ChunkChoice{
-			Index: choice.Index,
-			Delta: api.Message{
-				Role:    api.Role(choice.Delta.Role),
-				Content: choice.Delta.Content,
-			},
-			FinishReason: choice.FinishReason,
+			Index:           choice.Index,
+			Delta:           adaptMessage(choice.Delta),
+			FinishReason:    mapFinishReason(choice.FinishReason),
+			RawFinishReason: choice.FinishReason,
+			Logprobs:        choice.Logprobs.tokens(),
 		}
 	}
 
-	return &api.ResponseChunk{
+	chunk := &api.ResponseChunk{
 		ID:      streamResp.ID,
 		Object:  streamResp.Object,
 		Created: streamResp.Created,
 		Model:   streamResp.Model,
 		Choices: choices,
-	}, nil
+	}
+	if streamResp.Usage != nil {
+		usage := adaptUsage(*streamResp.Usage)
+		chunk.Usage = &usage
+	}
+
+	return chunk, nil
 }
 
 // Close 关闭流
@@ -0,0 +1,54 @@
+package deepseek
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/utils"
+)
+
+// DeepSeekModel 定义/models接口返回的模型
+type DeepSeekModel struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// DeepSeekModelList 定义/models接口的响应
+type DeepSeekModelList struct {
+	Object string          `json:"object"`
+	Data   []DeepSeekModel `json:"data"`
+}
+
+// ListModels 实现api.ModelLister接口，返回/models接口列出的模型
+func (c *Client) ListModels(ctx context.Context) ([]api.Model, error) {
+	config := utils.DefaultHTTPConfig()
+	config.MaxRetries = c.maxRetries
+	body, statusCode, err := utils.DoHTTPRequest(ctx, c.httpClient, http.MethodGet, c.baseURL+"/models", nil, utils.MakeAuthHeader(c.apiKey, "bearer"), config)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查HTTP状态码
+	if statusCode != http.StatusOK {
+		var deepseekErr DeepSeekError
+		if err := json.Unmarshal(body, &deepseekErr); err != nil {
+			return nil, api.NewError(api.ErrorTypeServer, fmt.Sprintf("API错误(状态码: %d)", statusCode), statusCode, nil)
+		}
+		return nil, mapDeepSeekError(&deepseekErr, statusCode)
+	}
+
+	var list DeepSeekModelList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", statusCode, err)
+	}
+
+	result := make([]api.Model, len(list.Data))
+	for i, model := range list.Data {
+		result[i] = api.Model{ID: model.ID, OwnedBy: model.OwnedBy}
+	}
+	return result, nil
+}
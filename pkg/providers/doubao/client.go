@@ -0,0 +1,533 @@
+package doubao
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/utils"
+)
+
+// Client 实现了字节跳动豆包（火山引擎方舟）的API客户端
+//
+// 方舟通过推理接入点ID（ep-xxxx）寻址模型。请求中的Model既可以直接填写接入点ID，
+// 也可以填写模型名，再通过WithEndpoint把模型名映射到接入点ID。
+type Client struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	// streamClient 用于流式请求，不设置总超时，由streamTimeouts分阶段控制
+	streamClient   *http.Client
+	streamTimeouts api.StreamTimeouts
+	// strictParams 为true时不支持的参数返回错误，否则忽略并在响应中附带警告
+	strictParams bool
+	endpoints    map[string]string
+}
+
+// 默认配置
+const (
+	defaultBaseURL    = "https://ark.cn-beijing.volces.com/api/v3"
+	defaultTimeout    = 60 * time.Second
+	defaultMaxRetries = 3
+)
+
+// Extra配置键
+const (
+	// extraEndpoints 模型名到接入点ID的映射，类型为map[string]string
+	extraEndpoints = "doubao.endpoints"
+	// embeddingModelKey 嵌入模型在映射中的键
+	embeddingModelKey = "doubao.embedding"
+)
+
+// WithEndpoint 将模型名映射到方舟推理接入点ID
+func WithEndpoint(model, endpointID string) api.ClientOption {
+	return func(options *api.ClientOptions) {
+		if options.Extra == nil {
+			options.Extra = map[string]interface{}{}
+		}
+		endpoints, _ := options.Extra[extraEndpoints].(map[string]string)
+		if endpoints == nil {
+			endpoints = map[string]string{}
+			options.Extra[extraEndpoints] = endpoints
+		}
+		endpoints[model] = endpointID
+	}
+}
+
+// WithEmbeddingEndpoint 设置Embedding使用的嵌入模型接入点ID
+func WithEmbeddingEndpoint(endpointID string) api.ClientOption {
+	return WithEndpoint(embeddingModelKey, endpointID)
+}
+
+// NewClient 创建一个新的豆包客户端
+func NewClient(options ...api.ClientOption) (api.LLMClient, error) {
+	clientOptions := &api.ClientOptions{
+		BaseURL:    defaultBaseURL,
+		Timeout:    int(defaultTimeout.Seconds()),
+		MaxRetries: defaultMaxRetries,
+	}
+
+	// 应用选项
+	for _, option := range options {
+		option(clientOptions)
+	}
+
+	// 验证必要的配置
+	if clientOptions.APIKey == "" {
+		return nil, api.NewError(api.ErrorTypeAuthentication, "API密钥不能为空", 0, nil)
+	}
+
+	// 创建HTTP客户端
+	httpClient := &http.Client{
+		Timeout: time.Duration(clientOptions.Timeout) * time.Second,
+	}
+	if clientOptions.HTTPClient != nil {
+		if client, ok := clientOptions.HTTPClient.(*http.Client); ok {
+			httpClient = client
+		}
+	}
+
+	endpoints, _ := clientOptions.Extra[extraEndpoints].(map[string]string)
+
+	return &Client{
+		apiKey:         clientOptions.APIKey,
+		baseURL:        clientOptions.BaseURL,
+		httpClient:     httpClient,
+		streamClient:   utils.NewStreamClient(httpClient),
+		streamTimeouts: clientOptions.StreamTimeouts.WithDefaults(time.Duration(clientOptions.Timeout) * time.Second),
+		strictParams:   clientOptions.StrictParams,
+		maxRetries:     clientOptions.MaxRetries,
+		endpoints:      endpoints,
+	}, nil
+}
+
+// Complete 发送请求并获取完整的响应
+func (c *Client) Complete(ctx context.Context, request *api.Request) (*api.Response, error) {
+	// 验证请求
+	if err := validateRequest(request); err != nil {
+		return nil, err
+	}
+
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.post(ctx, "/chat/completions", adaptRequest(request, c.resolveModel(request.Model)))
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析响应
+	var doubaoResp DoubaoResponse
+	if err := json.Unmarshal(body, &doubaoResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", http.StatusOK, err)
+	}
+
+	response := adaptResponse(&doubaoResp, request.Model)
+	response.Warnings = warnings
+	return response, nil
+}
+
+// CompleteStream 发送请求并获取流式响应
+func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.ResponseStream, error) {
+	// 验证请求
+	if err := validateRequest(request); err != nil {
+		return nil, err
+	}
+
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// 设置流式标志
+	reqCopy := *request
+	reqCopy.Stream = true
+
+	resp, err := c.doRequest(ctx, "/chat/completions", adaptRequest(&reqCopy, c.resolveModel(request.Model)), true)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查HTTP状态码
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, parseErrorBody(body, resp.StatusCode)
+	}
+
+	stream := &doubaoResponseStream{
+		rawReader: resp.Body,
+		model:     request.Model,
+	}
+	stream.StreamDecoder = utils.NewStreamDecoder(resp.Body, stream.decode, func(options *utils.StreamDecoderOptions) {
+		options.DoneData = "[DONE]"
+		options.ParseError = parseStreamError
+		options.InlineErrors = true
+	})
+	return utils.WithWarnings(stream, warnings), nil
+}
+
+// Embedding 获取文本的嵌入向量，需要先通过WithEmbeddingEndpoint配置接入点
+func (c *Client) Embedding(ctx context.Context, input string) ([]float32, error) {
+	endpointID := c.endpoints[embeddingModelKey]
+	if endpointID == "" {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "未配置嵌入模型的接入点ID", 0, nil)
+	}
+
+	body, err := c.post(ctx, "/embeddings", map[string]interface{}{
+		"model": endpointID,
+		"input": []string{input},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析嵌入响应
+	var embedResp struct {
+		Data []struct {
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &embedResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析嵌入响应失败", http.StatusOK, err)
+	}
+
+	if len(embedResp.Data) == 0 || len(embedResp.Data[0].Embedding) == 0 {
+		return nil, api.NewError(api.ErrorTypeServer, "未收到有效的嵌入结果", http.StatusOK, nil)
+	}
+
+	return embedResp.Data[0].Embedding, nil
+}
+
+// resolveModel 将模型名解析为接入点ID，未配置映射时原样返回
+func (c *Client) resolveModel(model string) string {
+	if endpointID, ok := c.endpoints[model]; ok {
+		return endpointID
+	}
+	return model
+}
+
+// post 发送JSON请求并返回成功响应的响应体
+func (c *Client) post(ctx context.Context, path string, payload interface{}) ([]byte, error) {
+	resp, err := c.doRequest(ctx, path, payload, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// 读取响应
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "读取响应失败", resp.StatusCode, err)
+	}
+
+	// 检查HTTP状态码
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseErrorBody(body, resp.StatusCode)
+	}
+
+	return body, nil
+}
+
+// doRequest 构造并发送HTTP请求
+func (c *Client) doRequest(ctx context.Context, path string, payload interface{}, stream bool) (*http.Response, error) {
+	// 准备请求体
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "无法序列化请求", 0, err)
+	}
+
+	// 创建HTTP请求
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "创建HTTP请求失败", 0, err)
+	}
+
+	// 设置请求头
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	// 流式请求分阶段控制超时
+	if stream {
+		return utils.DoStreamRequest(c.streamClient, req, c.streamTimeouts)
+	}
+
+	// 发送请求
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "HTTP请求失败", 0, err)
+	}
+	return resp, nil
+}
+
+// 验证请求参数
+func validateRequest(request *api.Request) error {
+	if request == nil {
+		return api.NewError(api.ErrorTypeInvalidRequest, "请求不能为空", 0, nil)
+	}
+	if request.Model == "" {
+		return api.NewError(api.ErrorTypeInvalidRequest, "模型不能为空", 0, nil)
+	}
+	if len(request.Messages) == 0 {
+		return api.NewError(api.ErrorTypeInvalidRequest, "消息不能为空", 0, nil)
+	}
+	return nil
+}
+
+// supportedParams 豆包支持的可选参数
+var supportedParams = api.NewParamSet(
+	api.ParamTemperature, api.ParamTopP, api.ParamMaxTokens, api.ParamPresencePenalty, api.ParamFrequencyPenalty, api.ParamStop,
+	api.ParamLogitBias, api.ParamTools, api.ParamToolChoice, api.ParamResponseFormat,
+)
+
+// 检查请求中豆包不支持的参数，非严格模式下返回忽略这些参数的警告
+func (c *Client) checkParams(request *api.Request) ([]string, error) {
+	return api.CheckParams("豆包", request, supportedParams, c.strictParams)
+}
+
+// DoubaoResponse 定义方舟API的响应结构
+type DoubaoResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index        int               `json:"index"`
+		Message      utils.ChatMessage `json:"message"`
+		FinishReason string            `json:"finish_reason"`
+	} `json:"choices"`
+	Usage DoubaoUsage `json:"usage"`
+}
+
+// DoubaoUsage 定义方舟的令牌用量
+type DoubaoUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// 将方舟的令牌用量转换为SDK格式
+func adaptUsage(usage DoubaoUsage) api.Usage {
+	return api.Usage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+	}
+}
+
+// DoubaoStreamResponse 定义方舟API的流式响应结构
+type DoubaoStreamResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index        int               `json:"index"`
+		Delta        utils.ChatMessage `json:"delta"`
+		FinishReason string            `json:"finish_reason,omitempty"`
+	} `json:"choices"`
+	// Usage 仅在开启stream_options.include_usage时出现在最后一个数据块中
+	Usage *DoubaoUsage `json:"usage,omitempty"`
+}
+
+// DoubaoError 定义方舟API的错误响应
+type DoubaoError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Param   string `json:"param"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
+
+// 将SDK的请求格式转换为方舟的格式，model为解析后的接入点ID
+func adaptRequest(request *api.Request, model string) map[string]interface{} {
+	// 方舟的API格式与OpenAI兼容
+	req := map[string]interface{}{
+		"model":    model,
+		"messages": utils.NewChatMessages(request.Messages),
+	}
+
+	// 添加可选参数
+	if request.Temperature != nil {
+		req["temperature"] = *request.Temperature
+	}
+	if request.TopP != nil {
+		req["top_p"] = *request.TopP
+	}
+	if request.MaxTokens != nil {
+		req["max_tokens"] = *request.MaxTokens
+	}
+	if request.PresencePenalty != nil {
+		req["presence_penalty"] = *request.PresencePenalty
+	}
+	if request.FrequencyPenalty != nil {
+		req["frequency_penalty"] = *request.FrequencyPenalty
+	}
+	if len(request.Stop) > 0 {
+		req["stop"] = request.Stop
+	}
+	if len(request.LogitBias) > 0 {
+		req["logit_bias"] = request.LogitBias
+	}
+	if request.Stream {
+		req["stream"] = request.Stream
+		// 让最后一个数据块携带令牌用量
+		req["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
+
+	// 函数调用
+	if len(request.Tools) > 0 {
+		req["tools"] = request.Tools
+	}
+	if request.ToolChoice != nil {
+		req["tool_choice"] = request.ToolChoice
+	}
+
+	// JSON模式
+	if request.ResponseFormat != nil {
+		req["response_format"] = request.ResponseFormat
+	}
+
+	// 添加其他自定义参数
+	for k, v := range request.ExtraParams {
+		req[k] = v
+	}
+
+	return req
+}
+
+// 将方舟的响应格式转换为SDK的通用格式
+//
+// 方舟返回的model为底层模型名，这里保留调用方传入的模型名以便与请求对应。
+func adaptResponse(doubaoResp *DoubaoResponse, modelName string) *api.Response {
+	choices := make([]api.Choice, len(doubaoResp.Choices))
+	for i, choice := range doubaoResp.Choices {
+		choices[i] = api.Choice{
+			Index:           choice.Index,
+			Message:         choice.Message.Message(),
+			FinishReason:    api.NormalizeFinishReason(choice.FinishReason),
+			RawFinishReason: choice.FinishReason,
+		}
+	}
+
+	return &api.Response{
+		ID:      doubaoResp.ID,
+		Object:  doubaoResp.Object,
+		Created: doubaoResp.Created,
+		Model:   modelName,
+		Choices: choices,
+		Usage:   adaptUsage(doubaoResp.Usage),
+	}
+}
+
+// parseErrorBody 解析错误响应体
+func parseErrorBody(body []byte, statusCode int) *api.Error {
+	var doubaoErr DoubaoError
+	if err := json.Unmarshal(body, &doubaoErr); err != nil {
+		return api.NewError(api.ErrorTypeServer, fmt.Sprintf("API错误(状态码: %d)", statusCode), statusCode, nil)
+	}
+	return mapDoubaoError(&doubaoErr, statusCode)
+}
+
+// 将方舟的错误映射到SDK的错误类型
+func mapDoubaoError(doubaoErr *DoubaoError, statusCode int) *api.Error {
+	code := doubaoErr.Error.Code
+	errType := api.ErrorTypeUnknown
+	switch {
+	case strings.HasPrefix(code, "AuthenticationError") || strings.HasPrefix(code, "AccessDenied") || code == "InvalidAccountStatus":
+		errType = api.ErrorTypeAuthentication
+	case strings.HasPrefix(code, "RateLimitExceeded") || strings.HasPrefix(code, "QuotaExceeded") || code == "ServerOverloaded":
+		errType = api.ErrorTypeRateLimit
+	case strings.HasPrefix(code, "InvalidParameter") || strings.HasPrefix(code, "InvalidEndpoint") ||
+		strings.HasPrefix(code, "ModelNotOpen") || strings.HasPrefix(code, "SensitiveContentDetected") ||
+		strings.HasPrefix(code, "MissingParameter"):
+		errType = api.ErrorTypeInvalidRequest
+	case code == "InternalServiceError":
+		errType = api.ErrorTypeServer
+	case statusCode == 400 || statusCode == 404:
+		errType = api.ErrorTypeInvalidRequest
+	case statusCode == 401 || statusCode == 403:
+		errType = api.ErrorTypeAuthentication
+	case statusCode == 429:
+		errType = api.ErrorTypeRateLimit
+	case statusCode >= 500:
+		errType = api.ErrorTypeServer
+	}
+
+	return &api.Error{
+		Type:       errType,
+		Message:    doubaoErr.Error.Message,
+		StatusCode: statusCode,
+		Param:      doubaoErr.Error.Param,
+		Code:       code,
+	}
+}
+
+// doubaoResponseStream 实现流式响应接口
+type doubaoResponseStream struct {
+	*utils.StreamDecoder
+	rawReader io.ReadCloser
+	model     string
+}
+
+// parseStreamError 解析流中途返回的错误，数据不是错误时返回nil
+func parseStreamError(data []byte) *api.Error {
+	var doubaoErr DoubaoError
+	if err := json.Unmarshal(data, &doubaoErr); err != nil || doubaoErr.Error.Code == "" {
+		return nil
+	}
+	return mapDoubaoError(&doubaoErr, 0)
+}
+
+// decode 将一个SSE事件解码为响应块
+func (s *doubaoResponseStream) decode(event *utils.SSEEvent) (*api.ResponseChunk, error) {
+	// 解析JSON数据
+	var streamResp DoubaoStreamResponse
+	if err := json.Unmarshal([]byte(utils.ParseSSEData(event.Data)), &streamResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析流式响应失败", 0, err)
+	}
+
+	// 转换为SDK的通用格式
+	choices := make([]api.ChunkChoice, len(streamResp.Choices))
+	for i, choice := range streamResp.Choices {
+		choices[i] = api.ChunkChoice{
+			Index:           choice.Index,
+			Delta:           choice.Delta.Message(),
+			FinishReason:    api.NormalizeFinishReason(choice.FinishReason),
+			RawFinishReason: choice.FinishReason,
+		}
+	}
+
+	chunk := &api.ResponseChunk{
+		ID:      streamResp.ID,
+		Object:  streamResp.Object,
+		Created: streamResp.Created,
+		Model:   s.model,
+		Choices: choices,
+	}
+	if streamResp.Usage != nil {
+		usage := adaptUsage(*streamResp.Usage)
+		chunk.Usage = &usage
+	}
+	return chunk, nil
+}
+
+// Close 关闭流
+func (s *doubaoResponseStream) Close() error {
+	return s.rawReader.Close()
+}
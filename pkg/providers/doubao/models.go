@@ -0,0 +1,24 @@
+package doubao
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// ListModels 实现api.ModelLister接口，返回通过WithEndpoint映射了接入点的模型
+//
+// 方舟的推理接口按接入点寻址，没有列出模型的接口，能通过该客户端调用的模型就是已映射的模型。
+// 嵌入模型（WithEmbeddingEndpoint）不包含在内，结果按模型名排序。
+func (c *Client) ListModels(_ context.Context) ([]api.Model, error) {
+	result := make([]api.Model, 0, len(c.endpoints))
+	for model := range c.endpoints {
+		if model == embeddingModelKey {
+			continue
+		}
+		result = append(result, api.Model{ID: model, OwnedBy: "volcengine"})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, nil
+}
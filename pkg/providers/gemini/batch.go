@@ -0,0 +1,339 @@
+package gemini
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// Gemini批处理任务状态
+const (
+	BatchStatePending   = "BATCH_STATE_PENDING"
+	BatchStateRunning   = "BATCH_STATE_RUNNING"
+	BatchStateSucceeded = "BATCH_STATE_SUCCEEDED"
+	BatchStateFailed    = "BATCH_STATE_FAILED"
+	BatchStateCancelled = "BATCH_STATE_CANCELLED"
+	BatchStateExpired   = "BATCH_STATE_EXPIRED"
+)
+
+// BatchClient 返回基于batchGenerateContent接口的统一批处理客户端
+//
+// 同一批次中的请求必须使用相同的模型，结果只支持以内联方式返回。
+// 批处理接口仅在v1beta版本提供，默认BaseURL以/v1结尾时会自动切换到/v1beta。
+func (c *Client) BatchClient() api.BatchClient {
+	return &batchClient{client: c}
+}
+
+// batchClient 将Gemini的批处理接口适配为api.BatchClient
+type batchClient struct {
+	client *Client
+}
+
+// GeminiBatch 定义Gemini批处理任务（以长时间运行操作的形式返回）
+type GeminiBatch struct {
+	Name     string              `json:"name"`
+	Done     bool                `json:"done"`
+	Metadata GeminiBatchMetadata `json:"metadata"`
+	Response *GeminiBatchOutput  `json:"response,omitempty"`
+	Error    *GeminiStatus       `json:"error,omitempty"`
+}
+
+// GeminiBatchMetadata 定义批处理任务的元数据
+type GeminiBatchMetadata struct {
+	Model       string    `json:"model"`
+	DisplayName string    `json:"displayName"`
+	State       string    `json:"state"`
+	CreateTime  time.Time `json:"createTime"`
+	UpdateTime  time.Time `json:"updateTime"`
+	EndTime     time.Time `json:"endTime"`
+	BatchStats  struct {
+		RequestCount           json.Number `json:"requestCount"`
+		SuccessfulRequestCount json.Number `json:"successfulRequestCount"`
+		FailedRequestCount     json.Number `json:"failedRequestCount"`
+		PendingRequestCount    json.Number `json:"pendingRequestCount"`
+	} `json:"batchStats"`
+	Output *GeminiBatchOutput `json:"output,omitempty"`
+}
+
+// GeminiBatchOutput 定义批处理任务的输出
+type GeminiBatchOutput struct {
+	// ResponsesFile 结果以文件形式返回时的文件名
+	ResponsesFile    string `json:"responsesFile,omitempty"`
+	InlinedResponses *struct {
+		InlinedResponses []GeminiInlinedResponse `json:"inlinedResponses"`
+	} `json:"inlinedResponses,omitempty"`
+}
+
+// GeminiInlinedResponse 定义内联返回的单个结果
+type GeminiInlinedResponse struct {
+	Response *GeminiResponse `json:"response,omitempty"`
+	Error    *GeminiStatus   `json:"error,omitempty"`
+	Metadata struct {
+		Key string `json:"key"`
+	} `json:"metadata"`
+}
+
+// GeminiStatus 定义Google API的状态结构
+type GeminiStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+}
+
+// Submit 提交一批请求
+func (b *batchClient) Submit(ctx context.Context, requests []api.BatchRequest) (*api.BatchJob, error) {
+	if len(requests) == 0 {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "批处理请求不能为空", 0, nil)
+	}
+
+	model := ""
+	items := make([]map[string]interface{}, 0, len(requests))
+	for i, item := range requests {
+		if err := validateRequest(item.Request); err != nil {
+			return nil, err
+		}
+		if model == "" {
+			model = item.Request.Model
+		} else if item.Request.Model != model {
+			return nil, api.NewError(api.ErrorTypeInvalidRequest, fmt.Sprintf("Gemini批处理中的请求必须使用相同的模型，第%d个请求使用了%s", i+1, item.Request.Model), 0, nil)
+		}
+
+		customID := item.CustomID
+		if customID == "" {
+			customID = fmt.Sprintf("request-%d", i)
+		}
+		items = append(items, map[string]interface{}{
+			"request":  adaptRequest(item.Request),
+			"metadata": map[string]string{"key": customID},
+		})
+	}
+
+	payload := map[string]interface{}{
+		"batch": map[string]interface{}{
+			"display_name": fmt.Sprintf("llm-sdk-%d", time.Now().Unix()),
+			"input_config": map[string]interface{}{
+				"requests": map[string]interface{}{"requests": items},
+			},
+		},
+	}
+
+	var batch GeminiBatch
+	if err := b.client.batchJSON(ctx, http.MethodPost, "models/"+model+":batchGenerateContent", payload, &batch); err != nil {
+		return nil, err
+	}
+	return adaptBatchJob(&batch), nil
+}
+
+// Status 查询任务状态，jobID为batches/xxx形式的任务名
+func (b *batchClient) Status(ctx context.Context, jobID string) (*api.BatchJob, error) {
+	batch, err := b.get(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return adaptBatchJob(batch), nil
+}
+
+// Cancel 取消任务
+func (b *batchClient) Cancel(ctx context.Context, jobID string) error {
+	if jobID == "" {
+		return api.NewError(api.ErrorTypeInvalidRequest, "批处理ID不能为空", 0, nil)
+	}
+	var empty struct{}
+	return b.client.batchJSON(ctx, http.MethodPost, jobID+":cancel", nil, &empty)
+}
+
+// Results 读取已结束任务的内联结果
+func (b *batchClient) Results(ctx context.Context, jobID string) (api.BatchResultIterator, error) {
+	batch, err := b.get(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if !batch.Done {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, fmt.Sprintf("批处理任务%s尚未结束", jobID), 0, nil)
+	}
+	if batch.Error != nil {
+		return nil, mapGeminiStatus(batch.Error, 0)
+	}
+
+	output := batch.Response
+	if output == nil {
+		output = batch.Metadata.Output
+	}
+	if output == nil || output.InlinedResponses == nil {
+		if output != nil && output.ResponsesFile != "" {
+			return nil, api.NewError(api.ErrorTypeInvalidRequest, "批处理结果以文件形式返回，暂不支持读取: "+output.ResponsesFile, 0, nil)
+		}
+		return nil, api.NewError(api.ErrorTypeServer, fmt.Sprintf("批处理任务%s没有可读取的结果", jobID), 0, nil)
+	}
+
+	return &batchResultIterator{
+		responses: output.InlinedResponses.InlinedResponses,
+		model:     strings.TrimPrefix(batch.Metadata.Model, "models/"),
+	}, nil
+}
+
+// get 查询批处理任务
+func (b *batchClient) get(ctx context.Context, jobID string) (*GeminiBatch, error) {
+	if jobID == "" {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "批处理ID不能为空", 0, nil)
+	}
+	var batch GeminiBatch
+	if err := b.client.batchJSON(ctx, http.MethodGet, jobID, nil, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// batchResultIterator 遍历内联返回的结果
+type batchResultIterator struct {
+	responses []GeminiInlinedResponse
+	model     string
+	pos       int
+}
+
+// Next 读取下一条结果
+func (it *batchResultIterator) Next() (*api.BatchResult, error) {
+	if it.pos >= len(it.responses) {
+		return nil, io.EOF
+	}
+	item := it.responses[it.pos]
+	it.pos++
+
+	result := &api.BatchResult{CustomID: item.Metadata.Key}
+	switch {
+	case item.Error != nil:
+		result.Error = mapGeminiStatus(item.Error, 0)
+	case item.Response != nil:
+		if err := promptBlockedError(item.Response.PromptFeedback); err != nil {
+			result.Error = err
+		} else {
+			result.Response = adaptResponse(item.Response, it.model)
+		}
+	}
+	return result, nil
+}
+
+// Close 实现api.BatchResultIterator接口
+func (it *batchResultIterator) Close() error {
+	return nil
+}
+
+// 将Gemini批处理任务转换为统一的任务结构
+func adaptBatchJob(batch *GeminiBatch) *api.BatchJob {
+	stats := batch.Metadata.BatchStats
+	job := &api.BatchJob{
+		ID:        batch.Name,
+		RawStatus: batch.Metadata.State,
+		Total:     numberToInt(stats.RequestCount),
+		Succeeded: numberToInt(stats.SuccessfulRequestCount),
+		Failed:    numberToInt(stats.FailedRequestCount),
+		CreatedAt: batch.Metadata.CreateTime,
+	}
+
+	switch batch.Metadata.State {
+	case BatchStateRunning:
+		job.Status = api.BatchStatusInProgress
+	case BatchStateSucceeded:
+		job.Status = api.BatchStatusCompleted
+	case BatchStateFailed:
+		job.Status = api.BatchStatusFailed
+	case BatchStateCancelled:
+		job.Status = api.BatchStatusCancelled
+	case BatchStateExpired:
+		job.Status = api.BatchStatusExpired
+	default:
+		job.Status = api.BatchStatusPending
+	}
+
+	if job.Done() {
+		job.EndedAt = batch.Metadata.EndTime
+		if job.EndedAt.IsZero() {
+			job.EndedAt = batch.Metadata.UpdateTime
+		}
+	}
+	return job
+}
+
+// numberToInt 将int64字符串形式的计数转换为int，无法解析时返回0
+func numberToInt(n json.Number) int {
+	v, err := n.Int64()
+	if err != nil {
+		return 0
+	}
+	return int(v)
+}
+
+// 将Google API的状态转换为SDK错误
+func mapGeminiStatus(status *GeminiStatus, statusCode int) *api.Error {
+	var geminiErr GeminiError
+	geminiErr.Error.Code = status.Code
+	geminiErr.Error.Message = status.Message
+	geminiErr.Error.Status = status.Status
+	return mapGeminiError(&geminiErr, statusCode)
+}
+
+// batchBaseURL 返回批处理接口使用的基础URL
+func (c *Client) batchBaseURL() string {
+	if strings.HasSuffix(c.baseURL, "/v1") {
+		return strings.TrimSuffix(c.baseURL, "/v1") + "/v1beta"
+	}
+	return c.baseURL
+}
+
+// batchJSON 向批处理接口发送请求并把成功的响应解析到out中
+func (c *Client) batchJSON(ctx context.Context, method, resource string, payload interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return api.NewError(api.ErrorTypeInvalidRequest, "无法序列化请求", 0, err)
+		}
+		reqBody = bytes.NewBuffer(data)
+	}
+
+	// 创建URL，包含API密钥
+	endpoint := fmt.Sprintf("%s/%s?key=%s", c.batchBaseURL(), resource, c.apiKey)
+
+	// 创建HTTP请求
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return api.NewError(api.ErrorTypeConnection, "创建HTTP请求失败", 0, err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	// 发送请求
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return api.NewError(api.ErrorTypeConnection, "HTTP请求失败", 0, err)
+	}
+	defer resp.Body.Close()
+
+	// 读取响应
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return api.NewError(api.ErrorTypeServer, "读取响应失败", resp.StatusCode, err)
+	}
+
+	// 检查HTTP状态码
+	if resp.StatusCode != http.StatusOK {
+		var geminiErr GeminiError
+		if err := json.Unmarshal(body, &geminiErr); err != nil {
+			return api.NewError(api.ErrorTypeServer, fmt.Sprintf("API错误(状态码: %d)", resp.StatusCode), resp.StatusCode, nil)
+		}
+		return mapGeminiError(&geminiErr, resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return api.NewError(api.ErrorTypeServer, "解析响应失败", resp.StatusCode, err)
+	}
+	return nil
+}
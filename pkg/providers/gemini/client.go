@@ -19,6 +19,11 @@ type Client struct {
 	baseURL    string
 	httpClient *http.Client
 	maxRetries int
+	// streamClient 用于流式请求，不设置总超时，由streamTimeouts分阶段控制
+	streamClient   *http.Client
+	streamTimeouts api.StreamTimeouts
+	// strictParams 为true时不支持的参数返回错误，否则忽略并在响应中附带警告
+	strictParams bool
 }
 
 // 默认配置
@@ -57,10 +62,13 @@ func NewClient(options ...api.ClientOption) (api.LLMClient, error) {
 	}
 
 	return &Client{
-		apiKey:     clientOptions.APIKey,
-		baseURL:    clientOptions.BaseURL,
-		httpClient: httpClient,
-		maxRetries: clientOptions.MaxRetries,
+		apiKey:         clientOptions.APIKey,
+		baseURL:        clientOptions.BaseURL,
+		httpClient:     httpClient,
+		streamClient:   utils.NewStreamClient(httpClient),
+		streamTimeouts: clientOptions.StreamTimeouts.WithDefaults(time.Duration(clientOptions.Timeout) * time.Second),
+		strictParams:   clientOptions.StrictParams,
+		maxRetries:     clientOptions.MaxRetries,
 	}, nil
 }
 
@@ -71,6 +79,12 @@ func (c *Client) Complete(ctx context.Context, request *api.Request) (*api.Respo
 		return nil, err
 	}
 
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
 	// 准备请求体
 	reqBody, err := json.Marshal(adaptRequest(request))
 	if err != nil {
@@ -117,7 +131,14 @@ func (c *Client) Complete(ctx context.Context, request *api.Request) (*api.Respo
 		return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", resp.StatusCode, err)
 	}
 
-	return adaptResponse(&geminiResp, request.Model), nil
+	// 提示被拦截时没有任何候选，直接返回内容过滤错误
+	if err := promptBlockedError(geminiResp.PromptFeedback); err != nil {
+		return nil, err
+	}
+
+	response := adaptResponse(&geminiResp, request.Model)
+	response.Warnings = warnings
+	return response, nil
 }
 
 // CompleteStream 发送请求并获取流式响应
@@ -127,18 +148,21 @@ func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.
 		return nil, err
 	}
 
-	// 设置流式标志
-	reqCopy := *request
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
 
 	// 准备请求体
-	reqBody, err := json.Marshal(adaptStreamRequest(&reqCopy))
+	reqBody, err := json.Marshal(adaptStreamRequest(request))
 	if err != nil {
 		return nil, api.NewError(api.ErrorTypeInvalidRequest, "无法序列化请求", 0, err)
 	}
 
 	// 创建URL，包含API密钥和流参数
 	endpoint := fmt.Sprintf("%s/models/%s:streamGenerateContent?key=%s&alt=sse",
-		c.baseURL, reqCopy.Model, c.apiKey)
+		c.baseURL, request.Model, c.apiKey)
 
 	// 创建HTTP请求
 	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(reqBody))
@@ -151,9 +175,9 @@ func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.
 	req.Header.Set("Accept", "text/event-stream")
 
 	// 发送请求
-	resp, err := c.httpClient.Do(req)
+	resp, err := utils.DoStreamRequest(c.streamClient, req, c.streamTimeouts)
 	if err != nil {
-		return nil, api.NewError(api.ErrorTypeConnection, "HTTP请求失败", 0, err)
+		return nil, err
 	}
 
 	// 检查HTTP状态码
@@ -168,11 +192,15 @@ func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.
 		return nil, mapGeminiError(&geminiErr, resp.StatusCode)
 	}
 
-	return &geminiResponseStream{
-		reader:    utils.NewSSEReader(resp.Body),
+	stream := &geminiResponseStream{
 		rawReader: resp.Body,
 		model:     request.Model,
-	}, nil
+	}
+	stream.StreamDecoder = utils.NewStreamDecoder(resp.Body, stream.decode, func(options *utils.StreamDecoderOptions) {
+		options.ParseError = parseStreamError
+		options.InlineErrors = true
+	})
+	return utils.WithWarnings(stream, warnings), nil
 }
 
 // Embedding 获取文本的嵌入向量
@@ -249,6 +277,49 @@ func validateRequest(request *api.Request) error {
 		return api.NewError(api.ErrorTypeInvalidRequest, "消息不能为空", 0, nil)
 	}
 
+	return validateMessages(request.Messages)
+}
+
+// supportedParams Gemini支持的可选参数
+var supportedParams = api.NewParamSet(
+	api.ParamTemperature, api.ParamTopP, api.ParamTopK, api.ParamMaxTokens, api.ParamPresencePenalty, api.ParamFrequencyPenalty, api.ParamStop,
+	api.ParamN, api.ParamSeed, api.ParamLogprobs, api.ParamTopLogprobs, api.ParamResponseFormat, api.ParamSafetySettings,
+)
+
+// 检查请求中Gemini不支持的参数，非严格模式下返回忽略这些参数的警告
+func (c *Client) checkParams(request *api.Request) ([]string, error) {
+	return api.CheckParams("Gemini", request, supportedParams, c.strictParams)
+}
+
+// 验证消息序列是否能转换为Gemini接受的对话
+//
+// Gemini要求对话由user和model交替组成且以user开始，每个part的文本不能为空；
+// 这里提前给出可定位的错误，而不是等待接口返回400。
+func validateMessages(messages []api.Message) error {
+	firstTurn := true
+	for i, msg := range messages {
+		switch msg.Role {
+		case api.RoleSystem, api.RoleUser, api.RoleAssistant:
+		default:
+			return api.NewError(api.ErrorTypeInvalidRequest, fmt.Sprintf("第%d条消息的角色%q不受Gemini支持", i+1, msg.Role), 0, nil)
+		}
+
+		if msg.Content == "" {
+			return api.NewError(api.ErrorTypeInvalidRequest, fmt.Sprintf("第%d条消息(%s)的内容为空，Gemini不接受空文本", i+1, msg.Role), 0, nil)
+		}
+
+		if msg.Role == api.RoleSystem {
+			continue
+		}
+		if firstTurn && msg.Role != api.RoleUser {
+			return api.NewError(api.ErrorTypeInvalidRequest, fmt.Sprintf("Gemini对话必须以用户消息开始，但第%d条消息的角色为%s", i+1, msg.Role), 0, nil)
+		}
+		firstTurn = false
+	}
+
+	if firstTurn {
+		return api.NewError(api.ErrorTypeInvalidRequest, "至少需要一条用户消息，Gemini不接受只有系统提示的请求", 0, nil)
+	}
 	return nil
 }
 
@@ -263,6 +334,8 @@ type GeminiError struct {
 
 // GeminiResponse 定义Gemini API的响应结构
 type GeminiResponse struct {
+	// ResponseID 响应的ID
+	ResponseID string `json:"responseId,omitempty"`
 	Candidates []struct {
 		Content struct {
 			Parts []struct {
@@ -270,28 +343,26 @@ type GeminiResponse struct {
 			} `json:"parts"`
 			Role string `json:"role"`
 		} `json:"content"`
-		FinishReason  string `json:"finishReason"`
-		Index         int    `json:"index"`
-		SafetyRatings []struct {
-			Category    string `json:"category"`
-			Probability string `json:"probability"`
-		} `json:"safetyRatings,omitempty"`
+		FinishReason   string                `json:"finishReason"`
+		Index          int                   `json:"index"`
+		SafetyRatings  []GeminiSafetyRating  `json:"safetyRatings,omitempty"`
+		LogprobsResult *GeminiLogprobsResult `json:"logprobsResult,omitempty"`
 	} `json:"candidates"`
-	PromptFeedback struct {
-		SafetyRatings []struct {
-			Category    string `json:"category"`
-			Probability string `json:"probability"`
-		} `json:"safetyRatings,omitempty"`
-	} `json:"promptFeedback,omitempty"`
-	UsageMetadata struct {
-		PromptTokenCount     int `json:"promptTokenCount"`
-		CandidatesTokenCount int `json:"candidatesTokenCount"`
-		TotalTokenCount      int `json:"totalTokenCount"`
-	} `json:"usageMetadata,omitempty"`
+	PromptFeedback *GeminiPromptFeedback `json:"promptFeedback,omitempty"`
+	UsageMetadata  GeminiUsageMetadata   `json:"usageMetadata,omitempty"`
+}
+
+// GeminiUsageMetadata 定义Gemini的令牌用量
+type GeminiUsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
 }
 
 // GeminiStreamResponse 定义Gemini API的流式响应结构
 type GeminiStreamResponse struct {
+	// ResponseID 响应的ID，同一次流式响应的所有数据块相同
+	ResponseID string `json:"responseId,omitempty"`
 	Candidates []struct {
 		Content struct {
 			Parts []struct {
@@ -299,42 +370,31 @@ type GeminiStreamResponse struct {
 			} `json:"parts"`
 			Role string `json:"role"`
 		} `json:"content"`
-		FinishReason  string `json:"finishReason"`
-		Index         int    `json:"index"`
-		SafetyRatings []struct {
-			Category    string `json:"category"`
-			Probability string `json:"probability"`
-		} `json:"safetyRatings,omitempty"`
+		FinishReason   string                `json:"finishReason"`
+		Index          int                   `json:"index"`
+		SafetyRatings  []GeminiSafetyRating  `json:"safetyRatings,omitempty"`
+		LogprobsResult *GeminiLogprobsResult `json:"logprobsResult,omitempty"`
 	} `json:"candidates"`
-	UsageMetadata struct {
-		PromptTokenCount     int `json:"promptTokenCount"`
-		CandidatesTokenCount int `json:"candidatesTokenCount"`
-		TotalTokenCount      int `json:"totalTokenCount"`
-	} `json:"usageMetadata,omitempty"`
+	PromptFeedback *GeminiPromptFeedback `json:"promptFeedback,omitempty"`
+	// UsageMetadata 每个数据块都包含截至当前的累计用量
+	UsageMetadata *GeminiUsageMetadata `json:"usageMetadata,omitempty"`
 }
 
 // 将SDK的请求格式转换为Gemini的格式
 func adaptRequest(request *api.Request) map[string]interface{} {
 	// 将消息转换为Gemini格式
-	contents := []map[string]interface{}{}
-
-	for _, msg := range request.Messages {
-		content := map[string]interface{}{
-			"role": mapRole(msg.Role),
-			"parts": []map[string]interface{}{
-				{
-					"text": msg.Content,
-				},
-			},
-		}
-		contents = append(contents, content)
-	}
+	systemInstruction, contents := adaptContents(request.Messages)
 
 	// 构建请求
 	req := map[string]interface{}{
 		"contents": contents,
 	}
 
+	// 系统消息通过systemInstruction传递
+	if systemInstruction != nil {
+		req["systemInstruction"] = systemInstruction
+	}
+
 	// 添加生成参数
 	generationConfig := map[string]interface{}{}
 
@@ -344,42 +404,67 @@ func adaptRequest(request *api.Request) map[string]interface{} {
 	if request.TopP != nil {
 		generationConfig["topP"] = *request.TopP
 	}
+	if request.TopK != nil {
+		generationConfig["topK"] = *request.TopK
+	}
 	if request.MaxTokens != nil {
 		generationConfig["maxOutputTokens"] = *request.MaxTokens
 	}
 	if len(request.Stop) > 0 {
 		generationConfig["stopSequences"] = request.Stop
 	}
+	if request.PresencePenalty != nil {
+		generationConfig["presencePenalty"] = *request.PresencePenalty
+	}
+	if request.FrequencyPenalty != nil {
+		generationConfig["frequencyPenalty"] = *request.FrequencyPenalty
+	}
+	if request.N > 1 {
+		generationConfig["candidateCount"] = request.N
+	}
+	if request.Seed != nil {
+		generationConfig["seed"] = *request.Seed
+	}
+	if request.Logprobs {
+		generationConfig["responseLogprobs"] = true
+		if request.TopLogprobs > 0 {
+			generationConfig["logprobs"] = request.TopLogprobs
+		}
+	}
+
+	if request.ResponseFormat != nil {
+		adaptResponseFormat(request.ResponseFormat, generationConfig)
+	}
 
 	if len(generationConfig) > 0 {
 		req["generationConfig"] = generationConfig
 	}
 
 	// 添加安全设置
-	safetySettings := []map[string]string{
-		{
-			"category":  "HARM_CATEGORY_HARASSMENT",
-			"threshold": "BLOCK_NONE",
-		},
-		{
-			"category":  "HARM_CATEGORY_HATE_SPEECH",
-			"threshold": "BLOCK_NONE",
-		},
-		{
-			"category":  "HARM_CATEGORY_SEXUALLY_EXPLICIT",
-			"threshold": "BLOCK_NONE",
-		},
-		{
-			"category":  "HARM_CATEGORY_DANGEROUS_CONTENT",
-			"threshold": "BLOCK_NONE",
-		},
-	}
-
-	req["safetySettings"] = safetySettings
+	req["safetySettings"] = adaptSafetySettings(request.SafetySettings)
 
 	return req
 }
 
+// 将响应格式转换为Gemini的responseMimeType和responseJsonSchema
+//
+// json_schema格式使用JSONSchema中的schema字段（OpenAI格式）；JSONSchema本身不含schema字段时视为完整的Schema。
+func adaptResponseFormat(format *api.ResponseFormat, generationConfig map[string]interface{}) {
+	switch format.Type {
+	case api.ResponseFormatJSONObject:
+		generationConfig["responseMimeType"] = "application/json"
+	case api.ResponseFormatJSONSchema:
+		generationConfig["responseMimeType"] = "application/json"
+		schema := format.JSONSchema
+		if m, ok := schema.(map[string]interface{}); ok && m["schema"] != nil {
+			schema = m["schema"]
+		}
+		if schema != nil {
+			generationConfig["responseJsonSchema"] = schema
+		}
+	}
+}
+
 // 为流式请求适配请求格式
 func adaptStreamRequest(request *api.Request) map[string]interface{} {
 	req := adaptRequest(request)
@@ -407,21 +492,30 @@ func adaptResponse(geminiResp *GeminiResponse, modelName string) *api.Response {
 				Role:    api.RoleAssistant,
 				Content: content,
 			},
-			FinishReason: candidate.FinishReason,
+			FinishReason:    mapFinishReason(candidate.FinishReason),
+			RawFinishReason: candidate.FinishReason,
+			SafetyRatings:   adaptSafetyRatings(candidate.SafetyRatings),
+			Logprobs:        adaptLogprobs(candidate.LogprobsResult),
 		})
 	}
 
 	return &api.Response{
-		ID:      "", // Gemini不提供ID
-		Object:  "chat.completion",
-		Created: time.Now().Unix(),
-		Model:   modelName,
-		Choices: choices,
-		Usage: api.Usage{
-			PromptTokens:     geminiResp.UsageMetadata.PromptTokenCount,
-			CompletionTokens: geminiResp.UsageMetadata.CandidatesTokenCount,
-			TotalTokens:      geminiResp.UsageMetadata.TotalTokenCount,
-		},
+		ID:             geminiResp.ResponseID,
+		Object:         "chat.completion",
+		Created:        time.Now().Unix(),
+		Model:          modelName,
+		Choices:        choices,
+		Usage:          adaptUsage(geminiResp.UsageMetadata),
+		PromptFeedback: adaptPromptFeedback(geminiResp.PromptFeedback),
+	}
+}
+
+// 将Gemini的令牌用量转换为SDK格式
+func adaptUsage(usage GeminiUsageMetadata) api.Usage {
+	return api.Usage{
+		PromptTokens:     usage.PromptTokenCount,
+		CompletionTokens: usage.CandidatesTokenCount,
+		TotalTokens:      usage.TotalTokenCount,
 	}
 }
 
@@ -448,12 +542,46 @@ func mapGeminiError(geminiErr *GeminiError, statusCode int) *api.Error {
 	}
 }
 
+// 将SDK的消息转换为Gemini的systemInstruction和contents
+//
+// 系统消息被提取到systemInstruction中，相邻的同角色消息合并为一个轮次的多个part，
+// 以满足Gemini要求的user/model交替。
+func adaptContents(messages []api.Message) (map[string]interface{}, []map[string]interface{}) {
+	var systemParts []map[string]interface{}
+	contents := []map[string]interface{}{}
+
+	for _, msg := range messages {
+		part := map[string]interface{}{
+			"text": msg.Content,
+		}
+
+		if msg.Role == api.RoleSystem {
+			systemParts = append(systemParts, part)
+			continue
+		}
+
+		role := mapRole(msg.Role)
+		if n := len(contents); n > 0 && contents[n-1]["role"] == role {
+			contents[n-1]["parts"] = append(contents[n-1]["parts"].([]map[string]interface{}), part)
+			continue
+		}
+
+		contents = append(contents, map[string]interface{}{
+			"role":  role,
+			"parts": []map[string]interface{}{part},
+		})
+	}
+
+	if len(systemParts) == 0 {
+		return nil, contents
+	}
+	return map[string]interface{}{"parts": systemParts}, contents
+}
+
 // 将SDK的角色映射到Gemini的角色
 func mapRole(role api.Role) string {
 	switch role {
-	case api.RoleUser:
-		return "user"
-	case api.RoleSystem, api.RoleAssistant:
+	case api.RoleAssistant:
 		return "model"
 	default:
 		return "user"
@@ -462,84 +590,77 @@ func mapRole(role api.Role) string {
 
 // geminiResponseStream 实现流式响应接口
 type geminiResponseStream struct {
-	reader    *utils.SSEReader
+	*utils.StreamDecoder
 	rawReader io.ReadCloser
 	model     string
-	chunkID   int
 }
 
-// Recv 实现ResponseStream接口，读取下一个响应块
-func (s *geminiResponseStream) Recv() (*api.ResponseChunk, error) {
-	event, err := s.reader.ReadEvent()
-	if err != nil {
-		if err == io.EOF {
-			return nil, io.EOF
-		}
-		return nil, api.NewError(api.ErrorTypeServer, "读取SSE事件失败", 0, err)
-	}
-
-	// 如果不是data字段，或者数据为空，则跳过
-	if event.Data == "" {
-		return s.Recv() // 递归调用直到获取到有效数据或EOF
+// parseStreamError 解析流中途返回的错误，数据不是错误时返回nil
+func parseStreamError(data []byte) *api.Error {
+	var geminiErr GeminiError
+	if err := json.Unmarshal(data, &geminiErr); err != nil || geminiErr.Error.Message == "" {
+		return nil
 	}
+	return mapGeminiError(&geminiErr, 0)
+}
 
+// decode 将一个SSE事件解码为响应块
+func (s *geminiResponseStream) decode(event *utils.SSEEvent) (*api.ResponseChunk, error) {
 	// 解析JSON数据
 	var streamResp GeminiStreamResponse
 	if err := json.Unmarshal([]byte(utils.ParseSSEData(event.Data)), &streamResp); err != nil {
 		return nil, api.NewError(api.ErrorTypeServer, "解析流式响应失败", 0, err)
 	}
 
-	// 如果没有候选项，继续接收
-	if len(streamResp.Candidates) == 0 {
-		return s.Recv()
+	// 提示被拦截时流中只会返回promptFeedback
+	if err := promptBlockedError(streamResp.PromptFeedback); err != nil {
+		return nil, err
 	}
 
 	// 转换为SDK的通用格式
 	choices := []api.ChunkChoice{}
 
 	for _, candidate := range streamResp.Candidates {
-		// 检查是否有结束原因
-		if candidate.FinishReason != "" {
-			// 返回一个带有结束原因的空内容块
-			choices = append(choices, api.ChunkChoice{
-				Index:        candidate.Index,
-				Delta:        api.Message{Role: api.RoleAssistant},
-				FinishReason: candidate.FinishReason,
-			})
-			continue
-		}
-
-		// 提取文本内容
+		// 提取文本内容，最后一个块可能同时包含文本和结束原因
 		var content string
 		for _, part := range candidate.Content.Parts {
 			content += part.Text
 		}
 
-		if content != "" {
-			choices = append(choices, api.ChunkChoice{
-				Index: candidate.Index,
-				Delta: api.Message{
-					Role:    api.RoleAssistant,
-					Content: content,
-				},
-			})
+		if content == "" && candidate.FinishReason == "" {
+			continue
 		}
-	}
 
-	// 如果没有有效内容，继续接收
-	if len(choices) == 0 {
-		return s.Recv()
+		choices = append(choices, api.ChunkChoice{
+			Index: candidate.Index,
+			Delta: api.Message{
+				Role:    api.RoleAssistant,
+				Content: content,
+			},
+			FinishReason:    mapFinishReason(candidate.FinishReason),
+			RawFinishReason: candidate.FinishReason,
+			SafetyRatings:   adaptSafetyRatings(candidate.SafetyRatings),
+			Logprobs:        adaptLogprobs(candidate.LogprobsResult),
+		})
 	}
 
-	s.chunkID++
+	// 既没有有效内容也没有用量时，继续接收
+	if len(choices) == 0 && streamResp.UsageMetadata == nil {
+		return nil, nil
+	}
 
-	return &api.ResponseChunk{
-		ID:      fmt.Sprintf("chunk-%d", s.chunkID),
+	chunk := &api.ResponseChunk{
+		ID:      streamResp.ResponseID,
 		Object:  "chat.completion.chunk",
 		Created: time.Now().Unix(),
 		Model:   s.model,
 		Choices: choices,
-	}, nil
+	}
+	if streamResp.UsageMetadata != nil {
+		usage := adaptUsage(*streamResp.UsageMetadata)
+		chunk.Usage = &usage
+	}
+	return chunk, nil
 }
 
 // Close 关闭流
@@ -0,0 +1,48 @@
+package gemini
+
+import (
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// GeminiLogprobsCandidate 定义Gemini返回的单个token及其对数概率
+type GeminiLogprobsCandidate struct {
+	Token          string  `json:"token"`
+	TokenID        int     `json:"tokenId,omitempty"`
+	LogProbability float64 `json:"logProbability"`
+}
+
+// GeminiLogprobsResult 定义Gemini返回的对数概率结果
+//
+// ChosenCandidates为每个位置实际输出的token，TopCandidates与之一一对应，为该位置最可能的若干个token。
+type GeminiLogprobsResult struct {
+	TopCandidates []struct {
+		Candidates []GeminiLogprobsCandidate `json:"candidates"`
+	} `json:"topCandidates,omitempty"`
+	ChosenCandidates []GeminiLogprobsCandidate `json:"chosenCandidates,omitempty"`
+}
+
+// 将Gemini的对数概率结果转换为SDK格式
+func adaptLogprobs(result *GeminiLogprobsResult) []api.TokenLogprob {
+	if result == nil || len(result.ChosenCandidates) == 0 {
+		return nil
+	}
+
+	tokens := make([]api.TokenLogprob, len(result.ChosenCandidates))
+	for i, chosen := range result.ChosenCandidates {
+		tokens[i] = api.TokenLogprob{
+			Token:   chosen.Token,
+			Logprob: chosen.LogProbability,
+		}
+		if i < len(result.TopCandidates) {
+			top := result.TopCandidates[i].Candidates
+			tokens[i].TopLogprobs = make([]api.TopLogprob, len(top))
+			for j, candidate := range top {
+				tokens[i].TopLogprobs[j] = api.TopLogprob{
+					Token:   candidate.Token,
+					Logprob: candidate.LogProbability,
+				}
+			}
+		}
+	}
+	return tokens
+}
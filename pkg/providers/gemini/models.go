@@ -0,0 +1,130 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/models"
+)
+
+// modelsPageSize 每次查询模型列表的数量，为接口允许的最大值
+const modelsPageSize = "1000"
+
+// GeminiModel 定义models.list接口返回的模型
+type GeminiModel struct {
+	// Name 资源名称，如models/gemini-2.0-flash
+	Name             string `json:"name"`
+	BaseModelID      string `json:"baseModelId,omitempty"`
+	Version          string `json:"version,omitempty"`
+	DisplayName      string `json:"displayName,omitempty"`
+	Description      string `json:"description,omitempty"`
+	InputTokenLimit  int    `json:"inputTokenLimit,omitempty"`
+	OutputTokenLimit int    `json:"outputTokenLimit,omitempty"`
+	// SupportedGenerationMethods 模型支持的方法，如generateContent、embedContent
+	SupportedGenerationMethods []string `json:"supportedGenerationMethods,omitempty"`
+	// Thinking 模型是否支持思考
+	Thinking bool `json:"thinking,omitempty"`
+}
+
+// GeminiModelList 定义models.list接口的响应
+type GeminiModelList struct {
+	Models        []GeminiModel `json:"models"`
+	NextPageToken string        `json:"nextPageToken,omitempty"`
+}
+
+// ListModels 实现api.ModelLister接口，读取models.list的所有分页
+//
+// 返回的模型ID去掉了"models/"前缀，包含输入和输出的token上限，能力由支持的方法推断。
+func (c *Client) ListModels(ctx context.Context) ([]api.Model, error) {
+	var result []api.Model
+	pageToken := ""
+	for {
+		list, err := c.listModelsPage(ctx, pageToken)
+		if err != nil {
+			return nil, err
+		}
+		for _, model := range list.Models {
+			result = append(result, adaptModel(model))
+		}
+
+		if list.NextPageToken == "" {
+			return result, nil
+		}
+		pageToken = list.NextPageToken
+	}
+}
+
+// listModelsPage 查询模型列表的一页
+func (c *Client) listModelsPage(ctx context.Context, pageToken string) (*GeminiModelList, error) {
+	query := url.Values{}
+	query.Set("key", c.apiKey)
+	query.Set("pageSize", modelsPageSize)
+	if pageToken != "" {
+		query.Set("pageToken", pageToken)
+	}
+
+	// 创建HTTP请求
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/models?"+query.Encode(), nil)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "创建HTTP请求失败", 0, err)
+	}
+
+	// 发送请求
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "HTTP请求失败", 0, err)
+	}
+	defer resp.Body.Close()
+
+	// 读取响应
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "读取响应失败", resp.StatusCode, err)
+	}
+
+	// 检查HTTP状态码
+	if resp.StatusCode != http.StatusOK {
+		var geminiErr GeminiError
+		if err := json.Unmarshal(body, &geminiErr); err != nil {
+			return nil, api.NewError(api.ErrorTypeServer, fmt.Sprintf("API错误(状态码: %d)", resp.StatusCode), resp.StatusCode, nil)
+		}
+		return nil, mapGeminiError(&geminiErr, resp.StatusCode)
+	}
+
+	var list GeminiModelList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", resp.StatusCode, err)
+	}
+	return &list, nil
+}
+
+// 将Gemini的模型转换为SDK格式
+func adaptModel(model GeminiModel) api.Model {
+	var capabilities []string
+	for _, method := range model.SupportedGenerationMethods {
+		switch method {
+		case "generateContent":
+			capabilities = append(capabilities, models.CapabilityChat)
+		case "embedContent":
+			capabilities = append(capabilities, models.CapabilityEmbedding)
+		}
+	}
+	if model.Thinking {
+		capabilities = append(capabilities, models.CapabilityThinking)
+	}
+
+	return api.Model{
+		ID:               strings.TrimPrefix(model.Name, "models/"),
+		DisplayName:      model.DisplayName,
+		OwnedBy:          "google",
+		InputTokenLimit:  model.InputTokenLimit,
+		OutputTokenLimit: model.OutputTokenLimit,
+		Capabilities:     capabilities,
+	}
+}
@@ -0,0 +1,119 @@
+package gemini
+
+import (
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// Gemini的有害内容类别
+const (
+	HarmCategoryHarassment       = "HARM_CATEGORY_HARASSMENT"
+	HarmCategoryHateSpeech       = "HARM_CATEGORY_HATE_SPEECH"
+	HarmCategorySexuallyExplicit = "HARM_CATEGORY_SEXUALLY_EXPLICIT"
+	HarmCategoryDangerousContent = "HARM_CATEGORY_DANGEROUS_CONTENT"
+	HarmCategoryCivicIntegrity   = "HARM_CATEGORY_CIVIC_INTEGRITY"
+)
+
+// Gemini的拦截阈值
+const (
+	BlockNone           = "BLOCK_NONE"
+	BlockOnlyHigh       = "BLOCK_ONLY_HIGH"
+	BlockMediumAndAbove = "BLOCK_MEDIUM_AND_ABOVE"
+	BlockLowAndAbove    = "BLOCK_LOW_AND_ABOVE"
+)
+
+// 未指定安全设置时使用的默认配置，与之前的行为保持一致
+var defaultSafetySettings = []api.SafetySetting{
+	{Category: HarmCategoryHarassment, Threshold: BlockNone},
+	{Category: HarmCategoryHateSpeech, Threshold: BlockNone},
+	{Category: HarmCategorySexuallyExplicit, Threshold: BlockNone},
+	{Category: HarmCategoryDangerousContent, Threshold: BlockNone},
+}
+
+// GeminiSafetyRating 定义Gemini返回的安全评估
+type GeminiSafetyRating struct {
+	Category    string `json:"category"`
+	Probability string `json:"probability"`
+	Blocked     bool   `json:"blocked,omitempty"`
+}
+
+// GeminiPromptFeedback 定义Gemini对输入提示的反馈
+type GeminiPromptFeedback struct {
+	BlockReason   string               `json:"blockReason,omitempty"`
+	SafetyRatings []GeminiSafetyRating `json:"safetyRatings,omitempty"`
+}
+
+// 将安全设置转换为Gemini格式
+func adaptSafetySettings(settings []api.SafetySetting) []map[string]string {
+	if len(settings) == 0 {
+		settings = defaultSafetySettings
+	}
+
+	result := make([]map[string]string, 0, len(settings))
+	for _, setting := range settings {
+		result = append(result, map[string]string{
+			"category":  setting.Category,
+			"threshold": setting.Threshold,
+		})
+	}
+	return result
+}
+
+// 将Gemini的安全评估转换为SDK格式
+func adaptSafetyRatings(ratings []GeminiSafetyRating) []api.SafetyRating {
+	if len(ratings) == 0 {
+		return nil
+	}
+
+	result := make([]api.SafetyRating, 0, len(ratings))
+	for _, rating := range ratings {
+		result = append(result, api.SafetyRating{
+			Category:    rating.Category,
+			Probability: rating.Probability,
+			Blocked:     rating.Blocked,
+		})
+	}
+	return result
+}
+
+// 将Gemini的提示反馈转换为SDK格式
+func adaptPromptFeedback(feedback *GeminiPromptFeedback) *api.PromptFeedback {
+	if feedback == nil || (feedback.BlockReason == "" && len(feedback.SafetyRatings) == 0) {
+		return nil
+	}
+
+	return &api.PromptFeedback{
+		BlockReason:   feedback.BlockReason,
+		SafetyRatings: adaptSafetyRatings(feedback.SafetyRatings),
+	}
+}
+
+// 提示被拦截时返回内容过滤错误
+func promptBlockedError(feedback *GeminiPromptFeedback) error {
+	if feedback == nil || feedback.BlockReason == "" {
+		return nil
+	}
+
+	return &api.Error{
+		Type:    api.ErrorTypeContentFiltered,
+		Message: "提示被Gemini安全策略拦截: " + feedback.BlockReason,
+		Code:    feedback.BlockReason,
+	}
+}
+
+// 将Gemini的结束原因映射为标准值，各类内容拦截统一为content_filter
+func mapFinishReason(reason string) api.FinishReason {
+	switch reason {
+	case "", "FINISH_REASON_UNSPECIFIED":
+		return ""
+	case "STOP":
+		return api.FinishReasonStop
+	case "MAX_TOKENS":
+		return api.FinishReasonLength
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return api.FinishReasonContentFilter
+	case "MALFORMED_FUNCTION_CALL":
+		return api.FinishReasonError
+	default:
+		return api.FinishReasonOther
+	}
+}
@@ -0,0 +1,636 @@
+package mistral
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/utils"
+)
+
+// Client 实现了Mistral的API客户端
+//
+// 除api.LLMClient接口外，Client还提供FIM代码补全（见FIMComplete），
+// 需要时可将NewClient的返回值断言为*mistral.Client使用。
+type Client struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	// streamClient 用于流式请求，不设置总超时，由streamTimeouts分阶段控制
+	streamClient   *http.Client
+	streamTimeouts api.StreamTimeouts
+	// strictParams 为true时不支持的参数返回错误，否则忽略并在响应中附带警告
+	strictParams bool
+}
+
+// 默认配置
+const (
+	defaultBaseURL        = "https://api.mistral.ai/v1"
+	defaultTimeout        = 30 * time.Second
+	defaultMaxRetries     = 3
+	defaultEmbeddingModel = "mistral-embed"
+)
+
+// NewClient 创建一个新的Mistral客户端
+func NewClient(options ...api.ClientOption) (api.LLMClient, error) {
+	clientOptions := &api.ClientOptions{
+		BaseURL:    defaultBaseURL,
+		Timeout:    int(defaultTimeout.Seconds()),
+		MaxRetries: defaultMaxRetries,
+	}
+
+	// 应用选项
+	for _, option := range options {
+		option(clientOptions)
+	}
+
+	// 验证必要的配置
+	if clientOptions.APIKey == "" {
+		return nil, api.NewError(api.ErrorTypeAuthentication, "API密钥不能为空", 0, nil)
+	}
+
+	// 创建HTTP客户端
+	httpClient := &http.Client{
+		Timeout: time.Duration(clientOptions.Timeout) * time.Second,
+	}
+	if clientOptions.HTTPClient != nil {
+		if client, ok := clientOptions.HTTPClient.(*http.Client); ok {
+			httpClient = client
+		}
+	}
+
+	return &Client{
+		apiKey:         clientOptions.APIKey,
+		baseURL:        clientOptions.BaseURL,
+		httpClient:     httpClient,
+		streamClient:   utils.NewStreamClient(httpClient),
+		streamTimeouts: clientOptions.StreamTimeouts.WithDefaults(time.Duration(clientOptions.Timeout) * time.Second),
+		strictParams:   clientOptions.StrictParams,
+		maxRetries:     clientOptions.MaxRetries,
+	}, nil
+}
+
+// Complete 发送请求并获取完整的响应
+func (c *Client) Complete(ctx context.Context, request *api.Request) (*api.Response, error) {
+	// 验证请求
+	if err := validateRequest(request); err != nil {
+		return nil, err
+	}
+
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.post(ctx, "/chat/completions", adaptRequest(request))
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析响应
+	var mistralResp MistralResponse
+	if err := json.Unmarshal(body, &mistralResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", http.StatusOK, err)
+	}
+
+	response := adaptResponse(&mistralResp)
+	response.Warnings = warnings
+	return response, nil
+}
+
+// CompleteStream 发送请求并获取流式响应
+func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.ResponseStream, error) {
+	// 验证请求
+	if err := validateRequest(request); err != nil {
+		return nil, err
+	}
+
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// 设置流式标志
+	reqCopy := *request
+	reqCopy.Stream = true
+
+	stream, err := c.postStream(ctx, "/chat/completions", adaptRequest(&reqCopy))
+	if err != nil {
+		return nil, err
+	}
+	return utils.WithWarnings(stream, warnings), nil
+}
+
+// Embedding 获取文本的嵌入向量
+func (c *Client) Embedding(ctx context.Context, input string) ([]float32, error) {
+	body, err := c.post(ctx, "/embeddings", map[string]interface{}{
+		"model": defaultEmbeddingModel,
+		"input": []string{input},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析嵌入响应
+	var embedResp struct {
+		Data []struct {
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &embedResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析嵌入响应失败", http.StatusOK, err)
+	}
+
+	if len(embedResp.Data) == 0 || len(embedResp.Data[0].Embedding) == 0 {
+		return nil, api.NewError(api.ErrorTypeServer, "未收到有效的嵌入结果", http.StatusOK, nil)
+	}
+
+	return embedResp.Data[0].Embedding, nil
+}
+
+// post 发送JSON请求并返回成功响应的响应体
+func (c *Client) post(ctx context.Context, path string, payload interface{}) ([]byte, error) {
+	resp, err := c.doRequest(ctx, path, payload, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// 读取响应
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "读取响应失败", resp.StatusCode, err)
+	}
+
+	// 检查HTTP状态码
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseErrorBody(body, resp.StatusCode)
+	}
+
+	return body, nil
+}
+
+// postStream 发送流式请求并返回响应流
+func (c *Client) postStream(ctx context.Context, path string, payload interface{}) (api.ResponseStream, error) {
+	resp, err := c.doRequest(ctx, path, payload, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查HTTP状态码
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, parseErrorBody(body, resp.StatusCode)
+	}
+
+	stream := &mistralResponseStream{
+		rawReader: resp.Body,
+	}
+	stream.StreamDecoder = utils.NewStreamDecoder(resp.Body, stream.decode, func(options *utils.StreamDecoderOptions) {
+		options.DoneData = "[DONE]"
+		options.ParseError = parseStreamError
+		options.InlineErrors = true
+	})
+	return stream, nil
+}
+
+// doRequest 构造并发送HTTP请求
+func (c *Client) doRequest(ctx context.Context, path string, payload interface{}, stream bool) (*http.Response, error) {
+	// 准备请求体
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "无法序列化请求", 0, err)
+	}
+
+	// 创建HTTP请求
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "创建HTTP请求失败", 0, err)
+	}
+
+	// 设置请求头
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
+	} else {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	// 流式请求分阶段控制超时
+	if stream {
+		return utils.DoStreamRequest(c.streamClient, req, c.streamTimeouts)
+	}
+
+	// 发送请求
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "HTTP请求失败", 0, err)
+	}
+	return resp, nil
+}
+
+// 验证请求参数
+func validateRequest(request *api.Request) error {
+	if request == nil {
+		return api.NewError(api.ErrorTypeInvalidRequest, "请求不能为空", 0, nil)
+	}
+	if request.Model == "" {
+		return api.NewError(api.ErrorTypeInvalidRequest, "模型不能为空", 0, nil)
+	}
+	if len(request.Messages) == 0 {
+		return api.NewError(api.ErrorTypeInvalidRequest, "消息不能为空", 0, nil)
+	}
+	if request.ResponseFormat != nil && request.ResponseFormat.Type == api.ResponseFormatJSONSchema && request.ResponseFormat.JSONSchema == nil {
+		return api.NewError(api.ErrorTypeInvalidRequest, "json_schema格式需要提供JSONSchema", 0, nil)
+	}
+	return nil
+}
+
+// supportedParams Mistral支持的可选参数
+var supportedParams = api.NewParamSet(
+	api.ParamTemperature, api.ParamTopP, api.ParamMaxTokens, api.ParamPresencePenalty, api.ParamFrequencyPenalty, api.ParamStop,
+	api.ParamSeed, api.ParamTools, api.ParamToolChoice, api.ParamParallelToolCalls, api.ParamResponseFormat,
+)
+
+// 检查请求中Mistral不支持的参数，非严格模式下返回忽略这些参数的警告
+func (c *Client) checkParams(request *api.Request) ([]string, error) {
+	return api.CheckParams("Mistral", request, supportedParams, c.strictParams)
+}
+
+// MistralResponse 定义Mistral API的响应结构
+type MistralResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index   int            `json:"index"`
+		Message MistralMessage `json:"message"`
+		// FinishReason 可能为stop、length、model_length、error或tool_calls
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage MistralUsage `json:"usage"`
+}
+
+// MistralUsage 定义Mistral的令牌用量
+type MistralUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// 将Mistral的令牌用量转换为SDK格式
+func adaptUsage(usage MistralUsage) api.Usage {
+	return api.Usage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+	}
+}
+
+// MistralStreamResponse 定义Mistral API的流式响应结构
+type MistralStreamResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index        int            `json:"index"`
+		Delta        MistralMessage `json:"delta"`
+		FinishReason string         `json:"finish_reason,omitempty"`
+	} `json:"choices"`
+	// Usage 仅在开启stream_options.include_usage时出现在最后一个数据块中
+	Usage *MistralUsage `json:"usage,omitempty"`
+}
+
+// MistralMessage 定义Mistral的消息结构
+type MistralMessage struct {
+	Role      string            `json:"role,omitempty"`
+	Content   string            `json:"content"`
+	ToolCalls []MistralToolCall `json:"tool_calls,omitempty"`
+}
+
+// MistralToolCall 定义Mistral的工具调用结构
+type MistralToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type,omitempty"`
+	Index    *int   `json:"index,omitempty"`
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+// MistralError 定义Mistral API的错误响应
+//
+// 常规错误形如 {"object":"error","message":"...","type":"...","code":"..."}，
+// 其中message在参数校验失败时可能是对象；422错误则可能只返回 {"detail":[...]}。
+type MistralError struct {
+	Object  string          `json:"object"`
+	Message json.RawMessage `json:"message"`
+	Type    string          `json:"type"`
+	Param   json.RawMessage `json:"param"`
+	Code    json.RawMessage `json:"code"`
+	Detail  json.RawMessage `json:"detail"`
+}
+
+// 将SDK的请求格式转换为Mistral的格式
+func adaptRequest(request *api.Request) map[string]interface{} {
+	req := map[string]interface{}{
+		"model":    request.Model,
+		"messages": adaptMessages(request.Messages),
+	}
+
+	// 添加可选参数
+	if request.Temperature != nil {
+		req["temperature"] = *request.Temperature
+	}
+	if request.TopP != nil {
+		req["top_p"] = *request.TopP
+	}
+	if request.MaxTokens != nil {
+		req["max_tokens"] = *request.MaxTokens
+	}
+	if request.PresencePenalty != nil {
+		req["presence_penalty"] = *request.PresencePenalty
+	}
+	if request.FrequencyPenalty != nil {
+		req["frequency_penalty"] = *request.FrequencyPenalty
+	}
+	if len(request.Stop) > 0 {
+		req["stop"] = request.Stop
+	}
+	if request.Seed != nil {
+		req["random_seed"] = *request.Seed
+	}
+	if request.Stream {
+		req["stream"] = request.Stream
+		// 让最后一个数据块携带令牌用量
+		req["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
+
+	// 函数调用
+	if len(request.Tools) > 0 {
+		req["tools"] = request.Tools
+	}
+	if request.ToolChoice != nil {
+		req["tool_choice"] = adaptToolChoice(request.ToolChoice)
+	}
+	if request.ParallelToolCalls != nil {
+		req["parallel_tool_calls"] = *request.ParallelToolCalls
+	}
+
+	// JSON模式
+	if request.ResponseFormat != nil {
+		req["response_format"] = request.ResponseFormat
+	}
+
+	// 添加其他自定义参数（如safe_prompt）
+	for k, v := range request.ExtraParams {
+		req[k] = v
+	}
+
+	return req
+}
+
+// 将SDK的消息转换为Mistral的消息格式
+func adaptMessages(messages []api.Message) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(messages))
+	for _, msg := range messages {
+		m := map[string]interface{}{
+			"role":    string(msg.Role),
+			"content": msg.Content,
+		}
+		if len(msg.ToolCalls) > 0 {
+			toolCalls := make([]map[string]interface{}, len(msg.ToolCalls))
+			for i, call := range msg.ToolCalls {
+				toolCalls[i] = map[string]interface{}{
+					"id":   call.ID,
+					"type": api.ToolTypeFunction,
+					"function": map[string]interface{}{
+						"name":      call.Function.Name,
+						"arguments": call.Function.Arguments,
+					},
+				}
+			}
+			m["tool_calls"] = toolCalls
+		}
+		if msg.ToolCallID != "" {
+			m["tool_call_id"] = msg.ToolCallID
+		}
+		if msg.Name != "" {
+			m["name"] = msg.Name
+		}
+		result = append(result, m)
+	}
+	return result
+}
+
+// Mistral使用"any"表示必须调用工具，这里兼容OpenAI风格的"required"
+func adaptToolChoice(choice interface{}) interface{} {
+	if s, ok := choice.(string); ok && s == "required" {
+		return "any"
+	}
+	return choice
+}
+
+// 将Mistral的消息转换为SDK的通用格式
+func adaptMessage(msg MistralMessage, defaultRole api.Role) api.Message {
+	role := api.Role(msg.Role)
+	if role == "" {
+		role = defaultRole
+	}
+
+	var toolCalls []api.ToolCall
+	for _, call := range msg.ToolCalls {
+		toolType := call.Type
+		if toolType == "" {
+			toolType = api.ToolTypeFunction
+		}
+		toolCalls = append(toolCalls, api.ToolCall{
+			Index: call.Index,
+			ID:    call.ID,
+			Type:  toolType,
+			Function: api.FunctionCall{
+				Name:      call.Function.Name,
+				Arguments: rawArguments(call.Function.Arguments),
+			},
+		})
+	}
+
+	return api.Message{
+		Role:      role,
+		Content:   msg.Content,
+		ToolCalls: toolCalls,
+	}
+}
+
+// rawArguments 将函数参数统一为JSON字符串，Mistral可能返回字符串或对象
+func rawArguments(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// 将Mistral的响应格式转换为SDK的通用格式
+func adaptResponse(mistralResp *MistralResponse) *api.Response {
+	choices := make([]api.Choice, len(mistralResp.Choices))
+	for i, choice := range mistralResp.Choices {
+		choices[i] = api.Choice{
+			Index:           choice.Index,
+			Message:         adaptMessage(choice.Message, api.RoleAssistant),
+			FinishReason:    mapFinishReason(choice.FinishReason),
+			RawFinishReason: choice.FinishReason,
+		}
+	}
+
+	return &api.Response{
+		ID:      mistralResp.ID,
+		Object:  mistralResp.Object,
+		Created: mistralResp.Created,
+		Model:   mistralResp.Model,
+		Choices: choices,
+		Usage:   adaptUsage(mistralResp.Usage),
+	}
+}
+
+// parseErrorBody 解析错误响应体
+func parseErrorBody(body []byte, statusCode int) *api.Error {
+	var mistralErr MistralError
+	if err := json.Unmarshal(body, &mistralErr); err != nil {
+		return api.NewError(api.ErrorTypeServer, fmt.Sprintf("API错误(状态码: %d)", statusCode), statusCode, nil)
+	}
+	return mapMistralError(&mistralErr, statusCode)
+}
+
+// 将Mistral的结束原因映射为标准值
+func mapFinishReason(reason string) api.FinishReason {
+	switch reason {
+	case "model_length":
+		return api.FinishReasonLength
+	case "error":
+		return api.FinishReasonError
+	default:
+		return api.NormalizeFinishReason(reason)
+	}
+}
+
+// 将Mistral的错误映射到SDK的错误类型
+func mapMistralError(mistralErr *MistralError, statusCode int) *api.Error {
+	errType := api.ErrorTypeUnknown
+	switch mistralErr.Type {
+	case "invalid_request_error", "invalid_request_message_order", "invalid_model":
+		errType = api.ErrorTypeInvalidRequest
+	case "authentication_error", "unauthorized":
+		errType = api.ErrorTypeAuthentication
+	case "rate_limit_error", "rate_limited":
+		errType = api.ErrorTypeRateLimit
+	case "server_error", "internal_server_error":
+		errType = api.ErrorTypeServer
+	default:
+		// Mistral的type取值不固定，退回到按状态码判断
+		switch {
+		case statusCode == http.StatusBadRequest || statusCode == http.StatusUnprocessableEntity || statusCode == http.StatusNotFound:
+			errType = api.ErrorTypeInvalidRequest
+		case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+			errType = api.ErrorTypeAuthentication
+		case statusCode == http.StatusTooManyRequests:
+			errType = api.ErrorTypeRateLimit
+		case statusCode >= 500:
+			errType = api.ErrorTypeServer
+		}
+	}
+
+	message := rawArguments(mistralErr.Message)
+	if message == "" && len(mistralErr.Detail) > 0 {
+		message = string(mistralErr.Detail)
+	}
+	if message == "" {
+		message = fmt.Sprintf("API错误(状态码: %d)", statusCode)
+	}
+
+	return &api.Error{
+		Type:       errType,
+		Message:    message,
+		StatusCode: statusCode,
+		Param:      nullableString(mistralErr.Param),
+		Code:       nullableString(mistralErr.Code),
+	}
+}
+
+// nullableString 将可能为null、字符串或数字的JSON值转换为字符串
+func nullableString(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	return rawArguments(raw)
+}
+
+// mistralResponseStream 实现流式响应接口
+type mistralResponseStream struct {
+	*utils.StreamDecoder
+	rawReader io.ReadCloser
+}
+
+// parseStreamError 解析流中途返回的错误，数据不是错误时返回nil
+func parseStreamError(data []byte) *api.Error {
+	var mistralErr MistralError
+	if err := json.Unmarshal(data, &mistralErr); err != nil || mistralErr.Object != "error" {
+		return nil
+	}
+	return mapMistralError(&mistralErr, 0)
+}
+
+// decode 将一个SSE事件解码为响应块
+func (s *mistralResponseStream) decode(event *utils.SSEEvent) (*api.ResponseChunk, error) {
+	// 解析JSON数据
+	var streamResp MistralStreamResponse
+	if err := json.Unmarshal([]byte(utils.ParseSSEData(event.Data)), &streamResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析流式响应失败", 0, err)
+	}
+
+	// 转换为SDK的通用格式
+	choices := make([]api.ChunkChoice, len(streamResp.Choices))
+	for i, choice := range streamResp.Choices {
+		choices[i] = api.ChunkChoice{
+			Index:           choice.Index,
+			Delta:           adaptMessage(choice.Delta, ""),
+			FinishReason:    mapFinishReason(choice.FinishReason),
+			RawFinishReason: choice.FinishReason,
+		}
+	}
+
+	chunk := &api.ResponseChunk{
+		ID:      streamResp.ID,
+		Object:  streamResp.Object,
+		Created: streamResp.Created,
+		Model:   streamResp.Model,
+		Choices: choices,
+	}
+	if streamResp.Usage != nil {
+		usage := adaptUsage(*streamResp.Usage)
+		chunk.Usage = &usage
+	}
+	return chunk, nil
+}
+
+// Close 关闭流
+func (s *mistralResponseStream) Close() error {
+	return s.rawReader.Close()
+}
@@ -0,0 +1,82 @@
+package mistral
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// FIMRequest 定义FIM（fill-in-the-middle）代码补全请求，用于Codestral系列模型
+type FIMRequest struct {
+	// 必填字段
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+
+	// Suffix 光标之后的代码，模型生成Prompt与Suffix之间的内容
+	Suffix string `json:"suffix,omitempty"`
+
+	// 可选参数
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	MaxTokens   *int     `json:"max_tokens,omitempty"`
+	MinTokens   *int     `json:"min_tokens,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+	RandomSeed  *int     `json:"random_seed,omitempty"`
+	Stream      bool     `json:"stream,omitempty"`
+}
+
+// FIMComplete 发送FIM代码补全请求并获取完整的响应
+//
+// 补全结果位于Choices[0].Message.Content。
+func (c *Client) FIMComplete(ctx context.Context, request *FIMRequest) (*api.Response, error) {
+	// 验证请求
+	if err := validateFIMRequest(request); err != nil {
+		return nil, err
+	}
+
+	reqCopy := *request
+	reqCopy.Stream = false
+
+	body, err := c.post(ctx, "/fim/completions", &reqCopy)
+	if err != nil {
+		return nil, err
+	}
+
+	// FIM的响应结构与聊天补全一致
+	var mistralResp MistralResponse
+	if err := json.Unmarshal(body, &mistralResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", http.StatusOK, err)
+	}
+
+	return adaptResponse(&mistralResp), nil
+}
+
+// FIMCompleteStream 发送FIM代码补全请求并获取流式响应
+func (c *Client) FIMCompleteStream(ctx context.Context, request *FIMRequest) (api.ResponseStream, error) {
+	// 验证请求
+	if err := validateFIMRequest(request); err != nil {
+		return nil, err
+	}
+
+	// 设置流式标志
+	reqCopy := *request
+	reqCopy.Stream = true
+
+	return c.postStream(ctx, "/fim/completions", &reqCopy)
+}
+
+// 验证FIM请求参数
+func validateFIMRequest(request *FIMRequest) error {
+	if request == nil {
+		return api.NewError(api.ErrorTypeInvalidRequest, "请求不能为空", 0, nil)
+	}
+	if request.Model == "" {
+		return api.NewError(api.ErrorTypeInvalidRequest, "模型不能为空", 0, nil)
+	}
+	if request.Prompt == "" {
+		return api.NewError(api.ErrorTypeInvalidRequest, "提示不能为空", 0, nil)
+	}
+	return nil
+}
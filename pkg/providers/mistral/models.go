@@ -0,0 +1,85 @@
+package mistral
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/models"
+	"github.com/ojbkgo/llm-sdk/pkg/utils"
+)
+
+// MistralModel 定义/models接口返回的模型
+type MistralModel struct {
+	ID               string `json:"id"`
+	Object           string `json:"object"`
+	Created          int64  `json:"created"`
+	OwnedBy          string `json:"owned_by"`
+	Name             string `json:"name,omitempty"`
+	MaxContextLength int    `json:"max_context_length,omitempty"`
+	Capabilities     struct {
+		CompletionChat  bool `json:"completion_chat"`
+		CompletionFIM   bool `json:"completion_fim"`
+		FunctionCalling bool `json:"function_calling"`
+		Vision          bool `json:"vision"`
+	} `json:"capabilities"`
+}
+
+// MistralModelList 定义/models接口的响应
+type MistralModelList struct {
+	Object string         `json:"object"`
+	Data   []MistralModel `json:"data"`
+}
+
+// ListModels 实现api.ModelLister接口，返回/models接口列出的模型，包含上下文长度和能力
+func (c *Client) ListModels(ctx context.Context) ([]api.Model, error) {
+	config := utils.DefaultHTTPConfig()
+	config.MaxRetries = c.maxRetries
+	body, statusCode, err := utils.DoHTTPRequest(ctx, c.httpClient, http.MethodGet, c.baseURL+"/models", nil, utils.MakeAuthHeader(c.apiKey, "bearer"), config)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查HTTP状态码
+	if statusCode != http.StatusOK {
+		return nil, parseErrorBody(body, statusCode)
+	}
+
+	var list MistralModelList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", statusCode, err)
+	}
+
+	result := make([]api.Model, len(list.Data))
+	for i, model := range list.Data {
+		result[i] = adaptModel(model)
+	}
+	return result, nil
+}
+
+// 将Mistral的模型转换为SDK格式
+func adaptModel(model MistralModel) api.Model {
+	var capabilities []string
+	if model.Capabilities.CompletionChat {
+		capabilities = append(capabilities, models.CapabilityChat)
+	}
+	if model.Capabilities.FunctionCalling {
+		capabilities = append(capabilities, models.CapabilityFunction)
+	}
+	if model.Capabilities.Vision {
+		capabilities = append(capabilities, models.CapabilityVision)
+	}
+	if model.Capabilities.CompletionFIM {
+		capabilities = append(capabilities, models.CapabilityFIM)
+	}
+
+	return api.Model{
+		ID:              model.ID,
+		DisplayName:     model.Name,
+		Created:         model.Created,
+		OwnedBy:         model.OwnedBy,
+		InputTokenLimit: model.MaxContextLength,
+		Capabilities:    capabilities,
+	}
+}
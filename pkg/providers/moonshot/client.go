@@ -0,0 +1,438 @@
+package moonshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/utils"
+)
+
+// Client 实现了月之暗面Kimi（Moonshot）的API客户端
+type Client struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	// streamClient 用于流式请求，不设置总超时，由streamTimeouts分阶段控制
+	streamClient   *http.Client
+	streamTimeouts api.StreamTimeouts
+	// strictParams 为true时不支持的参数返回错误，否则忽略并在响应中附带警告
+	strictParams bool
+}
+
+// 默认配置
+const (
+	defaultBaseURL    = "https://api.moonshot.cn/v1"
+	defaultTimeout    = 60 * time.Second
+	defaultMaxRetries = 3
+)
+
+// NewClient 创建一个新的Moonshot客户端
+func NewClient(options ...api.ClientOption) (api.LLMClient, error) {
+	clientOptions := &api.ClientOptions{
+		BaseURL:    defaultBaseURL,
+		Timeout:    int(defaultTimeout.Seconds()),
+		MaxRetries: defaultMaxRetries,
+	}
+
+	// 应用选项
+	for _, option := range options {
+		option(clientOptions)
+	}
+
+	// 验证必要的配置
+	if clientOptions.APIKey == "" {
+		return nil, api.NewError(api.ErrorTypeAuthentication, "API密钥不能为空", 0, nil)
+	}
+
+	// 创建HTTP客户端
+	httpClient := &http.Client{
+		Timeout: time.Duration(clientOptions.Timeout) * time.Second,
+	}
+	if clientOptions.HTTPClient != nil {
+		if client, ok := clientOptions.HTTPClient.(*http.Client); ok {
+			httpClient = client
+		}
+	}
+
+	return &Client{
+		apiKey:         clientOptions.APIKey,
+		baseURL:        clientOptions.BaseURL,
+		httpClient:     httpClient,
+		streamClient:   utils.NewStreamClient(httpClient),
+		streamTimeouts: clientOptions.StreamTimeouts.WithDefaults(time.Duration(clientOptions.Timeout) * time.Second),
+		strictParams:   clientOptions.StrictParams,
+		maxRetries:     clientOptions.MaxRetries,
+	}, nil
+}
+
+// Complete 发送请求并获取完整的响应
+func (c *Client) Complete(ctx context.Context, request *api.Request) (*api.Response, error) {
+	// 验证请求
+	if err := validateRequest(request); err != nil {
+		return nil, err
+	}
+
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.doRequest(ctx, "/chat/completions", adaptRequest(request), false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// 读取响应
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "读取响应失败", resp.StatusCode, err)
+	}
+
+	// 检查HTTP状态码
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseErrorBody(body, resp.StatusCode)
+	}
+
+	// 解析响应
+	var moonshotResp MoonshotResponse
+	if err := json.Unmarshal(body, &moonshotResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", resp.StatusCode, err)
+	}
+
+	response := adaptResponse(&moonshotResp)
+	response.Warnings = warnings
+	return response, nil
+}
+
+// CompleteStream 发送请求并获取流式响应
+func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.ResponseStream, error) {
+	// 验证请求
+	if err := validateRequest(request); err != nil {
+		return nil, err
+	}
+
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// 设置流式标志
+	reqCopy := *request
+	reqCopy.Stream = true
+
+	resp, err := c.doRequest(ctx, "/chat/completions", adaptRequest(&reqCopy), true)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查HTTP状态码
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, parseErrorBody(body, resp.StatusCode)
+	}
+
+	stream := &moonshotResponseStream{
+		rawReader: resp.Body,
+	}
+	stream.StreamDecoder = utils.NewStreamDecoder(resp.Body, stream.decode, func(options *utils.StreamDecoderOptions) {
+		options.DoneData = "[DONE]"
+		options.ParseError = parseStreamError
+		options.InlineErrors = true
+	})
+	return utils.WithWarnings(stream, warnings), nil
+}
+
+// Embedding 获取文本的嵌入向量
+func (c *Client) Embedding(ctx context.Context, input string) ([]float32, error) {
+	// Moonshot 目前没有公开的嵌入接口，所以这里返回未实现错误
+	return nil, api.NewError(api.ErrorTypeUnknown, "Moonshot暂不支持嵌入功能", 0, nil)
+}
+
+// doRequest 构造并发送HTTP请求
+func (c *Client) doRequest(ctx context.Context, path string, payload interface{}, stream bool) (*http.Response, error) {
+	// 准备请求体
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "无法序列化请求", 0, err)
+	}
+
+	// 创建HTTP请求
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "创建HTTP请求失败", 0, err)
+	}
+
+	// 设置请求头
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	// 流式请求分阶段控制超时
+	if stream {
+		return utils.DoStreamRequest(c.streamClient, req, c.streamTimeouts)
+	}
+
+	// 发送请求
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "HTTP请求失败", 0, err)
+	}
+	return resp, nil
+}
+
+// 验证请求参数
+func validateRequest(request *api.Request) error {
+	if request == nil {
+		return api.NewError(api.ErrorTypeInvalidRequest, "请求不能为空", 0, nil)
+	}
+	if request.Model == "" {
+		return api.NewError(api.ErrorTypeInvalidRequest, "模型不能为空", 0, nil)
+	}
+	if len(request.Messages) == 0 {
+		return api.NewError(api.ErrorTypeInvalidRequest, "消息不能为空", 0, nil)
+	}
+	return nil
+}
+
+// supportedParams Moonshot支持的可选参数
+var supportedParams = api.NewParamSet(
+	api.ParamTemperature, api.ParamTopP, api.ParamMaxTokens, api.ParamPresencePenalty, api.ParamFrequencyPenalty, api.ParamStop,
+	api.ParamTools, api.ParamToolChoice, api.ParamResponseFormat,
+)
+
+// 检查请求中Moonshot不支持的参数，非严格模式下返回忽略这些参数的警告
+func (c *Client) checkParams(request *api.Request) ([]string, error) {
+	return api.CheckParams("Moonshot", request, supportedParams, c.strictParams)
+}
+
+// MoonshotResponse 定义Moonshot API的响应结构
+type MoonshotResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index        int               `json:"index"`
+		Message      utils.ChatMessage `json:"message"`
+		FinishReason string            `json:"finish_reason"`
+	} `json:"choices"`
+	Usage MoonshotUsage `json:"usage"`
+}
+
+// MoonshotUsage 定义Moonshot的令牌用量
+type MoonshotUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// 将Moonshot的令牌用量转换为SDK格式
+func adaptUsage(usage MoonshotUsage) api.Usage {
+	return api.Usage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+	}
+}
+
+// MoonshotStreamResponse 定义Moonshot API的流式响应结构
+type MoonshotStreamResponse struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index        int               `json:"index"`
+		Delta        utils.ChatMessage `json:"delta"`
+		FinishReason string            `json:"finish_reason,omitempty"`
+		// Usage Moonshot在候选结束的数据块中返回的用量
+		Usage *MoonshotUsage `json:"usage,omitempty"`
+	} `json:"choices"`
+	// Usage 仅在开启stream_options.include_usage时出现在最后一个数据块中
+	Usage *MoonshotUsage `json:"usage,omitempty"`
+}
+
+// MoonshotError 定义Moonshot API的错误响应
+type MoonshotError struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
+
+// 将SDK的请求格式转换为Moonshot的格式
+func adaptRequest(request *api.Request) map[string]interface{} {
+	// Moonshot的API格式与OpenAI兼容
+	req := map[string]interface{}{
+		"model":    request.Model,
+		"messages": utils.NewChatMessages(request.Messages),
+	}
+
+	// 添加可选参数
+	if request.Temperature != nil {
+		req["temperature"] = *request.Temperature
+	}
+	if request.TopP != nil {
+		req["top_p"] = *request.TopP
+	}
+	if request.MaxTokens != nil {
+		req["max_tokens"] = *request.MaxTokens
+	}
+	if request.PresencePenalty != nil {
+		req["presence_penalty"] = *request.PresencePenalty
+	}
+	if request.FrequencyPenalty != nil {
+		req["frequency_penalty"] = *request.FrequencyPenalty
+	}
+	if len(request.Stop) > 0 {
+		req["stop"] = request.Stop
+	}
+	if request.Stream {
+		req["stream"] = request.Stream
+		// 让最后一个数据块携带令牌用量
+		req["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
+
+	// 函数调用
+	if len(request.Tools) > 0 {
+		req["tools"] = request.Tools
+	}
+	if request.ToolChoice != nil {
+		req["tool_choice"] = request.ToolChoice
+	}
+
+	// JSON模式
+	if request.ResponseFormat != nil {
+		req["response_format"] = map[string]interface{}{"type": request.ResponseFormat.Type}
+	}
+
+	// 添加其他自定义参数
+	for k, v := range request.ExtraParams {
+		req[k] = v
+	}
+
+	return req
+}
+
+// 将Moonshot的响应格式转换为SDK的通用格式
+func adaptResponse(moonshotResp *MoonshotResponse) *api.Response {
+	choices := make([]api.Choice, len(moonshotResp.Choices))
+	for i, choice := range moonshotResp.Choices {
+		choices[i] = api.Choice{
+			Index:           choice.Index,
+			Message:         choice.Message.Message(),
+			FinishReason:    api.NormalizeFinishReason(choice.FinishReason),
+			RawFinishReason: choice.FinishReason,
+		}
+	}
+
+	return &api.Response{
+		ID:      moonshotResp.ID,
+		Object:  moonshotResp.Object,
+		Created: moonshotResp.Created,
+		Model:   moonshotResp.Model,
+		Choices: choices,
+		Usage:   adaptUsage(moonshotResp.Usage),
+	}
+}
+
+// parseErrorBody 解析错误响应体
+func parseErrorBody(body []byte, statusCode int) *api.Error {
+	var moonshotErr MoonshotError
+	if err := json.Unmarshal(body, &moonshotErr); err != nil {
+		return api.NewError(api.ErrorTypeServer, fmt.Sprintf("API错误(状态码: %d)", statusCode), statusCode, nil)
+	}
+	return mapMoonshotError(&moonshotErr, statusCode)
+}
+
+// 将Moonshot的错误映射到SDK的错误类型
+func mapMoonshotError(moonshotErr *MoonshotError, statusCode int) *api.Error {
+	errType := api.ErrorTypeUnknown
+	switch moonshotErr.Error.Type {
+	case "invalid_request_error", "resource_not_found_error", "content_filter":
+		errType = api.ErrorTypeInvalidRequest
+	case "invalid_authentication_error", "incorrect_api_key_error", "permission_denied_error":
+		errType = api.ErrorTypeAuthentication
+	case "rate_limit_reached_error", "exceeded_current_quota_error", "engine_overloaded_error":
+		errType = api.ErrorTypeRateLimit
+	case "server_error", "unexpected_output":
+		errType = api.ErrorTypeServer
+	}
+
+	return &api.Error{
+		Type:       errType,
+		Message:    moonshotErr.Error.Message,
+		StatusCode: statusCode,
+		Code:       moonshotErr.Error.Type,
+	}
+}
+
+// moonshotResponseStream 实现流式响应接口
+type moonshotResponseStream struct {
+	*utils.StreamDecoder
+	rawReader io.ReadCloser
+}
+
+// parseStreamError 解析流中途返回的错误，数据不是错误时返回nil
+func parseStreamError(data []byte) *api.Error {
+	var moonshotErr MoonshotError
+	if err := json.Unmarshal(data, &moonshotErr); err != nil || moonshotErr.Error.Message == "" {
+		return nil
+	}
+	return mapMoonshotError(&moonshotErr, 0)
+}
+
+// decode 将一个SSE事件解码为响应块
+func (s *moonshotResponseStream) decode(event *utils.SSEEvent) (*api.ResponseChunk, error) {
+	// 解析JSON数据
+	var streamResp MoonshotStreamResponse
+	if err := json.Unmarshal([]byte(utils.ParseSSEData(event.Data)), &streamResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析流式响应失败", 0, err)
+	}
+
+	// 转换为SDK的通用格式
+	usage := streamResp.Usage
+	choices := make([]api.ChunkChoice, len(streamResp.Choices))
+	for i, choice := range streamResp.Choices {
+		choices[i] = api.ChunkChoice{
+			Index:           choice.Index,
+			Delta:           choice.Delta.Message(),
+			FinishReason:    api.NormalizeFinishReason(choice.FinishReason),
+			RawFinishReason: choice.FinishReason,
+		}
+		if usage == nil {
+			usage = choice.Usage
+		}
+	}
+
+	chunk := &api.ResponseChunk{
+		ID:      streamResp.ID,
+		Object:  streamResp.Object,
+		Created: streamResp.Created,
+		Model:   streamResp.Model,
+		Choices: choices,
+	}
+	if usage != nil {
+		adapted := adaptUsage(*usage)
+		chunk.Usage = &adapted
+	}
+	return chunk, nil
+}
+
+// Close 关闭流
+func (s *moonshotResponseStream) Close() error {
+	return s.rawReader.Close()
+}
@@ -0,0 +1,596 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// 批处理支持的接口
+const (
+	BatchEndpointChatCompletions = "/v1/chat/completions"
+	BatchEndpointEmbeddings      = "/v1/embeddings"
+)
+
+// 批处理任务状态
+const (
+	BatchStatusValidating = "validating"
+	BatchStatusFailed     = "failed"
+	BatchStatusInProgress = "in_progress"
+	BatchStatusFinalizing = "finalizing"
+	BatchStatusCompleted  = "completed"
+	BatchStatusExpired    = "expired"
+	BatchStatusCancelling = "cancelling"
+	BatchStatusCancelled  = "cancelled"
+)
+
+// defaultCompletionWindow 目前OpenAI只支持24小时的完成窗口
+const defaultCompletionWindow = "24h"
+
+// BatchRequest 定义批处理中的单个聊天请求
+type BatchRequest struct {
+	// CustomID 用于在结果中定位该请求，同一批次内必须唯一；为空时按序号生成
+	CustomID string
+	Request  *api.Request
+}
+
+// EmbeddingBatchRequest 定义批处理中的单个嵌入请求
+type EmbeddingBatchRequest struct {
+	CustomID string
+	Model    string
+	Input    string
+}
+
+// Batch 定义OpenAI的批处理任务
+type Batch struct {
+	ID               string             `json:"id"`
+	Object           string             `json:"object"`
+	Endpoint         string             `json:"endpoint"`
+	Errors           *BatchErrors       `json:"errors,omitempty"`
+	InputFileID      string             `json:"input_file_id"`
+	CompletionWindow string             `json:"completion_window"`
+	Status           string             `json:"status"`
+	OutputFileID     string             `json:"output_file_id,omitempty"`
+	ErrorFileID      string             `json:"error_file_id,omitempty"`
+	CreatedAt        int64              `json:"created_at"`
+	InProgressAt     int64              `json:"in_progress_at,omitempty"`
+	ExpiresAt        int64              `json:"expires_at,omitempty"`
+	CompletedAt      int64              `json:"completed_at,omitempty"`
+	FailedAt         int64              `json:"failed_at,omitempty"`
+	CancelledAt      int64              `json:"cancelled_at,omitempty"`
+	RequestCounts    BatchRequestCounts `json:"request_counts"`
+	Metadata         map[string]string  `json:"metadata,omitempty"`
+
+	// Warnings 提交时被忽略的参数，只有SubmitBatch返回的任务包含
+	Warnings []string `json:"-"`
+}
+
+// BatchErrors 定义批处理任务在校验阶段的错误
+type BatchErrors struct {
+	Data []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Param   string `json:"param,omitempty"`
+		Line    int    `json:"line,omitempty"`
+	} `json:"data"`
+}
+
+// BatchRequestCounts 定义批处理中各状态的请求数量
+type BatchRequestCounts struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+// Done 判断批处理任务是否已进入终态
+func (b *Batch) Done() bool {
+	switch b.Status {
+	case BatchStatusCompleted, BatchStatusFailed, BatchStatusExpired, BatchStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// BatchList 定义批处理任务列表
+type BatchList struct {
+	Data    []Batch `json:"data"`
+	HasMore bool    `json:"has_more"`
+	FirstID string  `json:"first_id"`
+	LastID  string  `json:"last_id"`
+}
+
+// BatchResult 定义批处理中单个请求的结果
+type BatchResult struct {
+	CustomID string
+	// StatusCode 该请求的HTTP状态码，请求未被执行时为0
+	StatusCode int
+	// Response 聊天请求成功时的响应，与Complete返回的结构一致
+	Response *api.Response
+	// Embedding 嵌入请求成功时的向量
+	Embedding []float32
+	// Error 请求失败时的错误
+	Error *api.Error
+}
+
+// WriteBatchFile 将聊天请求写为批处理所需的JSONL格式
+//
+// 每个请求与Complete一样检查参数，不支持的参数会被忽略，返回说明这些参数的警告。
+func WriteBatchFile(w io.Writer, requests []BatchRequest) ([]string, error) {
+	return writeBatchFile(w, requests, false)
+}
+
+// writeBatchFile 写入聊天请求，strict为true时包含不支持的参数返回错误
+func writeBatchFile(w io.Writer, requests []BatchRequest, strict bool) ([]string, error) {
+	encoder := json.NewEncoder(w)
+	var warnings []string
+	for i, item := range requests {
+		if err := validateRequest(item.Request); err != nil {
+			return nil, err
+		}
+
+		customID := batchCustomID(item.CustomID, i)
+		itemWarnings, err := api.CheckParams("OpenAI批处理", item.Request, supportedParams, strict)
+		if err != nil {
+			return nil, err
+		}
+		for _, warning := range itemWarnings {
+			warnings = append(warnings, customID+": "+warning)
+		}
+
+		// 批处理不支持流式输出
+		body := adaptRequest(item.Request)
+		delete(body, "stream")
+
+		if err := encoder.Encode(batchLine(customID, i, BatchEndpointChatCompletions, body)); err != nil {
+			return nil, api.NewError(api.ErrorTypeInvalidRequest, "无法序列化批处理请求", 0, err)
+		}
+	}
+	return warnings, nil
+}
+
+// WriteEmbeddingBatchFile 将嵌入请求写为批处理所需的JSONL格式
+func WriteEmbeddingBatchFile(w io.Writer, requests []EmbeddingBatchRequest) error {
+	encoder := json.NewEncoder(w)
+	for i, item := range requests {
+		if item.Model == "" {
+			return api.NewError(api.ErrorTypeInvalidRequest, "模型不能为空", 0, nil)
+		}
+
+		body := map[string]interface{}{
+			"model": item.Model,
+			"input": item.Input,
+		}
+		if err := encoder.Encode(batchLine(item.CustomID, i, BatchEndpointEmbeddings, body)); err != nil {
+			return api.NewError(api.ErrorTypeInvalidRequest, "无法序列化批处理请求", 0, err)
+		}
+	}
+	return nil
+}
+
+// batchCustomID 返回请求的custom_id，为空时按序号生成
+func batchCustomID(customID string, index int) string {
+	if customID == "" {
+		return fmt.Sprintf("request-%d", index)
+	}
+	return customID
+}
+
+// 构造批处理文件中的一行
+func batchLine(customID string, index int, endpoint string, body interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"custom_id": batchCustomID(customID, index),
+		"method":    http.MethodPost,
+		"url":       endpoint,
+		"body":      body,
+	}
+}
+
+// SubmitBatch 将聊天请求写为JSONL文件上传并创建批处理任务
+//
+// 每个请求与Complete一样检查参数：严格模式下包含不支持的参数时返回错误，否则忽略这些参数并在返回任务的Warnings中说明。
+func (c *Client) SubmitBatch(ctx context.Context, requests []BatchRequest, metadata map[string]string) (*Batch, error) {
+	if len(requests) == 0 {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "批处理请求不能为空", 0, nil)
+	}
+
+	var buf bytes.Buffer
+	warnings, err := writeBatchFile(&buf, requests, c.strictParams)
+	if err != nil {
+		return nil, err
+	}
+	batch, err := c.submitBatchFile(ctx, &buf, BatchEndpointChatCompletions, metadata)
+	if err != nil {
+		return nil, err
+	}
+	batch.Warnings = warnings
+	return batch, nil
+}
+
+// SubmitEmbeddingBatch 将嵌入请求写为JSONL文件上传并创建批处理任务
+func (c *Client) SubmitEmbeddingBatch(ctx context.Context, requests []EmbeddingBatchRequest, metadata map[string]string) (*Batch, error) {
+	if len(requests) == 0 {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "批处理请求不能为空", 0, nil)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteEmbeddingBatchFile(&buf, requests); err != nil {
+		return nil, err
+	}
+	return c.submitBatchFile(ctx, &buf, BatchEndpointEmbeddings, metadata)
+}
+
+// 上传批处理文件并创建任务
+func (c *Client) submitBatchFile(ctx context.Context, content io.Reader, endpoint string, metadata map[string]string) (*Batch, error) {
+	file, err := c.UploadFile(ctx, "batch.jsonl", FilePurposeBatch, content)
+	if err != nil {
+		return nil, err
+	}
+	return c.CreateBatch(ctx, file.ID, endpoint, metadata)
+}
+
+// CreateBatch 基于已上传的JSONL文件创建批处理任务
+func (c *Client) CreateBatch(ctx context.Context, inputFileID, endpoint string, metadata map[string]string) (*Batch, error) {
+	if inputFileID == "" {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "输入文件ID不能为空", 0, nil)
+	}
+	if endpoint == "" {
+		endpoint = BatchEndpointChatCompletions
+	}
+
+	payload := map[string]interface{}{
+		"input_file_id":     inputFileID,
+		"endpoint":          endpoint,
+		"completion_window": defaultCompletionWindow,
+	}
+	if len(metadata) > 0 {
+		payload["metadata"] = metadata
+	}
+
+	var batch Batch
+	if err := c.doJSON(ctx, http.MethodPost, "/batches", payload, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// GetBatch 查询批处理任务的状态
+func (c *Client) GetBatch(ctx context.Context, batchID string) (*Batch, error) {
+	if batchID == "" {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "批处理ID不能为空", 0, nil)
+	}
+
+	var batch Batch
+	if err := c.doJSON(ctx, http.MethodGet, "/batches/"+url.PathEscape(batchID), nil, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// ListBatches 列出批处理任务，after为上一页最后一个任务的ID，limit为0时使用服务端默认值
+func (c *Client) ListBatches(ctx context.Context, after string, limit int) (*BatchList, error) {
+	query := url.Values{}
+	if after != "" {
+		query.Set("after", after)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	path := "/batches"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var list BatchList
+	if err := c.doJSON(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// CancelBatch 取消批处理任务
+func (c *Client) CancelBatch(ctx context.Context, batchID string) (*Batch, error) {
+	if batchID == "" {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "批处理ID不能为空", 0, nil)
+	}
+
+	var batch Batch
+	if err := c.doJSON(ctx, http.MethodPost, "/batches/"+url.PathEscape(batchID)+"/cancel", nil, &batch); err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// GetBatchResults 读取批处理任务的结果，依次返回输出文件和错误文件中的记录
+//
+// 结果文件通过GetFileContent下载，不限制总时长，只受StreamTimeouts的连接和空闲超时约束。
+//
+// 调用方读取完毕后需要调用Close。
+func (c *Client) GetBatchResults(ctx context.Context, batch *Batch) (*BatchResultsReader, error) {
+	if batch == nil {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "批处理任务不能为空", 0, nil)
+	}
+
+	var fileIDs []string
+	if batch.OutputFileID != "" {
+		fileIDs = append(fileIDs, batch.OutputFileID)
+	}
+	if batch.ErrorFileID != "" {
+		fileIDs = append(fileIDs, batch.ErrorFileID)
+	}
+	if len(fileIDs) == 0 {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, fmt.Sprintf("批处理任务%s没有可读取的结果(状态: %s)", batch.ID, batch.Status), 0, nil)
+	}
+
+	return &BatchResultsReader{
+		ctx:      ctx,
+		client:   c,
+		fileIDs:  fileIDs,
+		endpoint: batch.Endpoint,
+	}, nil
+}
+
+// BatchResultsReader 逐条读取批处理结果
+type BatchResultsReader struct {
+	ctx      context.Context
+	client   *Client
+	fileIDs  []string
+	endpoint string
+
+	current io.ReadCloser
+	scanner *bufio.Scanner
+}
+
+// openaiBatchResult 定义输出文件和错误文件中的一行
+type openaiBatchResult struct {
+	ID       string `json:"id"`
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		RequestID  string          `json:"request_id"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// openaiEmbeddingResponse 定义嵌入接口的响应结构
+type openaiEmbeddingResponse struct {
+	Data []struct {
+		Embedding []float32 `json:"embedding"`
+		Index     int       `json:"index"`
+	} `json:"data"`
+}
+
+// maxBatchLineSize 结果文件中单行的最大长度
+const maxBatchLineSize = 16 * 1024 * 1024
+
+// Next 读取下一条结果，全部读取完毕后返回io.EOF
+func (r *BatchResultsReader) Next() (*BatchResult, error) {
+	for {
+		if r.scanner == nil {
+			if len(r.fileIDs) == 0 {
+				return nil, io.EOF
+			}
+			content, err := r.client.GetFileContent(r.ctx, r.fileIDs[0])
+			if err != nil {
+				return nil, err
+			}
+			r.fileIDs = r.fileIDs[1:]
+			r.current = content
+			r.scanner = bufio.NewScanner(content)
+			r.scanner.Buffer(make([]byte, 64*1024), maxBatchLineSize)
+		}
+
+		if !r.scanner.Scan() {
+			err := r.scanner.Err()
+			r.current.Close()
+			r.current, r.scanner = nil, nil
+			if err != nil {
+				// 下载超时等读取错误原样返回
+				var apiErr *api.Error
+				if errors.As(err, &apiErr) {
+					return nil, apiErr
+				}
+				return nil, api.NewError(api.ErrorTypeServer, "读取批处理结果失败", 0, err)
+			}
+			continue
+		}
+
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		return r.parseLine(line)
+	}
+}
+
+// 解析结果文件中的一行
+func (r *BatchResultsReader) parseLine(line []byte) (*BatchResult, error) {
+	var item openaiBatchResult
+	if err := json.Unmarshal(line, &item); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析批处理结果失败", 0, err)
+	}
+
+	result := &BatchResult{CustomID: item.CustomID}
+
+	// 请求未被执行，例如批次过期或被取消
+	if item.Error != nil {
+		result.Error = &api.Error{
+			Type:    api.ErrorTypeServer,
+			Message: item.Error.Message,
+			Code:    item.Error.Code,
+		}
+		return result, nil
+	}
+	if item.Response == nil {
+		return result, nil
+	}
+
+	result.StatusCode = item.Response.StatusCode
+	if item.Response.StatusCode != http.StatusOK {
+		result.Error = parseErrorBody(item.Response.Body, item.Response.StatusCode)
+		return result, nil
+	}
+
+	switch r.endpoint {
+	case BatchEndpointEmbeddings:
+		var embedResp openaiEmbeddingResponse
+		if err := json.Unmarshal(item.Response.Body, &embedResp); err != nil {
+			return nil, api.NewError(api.ErrorTypeServer, "解析嵌入响应失败", item.Response.StatusCode, err)
+		}
+		if len(embedResp.Data) > 0 {
+			result.Embedding = embedResp.Data[0].Embedding
+		}
+	default:
+		var openaiResp OpenAIResponse
+		if err := json.Unmarshal(item.Response.Body, &openaiResp); err != nil {
+			return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", item.Response.StatusCode, err)
+		}
+		result.Response = adaptResponse(&openaiResp)
+	}
+	return result, nil
+}
+
+// Close 关闭当前打开的结果文件
+func (r *BatchResultsReader) Close() error {
+	r.fileIDs = nil
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current, r.scanner = nil, nil
+	return err
+}
+
+// BatchClient 返回基于Batch API的统一批处理客户端，只支持聊天补全请求
+func (c *Client) BatchClient() api.BatchClient {
+	return &batchClient{client: c}
+}
+
+// batchClient 将Batch API适配为api.BatchClient
+type batchClient struct {
+	client *Client
+}
+
+// Submit 提交一批聊天请求
+func (b *batchClient) Submit(ctx context.Context, requests []api.BatchRequest) (*api.BatchJob, error) {
+	items := make([]BatchRequest, len(requests))
+	for i, item := range requests {
+		items[i] = BatchRequest{CustomID: item.CustomID, Request: item.Request}
+	}
+
+	batch, err := b.client.SubmitBatch(ctx, items, nil)
+	if err != nil {
+		return nil, err
+	}
+	return adaptBatchJob(batch), nil
+}
+
+// Status 查询任务状态
+func (b *batchClient) Status(ctx context.Context, jobID string) (*api.BatchJob, error) {
+	batch, err := b.client.GetBatch(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return adaptBatchJob(batch), nil
+}
+
+// Cancel 取消任务
+func (b *batchClient) Cancel(ctx context.Context, jobID string) error {
+	_, err := b.client.CancelBatch(ctx, jobID)
+	return err
+}
+
+// Results 读取已结束任务的结果
+func (b *batchClient) Results(ctx context.Context, jobID string) (api.BatchResultIterator, error) {
+	batch, err := b.client.GetBatch(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := b.client.GetBatchResults(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+	return &batchResultIterator{reader: reader}, nil
+}
+
+// batchResultIterator 将BatchResultsReader适配为api.BatchResultIterator
+type batchResultIterator struct {
+	reader *BatchResultsReader
+}
+
+// Next 读取下一条结果
+func (it *batchResultIterator) Next() (*api.BatchResult, error) {
+	result, err := it.reader.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	converted := &api.BatchResult{
+		CustomID: result.CustomID,
+		Response: result.Response,
+	}
+	if result.Error != nil {
+		converted.Error = result.Error
+	}
+	return converted, nil
+}
+
+// Close 关闭结果流
+func (it *batchResultIterator) Close() error {
+	return it.reader.Close()
+}
+
+// 将Batch转换为统一的任务结构
+func adaptBatchJob(batch *Batch) *api.BatchJob {
+	job := &api.BatchJob{
+		ID:        batch.ID,
+		RawStatus: batch.Status,
+		Total:     batch.RequestCounts.Total,
+		Succeeded: batch.RequestCounts.Completed,
+		Failed:    batch.RequestCounts.Failed,
+		CreatedAt: time.Unix(batch.CreatedAt, 0),
+		Warnings:  batch.Warnings,
+	}
+
+	var endedAt int64
+	switch batch.Status {
+	case BatchStatusValidating:
+		job.Status = api.BatchStatusPending
+	case BatchStatusInProgress, BatchStatusFinalizing:
+		job.Status = api.BatchStatusInProgress
+	case BatchStatusCompleted:
+		job.Status = api.BatchStatusCompleted
+		endedAt = batch.CompletedAt
+	case BatchStatusFailed:
+		job.Status = api.BatchStatusFailed
+		endedAt = batch.FailedAt
+	case BatchStatusExpired:
+		job.Status = api.BatchStatusExpired
+		endedAt = batch.ExpiresAt
+	case BatchStatusCancelling:
+		job.Status = api.BatchStatusCancelling
+	case BatchStatusCancelled:
+		job.Status = api.BatchStatusCancelled
+		endedAt = batch.CancelledAt
+	default:
+		job.Status = api.BatchStatusPending
+	}
+	if endedAt > 0 {
+		job.EndedAt = time.Unix(endedAt, 0)
+	}
+	return job
+}
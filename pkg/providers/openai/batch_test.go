@@ -0,0 +1,170 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+func TestSubmitBatchChecksParams(t *testing.T) {
+	topK := 40
+	requests := []BatchRequest{
+		{CustomID: "q1", Request: &api.Request{Model: "gpt-4o", Messages: []api.Message{{Role: api.RoleUser, Content: "hi"}}}},
+		{Request: &api.Request{Model: "gpt-4o", Messages: []api.Message{{Role: api.RoleUser, Content: "hi"}}, TopK: &topK}},
+	}
+
+	tests := []struct {
+		name         string
+		strict       bool
+		wantErr      bool
+		wantWarnings []string
+	}{
+		{name: "lenient", wantWarnings: []string{"request-1: OpenAI批处理不支持参数top_k，已忽略"}},
+		{name: "strict", strict: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case "/files":
+					_, _ = io.WriteString(w, `{"id":"file-1","purpose":"batch"}`)
+				case "/batches":
+					_, _ = io.WriteString(w, `{"id":"batch-1","status":"validating"}`)
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			client, err := NewClient(func(options *api.ClientOptions) {
+				options.APIKey = "test-key"
+				options.BaseURL = server.URL
+				options.StrictParams = tt.strict
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			batch, err := client.(*Client).SubmitBatch(context.Background(), requests, nil)
+			if tt.wantErr {
+				var apiErr *api.Error
+				if !errors.As(err, &apiErr) || apiErr.Code != api.ErrorCodeUnsupportedParam {
+					t.Fatalf("err = %v, want unsupported parameter error", err)
+				}
+				if n := atomic.LoadInt32(&calls); n != 0 {
+					t.Errorf("%d requests sent in strict mode, want 0", n)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.Join(batch.Warnings, "\n") != strings.Join(tt.wantWarnings, "\n") {
+				t.Errorf("warnings = %q, want %q", batch.Warnings, tt.wantWarnings)
+			}
+			if job := adaptBatchJob(batch); len(job.Warnings) != len(tt.wantWarnings) {
+				t.Errorf("job warnings = %q, want %q", job.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestWriteBatchFileOmitsTopK(t *testing.T) {
+	topK := 40
+	var buf bytes.Buffer
+	warnings, err := WriteBatchFile(&buf, []BatchRequest{
+		{CustomID: "q1", Request: &api.Request{Model: "gpt-4o", Messages: []api.Message{{Role: api.RoleUser, Content: "hi"}}, TopK: &topK}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("warnings = %q, want 1 warning", warnings)
+	}
+	if strings.Contains(buf.String(), "top_k") {
+		t.Errorf("batch file contains top_k: %s", buf.String())
+	}
+}
+
+func TestGetFileContentOutlivesClientTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		// 总时长超过客户端的1秒超时，但每次间隔都小于空闲超时
+		for i := 0; i < 6; i++ {
+			_, _ = io.WriteString(w, `{"custom_id":"q"}`+"\n")
+			flusher.Flush()
+			time.Sleep(250 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(func(options *api.ClientOptions) {
+		options.APIKey = "test-key"
+		options.BaseURL = server.URL
+		options.Timeout = 1
+		options.StreamTimeouts.Idle = time.Second
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := client.(*Client).GetFileContent(context.Background(), "file-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer content.Close()
+	data, err := io.ReadAll(content)
+	if err != nil {
+		t.Fatalf("read failed after %d bytes: %v", len(data), err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 6 {
+		t.Errorf("got %d lines, want 6", lines)
+	}
+}
+
+func TestGetFileContentIdleTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, `{"custom_id":"q"}`+"\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client, err := NewClient(func(options *api.ClientOptions) {
+		options.APIKey = "test-key"
+		options.BaseURL = server.URL
+		options.StreamTimeouts.Idle = 100 * time.Millisecond
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := client.(*Client).GetFileContent(context.Background(), "file-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer content.Close()
+	_, err = io.ReadAll(content)
+	var apiErr *api.Error
+	if !errors.As(err, &apiErr) || apiErr.TimeoutPhase() != api.TimeoutPhaseIdle {
+		t.Fatalf("err = %v, want idle timeout", err)
+	}
+}
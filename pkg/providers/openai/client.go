@@ -19,6 +19,11 @@ type Client struct {
 	baseURL    string
 	httpClient *http.Client
 	maxRetries int
+	// streamClient 用于流式请求，不设置总超时，由streamTimeouts分阶段控制
+	streamClient   *http.Client
+	streamTimeouts api.StreamTimeouts
+	// strictParams 为true时不支持的参数返回错误，否则忽略并在响应中附带警告
+	strictParams bool
 }
 
 // 默认配置
@@ -57,10 +62,13 @@ func NewClient(options ...api.ClientOption) (api.LLMClient, error) {
 	}
 
 	return &Client{
-		apiKey:     clientOptions.APIKey,
-		baseURL:    clientOptions.BaseURL,
-		httpClient: httpClient,
-		maxRetries: clientOptions.MaxRetries,
+		apiKey:         clientOptions.APIKey,
+		baseURL:        clientOptions.BaseURL,
+		httpClient:     httpClient,
+		streamClient:   utils.NewStreamClient(httpClient),
+		streamTimeouts: clientOptions.StreamTimeouts.WithDefaults(time.Duration(clientOptions.Timeout) * time.Second),
+		strictParams:   clientOptions.StrictParams,
+		maxRetries:     clientOptions.MaxRetries,
 	}, nil
 }
 
@@ -71,6 +79,12 @@ func (c *Client) Complete(ctx context.Context, request *api.Request) (*api.Respo
 		return nil, err
 	}
 
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
 	// 准备请求体
 	reqBody, err := json.Marshal(adaptRequest(request))
 	if err != nil {
@@ -115,7 +129,9 @@ func (c *Client) Complete(ctx context.Context, request *api.Request) (*api.Respo
 		return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", resp.StatusCode, err)
 	}
 
-	return adaptResponse(&openaiResp), nil
+	response := adaptResponse(&openaiResp)
+	response.Warnings = warnings
+	return response, nil
 }
 
 // CompleteStream 发送请求并获取流式响应
@@ -125,6 +141,12 @@ func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.
 		return nil, err
 	}
 
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
 	// 设置流式标志
 	reqCopy := *request
 	reqCopy.Stream = true
@@ -147,9 +169,9 @@ func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.
 	req.Header.Set("Accept", "text/event-stream")
 
 	// 发送请求
-	resp, err := c.httpClient.Do(req)
+	resp, err := utils.DoStreamRequest(c.streamClient, req, c.streamTimeouts)
 	if err != nil {
-		return nil, api.NewError(api.ErrorTypeConnection, "HTTP请求失败", 0, err)
+		return nil, err
 	}
 
 	// 检查HTTP状态码
@@ -164,10 +186,15 @@ func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.
 		return nil, mapOpenAIError(&openaiErr, resp.StatusCode)
 	}
 
-	return &openaiResponseStream{
-		reader:    utils.NewSSEReader(resp.Body),
+	stream := &openaiResponseStream{
+		StreamDecoder: utils.NewStreamDecoder(resp.Body, decodeStreamEvent, func(options *utils.StreamDecoderOptions) {
+			options.DoneData = "[DONE]"
+			options.ParseError = parseStreamError
+			options.InlineErrors = true
+		}),
 		rawReader: resp.Body,
-	}, nil
+	}
+	return utils.WithWarnings(stream, warnings), nil
 }
 
 // Embedding 获取文本的嵌入向量
@@ -190,6 +217,18 @@ func validateRequest(request *api.Request) error {
 	return nil
 }
 
+// supportedParams OpenAI支持的可选参数
+var supportedParams = api.NewParamSet(
+	api.ParamTemperature, api.ParamTopP, api.ParamMaxTokens, api.ParamPresencePenalty, api.ParamFrequencyPenalty, api.ParamStop,
+	api.ParamN, api.ParamSeed, api.ParamLogitBias, api.ParamLogprobs, api.ParamTopLogprobs, api.ParamUser, api.ParamMetadata,
+	api.ParamTools, api.ParamToolChoice, api.ParamParallelToolCalls, api.ParamResponseFormat, api.ParamReasoningEffort,
+)
+
+// 检查请求中OpenAI不支持的参数，非严格模式下返回忽略这些参数的警告
+func (c *Client) checkParams(request *api.Request) ([]string, error) {
+	return api.CheckParams("OpenAI", request, supportedParams, c.strictParams)
+}
+
 // OpenAIResponse 定义OpenAI API的响应结构
 type OpenAIResponse struct {
 	ID      string `json:"id"`
@@ -199,16 +238,35 @@ type OpenAIResponse struct {
 	Choices []struct {
 		Index   int `json:"index"`
 		Message struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
+			Role      string         `json:"role"`
+			Content   string         `json:"content"`
+			ToolCalls []api.ToolCall `json:"tool_calls,omitempty"`
 		} `json:"message"`
-		FinishReason string `json:"finish_reason"`
+		FinishReason string          `json:"finish_reason"`
+		Logprobs     *OpenAILogprobs `json:"logprobs,omitempty"`
 	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage"`
+	Usage OpenAIUsage `json:"usage"`
+}
+
+// OpenAIUsage 定义OpenAI的令牌用量
+type OpenAIUsage struct {
+	PromptTokens        int `json:"prompt_tokens"`
+	CompletionTokens    int `json:"completion_tokens"`
+	TotalTokens         int `json:"total_tokens"`
+	PromptTokensDetails struct {
+		// CachedTokens 命中自动提示缓存的token数，包含在PromptTokens中
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"prompt_tokens_details"`
+}
+
+// 将OpenAI的令牌用量转换为SDK格式
+func adaptUsage(usage OpenAIUsage) api.Usage {
+	return api.Usage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+		CacheReadTokens:  usage.PromptTokensDetails.CachedTokens,
+	}
 }
 
 // OpenAIError 定义OpenAI API的错误响应
@@ -225,7 +283,7 @@ type OpenAIError struct {
 func adaptRequest(request *api.Request) map[string]interface{} {
 	req := map[string]interface{}{
 		"model":    request.Model,
-		"messages": request.Messages,
+		"messages": utils.NewChatMessages(request.Messages),
 	}
 
 	// 添加可选参数
@@ -247,8 +305,50 @@ func adaptRequest(request *api.Request) map[string]interface{} {
 	if len(request.Stop) > 0 {
 		req["stop"] = request.Stop
 	}
+	if request.N > 1 {
+		req["n"] = request.N
+	}
+	if request.Seed != nil {
+		req["seed"] = *request.Seed
+	}
+	if len(request.LogitBias) > 0 {
+		req["logit_bias"] = request.LogitBias
+	}
+	if request.Logprobs {
+		req["logprobs"] = true
+		if request.TopLogprobs > 0 {
+			req["top_logprobs"] = request.TopLogprobs
+		}
+	}
+	if request.User != "" {
+		req["user"] = request.User
+	}
+	if len(request.Metadata) > 0 {
+		req["metadata"] = request.Metadata
+	}
+	if request.ReasoningEffort != "" {
+		req["reasoning_effort"] = request.ReasoningEffort
+	}
 	if request.Stream {
 		req["stream"] = request.Stream
+		// 让最后一个数据块携带令牌用量
+		req["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
+
+	// 函数调用
+	if len(request.Tools) > 0 {
+		req["tools"] = request.Tools
+	}
+	if request.ToolChoice != nil {
+		req["tool_choice"] = request.ToolChoice
+	}
+	if request.ParallelToolCalls != nil {
+		req["parallel_tool_calls"] = *request.ParallelToolCalls
+	}
+
+	// JSON模式
+	if request.ResponseFormat != nil {
+		req["response_format"] = request.ResponseFormat
 	}
 
 	// 添加其他自定义参数
@@ -266,10 +366,13 @@ func adaptResponse(openaiResp *OpenAIResponse) *api.Response {
 		choices[i] = api.Choice{
 			Index: choice.Index,
 			Message: api.Message{
-				Role:    api.Role(choice.Message.Role),
-				Content: choice.Message.Content,
+				Role:      api.Role(choice.Message.Role),
+				Content:   choice.Message.Content,
+				ToolCalls: choice.Message.ToolCalls,
 			},
-			FinishReason: choice.FinishReason,
+			FinishReason:    api.NormalizeFinishReason(choice.FinishReason),
+			RawFinishReason: choice.FinishReason,
+			Logprobs:        choice.Logprobs.tokens(),
 		}
 	}
 
@@ -279,11 +382,7 @@ func adaptResponse(openaiResp *OpenAIResponse) *api.Response {
 		Created: openaiResp.Created,
 		Model:   openaiResp.Model,
 		Choices: choices,
-		Usage: api.Usage{
-			PromptTokens:     openaiResp.Usage.PromptTokens,
-			CompletionTokens: openaiResp.Usage.CompletionTokens,
-			TotalTokens:      openaiResp.Usage.TotalTokens,
-		},
+		Usage:   adaptUsage(openaiResp.Usage),
 	}
 }
 
@@ -312,10 +411,23 @@ func mapOpenAIError(openaiErr *OpenAIError, statusCode int) *api.Error {
 
 // openaiResponseStream 实现流式响应接口
 type openaiResponseStream struct {
-	reader    *utils.SSEReader
+	*utils.StreamDecoder
 	rawReader io.ReadCloser
 }
 
+// OpenAILogprobs 定义候选中输出token的对数概率
+type OpenAILogprobs struct {
+	Content []api.TokenLogprob `json:"content"`
+}
+
+// tokens 返回各token的对数概率，未返回对数概率时为nil
+func (l *OpenAILogprobs) tokens() []api.TokenLogprob {
+	if l == nil {
+		return nil
+	}
+	return l.Content
+}
+
 // OpenAIStreamResponse 定义OpenAI API的流式响应结构
 type OpenAIStreamResponse struct {
 	ID      string `json:"id"`
@@ -325,31 +437,28 @@ type OpenAIStreamResponse struct {
 	Choices []struct {
 		Index int `json:"index"`
 		Delta struct {
-			Content string `json:"content,omitempty"`
-			Role    string `json:"role,omitempty"`
+			Content   string         `json:"content,omitempty"`
+			Role      string         `json:"role,omitempty"`
+			ToolCalls []api.ToolCall `json:"tool_calls,omitempty"`
 		} `json:"delta"`
-		FinishReason string `json:"finish_reason,omitempty"`
+		FinishReason string          `json:"finish_reason,omitempty"`
+		Logprobs     *OpenAILogprobs `json:"logprobs,omitempty"`
 	} `json:"choices"`
+	// Usage 仅在开启stream_options.include_usage时出现在最后一个数据块中
+	Usage *OpenAIUsage `json:"usage,omitempty"`
 }
 
-// Recv 实现ResponseStream接口，读取下一个响应块
-func (s *openaiResponseStream) Recv() (*api.ResponseChunk, error) {
-	event, err := s.reader.ReadEvent()
-	if err != nil {
-		if err == io.EOF {
-			return nil, io.EOF
-		}
-		return nil, api.NewError(api.ErrorTypeServer, "读取SSE事件失败", 0, err)
-	}
-
-	// 如果不是data字段，或者数据为空，则跳过
-	if event.Data == "" || event.Data == "[DONE]" {
-		if event.Data == "[DONE]" {
-			return nil, io.EOF
-		}
-		return s.Recv() // 递归调用直到获取到有效数据或EOF
+// parseStreamError 解析流中途返回的错误，数据不是错误时返回nil
+func parseStreamError(data []byte) *api.Error {
+	var openaiErr OpenAIError
+	if err := json.Unmarshal(data, &openaiErr); err != nil || openaiErr.Error.Message == "" {
+		return nil
 	}
+	return mapOpenAIError(&openaiErr, 0)
+}
 
+// decodeStreamEvent 将一个SSE事件解码为响应块
+func decodeStreamEvent(event *utils.SSEEvent) (*api.ResponseChunk, error) {
 	// 解析JSON数据
 	var streamResp OpenAIStreamResponse
 	if err := json.Unmarshal([]byte(utils.ParseSSEData(event.Data)), &streamResp); err != nil {
@@ -362,20 +471,28 @@ func (s *openaiResponseStream) Recv() (*api.ResponseChunk, error) {
 		choices[i] = api.ChunkChoice{
 			Index: choice.Index,
 			Delta: api.Message{
-				Role:    api.Role(choice.Delta.Role),
-				Content: choice.Delta.Content,
+				Role:      api.Role(choice.Delta.Role),
+				Content:   choice.Delta.Content,
+				ToolCalls: choice.Delta.ToolCalls,
 			},
-			FinishReason: choice.FinishReason,
+			FinishReason:    api.NormalizeFinishReason(choice.FinishReason),
+			RawFinishReason: choice.FinishReason,
+			Logprobs:        choice.Logprobs.tokens(),
 		}
 	}
 
-	return &api.ResponseChunk{
+	chunk := &api.ResponseChunk{
 		ID:      streamResp.ID,
 		Object:  streamResp.Object,
 		Created: streamResp.Created,
 		Model:   streamResp.Model,
 		Choices: choices,
-	}, nil
+	}
+	if streamResp.Usage != nil {
+		usage := adaptUsage(*streamResp.Usage)
+		chunk.Usage = &usage
+	}
+	return chunk, nil
 }
 
 // Close 关闭流
@@ -0,0 +1,96 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// newTestClient 创建请求发往server的客户端
+func newTestClient(t *testing.T, server *httptest.Server) api.LLMClient {
+	t.Helper()
+	client, err := NewClient(func(options *api.ClientOptions) {
+		options.APIKey = "test-key"
+		options.BaseURL = server.URL
+		options.MaxRetries = 0
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestCompleteSendsOnlyOpenAIMessageFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []api.Message
+		want    []map[string]interface{}
+	}{
+		{
+			name: "deepseek reasoner history",
+			history: []api.Message{
+				{Role: api.RoleUser, Content: "1+1=?"},
+				{Role: api.RoleAssistant, Content: "2", ReasoningContent: "先计算1+1"},
+				{Role: api.RoleUser, Content: "再加1呢？"},
+			},
+			want: []map[string]interface{}{
+				{"role": "user", "content": "1+1=?"},
+				{"role": "assistant", "content": "2"},
+				{"role": "user", "content": "再加1呢？"},
+			},
+		},
+		{
+			name: "anthropic thinking history with parts",
+			history: []api.Message{
+				{Role: api.RoleSystem, Parts: []api.ContentPart{{Type: api.ContentPartTypeText, Text: "你是助手。", CacheControl: &api.CacheControl{Type: api.CacheControlEphemeral}}}},
+				{Role: api.RoleUser, Content: "查天气"},
+				{
+					Role:             api.RoleAssistant,
+					ReasoningContent: "需要调用工具",
+					ThinkingBlocks:   []api.ThinkingBlock{{Type: api.ThinkingTypeThinking, Thinking: "需要调用工具", Signature: "sig"}},
+					ToolCalls:        []api.ToolCall{{ID: "toolu_1", Type: api.ToolTypeFunction, Function: api.FunctionCall{Name: "weather", Arguments: `{"city":"北京"}`}}},
+				},
+				{Role: api.RoleTool, Content: "晴", Name: "weather", ToolCallID: "toolu_1"},
+			},
+			want: []map[string]interface{}{
+				{"role": "system", "content": "你是助手。"},
+				{"role": "user", "content": "查天气"},
+				{"role": "assistant", "content": "", "tool_calls": []interface{}{
+					map[string]interface{}{"id": "toolu_1", "type": "function", "function": map[string]interface{}{"name": "weather", "arguments": `{"city":"北京"}`}},
+				}},
+				{"role": "tool", "content": "晴", "name": "weather", "tool_call_id": "toolu_1"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body struct {
+				Messages []map[string]interface{} `json:"messages"`
+			}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				if err := json.Unmarshal(data, &body); err != nil {
+					t.Errorf("invalid request body: %v", err)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, `{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`)
+			}))
+			defer server.Close()
+
+			_, err := newTestClient(t, server).Complete(context.Background(), &api.Request{Model: "gpt-4o", Messages: tt.history})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(body.Messages, tt.want) {
+				t.Errorf("messages = %v, want %v", body.Messages, tt.want)
+			}
+		})
+	}
+}
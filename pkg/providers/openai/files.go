@@ -0,0 +1,209 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/utils"
+)
+
+// 文件用途
+const (
+	FilePurposeBatch      = "batch"
+	FilePurposeBatchOut   = "batch_output"
+	FilePurposeFineTune   = "fine-tune"
+	FilePurposeAssistants = "assistants"
+	FilePurposeVision     = "vision"
+	FilePurposeUserData   = "user_data"
+)
+
+// File 定义OpenAI Files API中的文件对象
+type File struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int64  `json:"bytes"`
+	CreatedAt int64  `json:"created_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+}
+
+// UploadFile 上传文件，purpose为FilePurpose*常量之一
+func (c *Client) UploadFile(ctx context.Context, filename, purpose string, content io.Reader) (*File, error) {
+	if filename == "" {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "文件名不能为空", 0, nil)
+	}
+	if purpose == "" {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "文件用途不能为空", 0, nil)
+	}
+
+	// 构造multipart请求体
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if err := writer.WriteField("purpose", purpose); err != nil {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "构造上传请求失败", 0, err)
+	}
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "构造上传请求失败", 0, err)
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "读取上传内容失败", 0, err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "构造上传请求失败", 0, err)
+	}
+
+	// 创建HTTP请求
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/files", &buf)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "创建HTTP请求失败", 0, err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	var file File
+	if err := c.sendJSON(req, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// ListFiles 列出已上传的文件，purpose为空时返回所有用途的文件
+func (c *Client) ListFiles(ctx context.Context, purpose string) ([]File, error) {
+	path := "/files"
+	if purpose != "" {
+		path += "?purpose=" + url.QueryEscape(purpose)
+	}
+
+	var list struct {
+		Data []File `json:"data"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return nil, err
+	}
+	return list.Data, nil
+}
+
+// GetFile 获取文件信息
+func (c *Client) GetFile(ctx context.Context, fileID string) (*File, error) {
+	if fileID == "" {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "文件ID不能为空", 0, nil)
+	}
+
+	var file File
+	if err := c.doJSON(ctx, http.MethodGet, "/files/"+url.PathEscape(fileID), nil, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// DeleteFile 删除文件
+func (c *Client) DeleteFile(ctx context.Context, fileID string) error {
+	if fileID == "" {
+		return api.NewError(api.ErrorTypeInvalidRequest, "文件ID不能为空", 0, nil)
+	}
+
+	var result struct {
+		Deleted bool `json:"deleted"`
+	}
+	if err := c.doJSON(ctx, http.MethodDelete, "/files/"+url.PathEscape(fileID), nil, &result); err != nil {
+		return err
+	}
+	if !result.Deleted {
+		return api.NewError(api.ErrorTypeServer, fmt.Sprintf("文件%s未被删除", fileID), 0, nil)
+	}
+	return nil
+}
+
+// GetFileContent 获取文件内容，调用方读取完毕后需要关闭返回的流
+//
+// 文件可能很大，下载不限制总时长，两次收到数据的间隔超过StreamTimeouts.Idle时读取返回超时错误。
+func (c *Client) GetFileContent(ctx context.Context, fileID string) (io.ReadCloser, error) {
+	if fileID == "" {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "文件ID不能为空", 0, nil)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/files/"+url.PathEscape(fileID)+"/content", nil)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "创建HTTP请求失败", 0, err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := utils.DoDownloadRequest(c.streamClient, req, c.streamTimeouts)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, parseErrorBody(body, resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// doJSON 发送JSON请求并把成功的响应解析到out中
+func (c *Client) doJSON(ctx context.Context, method, path string, payload interface{}, out interface{}) error {
+	var reqBody io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return api.NewError(api.ErrorTypeInvalidRequest, "无法序列化请求", 0, err)
+		}
+		reqBody = bytes.NewBuffer(data)
+	}
+
+	// 创建HTTP请求
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return api.NewError(api.ErrorTypeConnection, "创建HTTP请求失败", 0, err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	return c.sendJSON(req, out)
+}
+
+// sendJSON 发送已构造的请求并把成功的响应解析到out中
+func (c *Client) sendJSON(req *http.Request, out interface{}) error {
+	// 发送请求
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return api.NewError(api.ErrorTypeConnection, "HTTP请求失败", 0, err)
+	}
+	defer resp.Body.Close()
+
+	// 读取响应
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return api.NewError(api.ErrorTypeServer, "读取响应失败", resp.StatusCode, err)
+	}
+
+	// 检查HTTP状态码
+	if resp.StatusCode != http.StatusOK {
+		return parseErrorBody(body, resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return api.NewError(api.ErrorTypeServer, "解析响应失败", resp.StatusCode, err)
+	}
+	return nil
+}
+
+// 解析错误响应体
+func parseErrorBody(body []byte, statusCode int) *api.Error {
+	var openaiErr OpenAIError
+	if err := json.Unmarshal(body, &openaiErr); err != nil {
+		return api.NewError(api.ErrorTypeServer, fmt.Sprintf("API错误(状态码: %d)", statusCode), statusCode, nil)
+	}
+	return mapOpenAIError(&openaiErr, statusCode)
+}
@@ -0,0 +1,42 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// OpenAIModel 定义/models接口返回的模型
+type OpenAIModel struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// OpenAIModelList 定义/models接口的响应
+type OpenAIModelList struct {
+	Object string        `json:"object"`
+	Data   []OpenAIModel `json:"data"`
+}
+
+// ListModels 实现api.ModelLister接口，返回/models接口列出的模型
+//
+// OpenAI不返回模型的上下文长度和能力，这些信息需要由models注册表补充。
+func (c *Client) ListModels(ctx context.Context) ([]api.Model, error) {
+	var list OpenAIModelList
+	if err := c.doJSON(ctx, http.MethodGet, "/models", nil, &list); err != nil {
+		return nil, err
+	}
+
+	result := make([]api.Model, len(list.Data))
+	for i, model := range list.Data {
+		result[i] = api.Model{
+			ID:      model.ID,
+			Created: model.Created,
+			OwnedBy: model.OwnedBy,
+		}
+	}
+	return result, nil
+}
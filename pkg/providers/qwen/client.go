@@ -0,0 +1,513 @@
+package qwen
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/utils"
+)
+
+// Client 实现了阿里云通义千问（DashScope原生接口）的API客户端
+type Client struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	// streamClient 用于流式请求，不设置总超时，由streamTimeouts分阶段控制
+	streamClient   *http.Client
+	streamTimeouts api.StreamTimeouts
+	// strictParams 为true时不支持的参数返回错误，否则忽略并在响应中附带警告
+	strictParams bool
+}
+
+// 默认配置
+const (
+	defaultBaseURL        = "https://dashscope.aliyuncs.com/api/v1"
+	defaultTimeout        = 60 * time.Second
+	defaultMaxRetries     = 3
+	defaultEmbeddingModel = "text-embedding-v3"
+
+	generationPath = "/services/aigc/text-generation/generation"
+	embeddingPath  = "/services/embeddings/text-embedding/text-embedding"
+)
+
+// NewClient 创建一个新的通义千问客户端
+func NewClient(options ...api.ClientOption) (api.LLMClient, error) {
+	clientOptions := &api.ClientOptions{
+		BaseURL:    defaultBaseURL,
+		Timeout:    int(defaultTimeout.Seconds()),
+		MaxRetries: defaultMaxRetries,
+	}
+
+	// 应用选项
+	for _, option := range options {
+		option(clientOptions)
+	}
+
+	// 验证必要的配置
+	if clientOptions.APIKey == "" {
+		return nil, api.NewError(api.ErrorTypeAuthentication, "API密钥不能为空", 0, nil)
+	}
+
+	// 创建HTTP客户端
+	httpClient := &http.Client{
+		Timeout: time.Duration(clientOptions.Timeout) * time.Second,
+	}
+	if clientOptions.HTTPClient != nil {
+		if client, ok := clientOptions.HTTPClient.(*http.Client); ok {
+			httpClient = client
+		}
+	}
+
+	return &Client{
+		apiKey:         clientOptions.APIKey,
+		baseURL:        clientOptions.BaseURL,
+		httpClient:     httpClient,
+		streamClient:   utils.NewStreamClient(httpClient),
+		streamTimeouts: clientOptions.StreamTimeouts.WithDefaults(time.Duration(clientOptions.Timeout) * time.Second),
+		strictParams:   clientOptions.StrictParams,
+		maxRetries:     clientOptions.MaxRetries,
+	}, nil
+}
+
+// Complete 发送请求并获取完整的响应
+func (c *Client) Complete(ctx context.Context, request *api.Request) (*api.Response, error) {
+	// 验证请求
+	if err := validateRequest(request); err != nil {
+		return nil, err
+	}
+
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.post(ctx, generationPath, adaptRequest(request))
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析响应
+	var qwenResp QwenResponse
+	if err := json.Unmarshal(body, &qwenResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", http.StatusOK, err)
+	}
+
+	response := adaptResponse(&qwenResp, request.Model)
+	response.Warnings = warnings
+	return response, nil
+}
+
+// CompleteStream 发送请求并获取流式响应
+//
+// 默认开启incremental_output，每个响应块只包含新增内容；若通过ExtraParams
+// 显式关闭，流会在内部把累积输出还原为增量，对调用方表现一致。
+func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.ResponseStream, error) {
+	// 验证请求
+	if err := validateRequest(request); err != nil {
+		return nil, err
+	}
+
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// 设置流式标志
+	reqCopy := *request
+	reqCopy.Stream = true
+
+	payload := adaptRequest(&reqCopy)
+	parameters := payload["parameters"].(map[string]interface{})
+	incremental, _ := parameters["incremental_output"].(bool)
+
+	resp, err := c.doRequest(ctx, generationPath, payload, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查HTTP状态码
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, parseErrorBody(body, resp.StatusCode)
+	}
+
+	stream := &qwenResponseStream{
+		rawReader:   resp.Body,
+		model:       request.Model,
+		incremental: incremental,
+		previous:    map[int]string{},
+	}
+	stream.StreamDecoder = utils.NewStreamDecoder(resp.Body, stream.decode, func(options *utils.StreamDecoderOptions) {
+		options.ParseError = parseStreamError
+	})
+	return utils.WithWarnings(stream, warnings), nil
+}
+
+// Embedding 获取文本的嵌入向量
+func (c *Client) Embedding(ctx context.Context, input string) ([]float32, error) {
+	body, err := c.post(ctx, embeddingPath, map[string]interface{}{
+		"model": defaultEmbeddingModel,
+		"input": map[string]interface{}{
+			"texts": []string{input},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析嵌入响应
+	var embedResp struct {
+		Output struct {
+			Embeddings []struct {
+				TextIndex int       `json:"text_index"`
+				Embedding []float32 `json:"embedding"`
+			} `json:"embeddings"`
+		} `json:"output"`
+	}
+	if err := json.Unmarshal(body, &embedResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析嵌入响应失败", http.StatusOK, err)
+	}
+
+	if len(embedResp.Output.Embeddings) == 0 || len(embedResp.Output.Embeddings[0].Embedding) == 0 {
+		return nil, api.NewError(api.ErrorTypeServer, "未收到有效的嵌入结果", http.StatusOK, nil)
+	}
+
+	return embedResp.Output.Embeddings[0].Embedding, nil
+}
+
+// post 发送JSON请求并返回成功响应的响应体
+func (c *Client) post(ctx context.Context, path string, payload interface{}) ([]byte, error) {
+	resp, err := c.doRequest(ctx, path, payload, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// 读取响应
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "读取响应失败", resp.StatusCode, err)
+	}
+
+	// 检查HTTP状态码
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseErrorBody(body, resp.StatusCode)
+	}
+
+	return body, nil
+}
+
+// doRequest 构造并发送HTTP请求
+func (c *Client) doRequest(ctx context.Context, path string, payload interface{}, stream bool) (*http.Response, error) {
+	// 准备请求体
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "无法序列化请求", 0, err)
+	}
+
+	// 创建HTTP请求
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "创建HTTP请求失败", 0, err)
+	}
+
+	// 设置请求头，DashScope通过X-DashScope-SSE开启SSE输出
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("X-DashScope-SSE", "enable")
+	}
+
+	// 流式请求分阶段控制超时
+	if stream {
+		return utils.DoStreamRequest(c.streamClient, req, c.streamTimeouts)
+	}
+
+	// 发送请求
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "HTTP请求失败", 0, err)
+	}
+	return resp, nil
+}
+
+// 验证请求参数
+func validateRequest(request *api.Request) error {
+	if request == nil {
+		return api.NewError(api.ErrorTypeInvalidRequest, "请求不能为空", 0, nil)
+	}
+	if request.Model == "" {
+		return api.NewError(api.ErrorTypeInvalidRequest, "模型不能为空", 0, nil)
+	}
+	if len(request.Messages) == 0 {
+		return api.NewError(api.ErrorTypeInvalidRequest, "消息不能为空", 0, nil)
+	}
+	if request.ResponseFormat != nil && request.ResponseFormat.Type == api.ResponseFormatJSONSchema && request.ResponseFormat.JSONSchema == nil {
+		return api.NewError(api.ErrorTypeInvalidRequest, "json_schema格式需要提供JSONSchema", 0, nil)
+	}
+	return nil
+}
+
+// supportedParams 通义千问支持的可选参数
+var supportedParams = api.NewParamSet(
+	api.ParamTemperature, api.ParamTopP, api.ParamTopK, api.ParamMaxTokens, api.ParamPresencePenalty, api.ParamStop, api.ParamSeed,
+	api.ParamTools, api.ParamToolChoice, api.ParamParallelToolCalls, api.ParamResponseFormat,
+)
+
+// 检查请求中通义千问不支持的参数，非严格模式下返回忽略这些参数的警告
+func (c *Client) checkParams(request *api.Request) ([]string, error) {
+	return api.CheckParams("通义千问", request, supportedParams, c.strictParams)
+}
+
+// QwenResponse 定义DashScope文本生成接口的响应结构，流式响应的每个事件也使用该结构
+type QwenResponse struct {
+	RequestID string `json:"request_id"`
+	Output    struct {
+		Choices []struct {
+			// FinishReason 生成过程中为字符串"null"，结束时为stop、length或tool_calls
+			FinishReason string            `json:"finish_reason"`
+			Message      utils.ChatMessage `json:"message"`
+		} `json:"choices"`
+	} `json:"output"`
+	// Usage 流式响应的每个事件都包含截至当前的累计用量
+	Usage QwenUsage `json:"usage"`
+}
+
+// QwenUsage 定义DashScope的令牌用量
+type QwenUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
+// 将DashScope的令牌用量转换为SDK格式
+func adaptUsage(usage QwenUsage) api.Usage {
+	return api.Usage{
+		PromptTokens:     usage.InputTokens,
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      usage.TotalTokens,
+	}
+}
+
+// QwenError 定义DashScope API的错误响应
+type QwenError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id"`
+}
+
+// 将SDK的请求格式转换为DashScope的格式
+func adaptRequest(request *api.Request) map[string]interface{} {
+	// DashScope原生接口将消息放在input中，生成参数放在parameters中
+	parameters := map[string]interface{}{
+		"result_format": "message",
+	}
+
+	// 添加可选参数
+	if request.Temperature != nil {
+		parameters["temperature"] = *request.Temperature
+	}
+	if request.TopP != nil {
+		parameters["top_p"] = *request.TopP
+	}
+	if request.TopK != nil {
+		parameters["top_k"] = *request.TopK
+	}
+	if request.MaxTokens != nil {
+		parameters["max_tokens"] = *request.MaxTokens
+	}
+	if request.PresencePenalty != nil {
+		parameters["presence_penalty"] = *request.PresencePenalty
+	}
+	if len(request.Stop) > 0 {
+		parameters["stop"] = request.Stop
+	}
+	if request.Seed != nil {
+		parameters["seed"] = *request.Seed
+	}
+	if request.Stream {
+		parameters["incremental_output"] = true
+	}
+
+	// 函数调用
+	if len(request.Tools) > 0 {
+		parameters["tools"] = request.Tools
+	}
+	if request.ToolChoice != nil {
+		parameters["tool_choice"] = request.ToolChoice
+	}
+	if request.ParallelToolCalls != nil {
+		parameters["parallel_tool_calls"] = *request.ParallelToolCalls
+	}
+
+	// JSON模式，json_schema格式同时转发JSONSchema（与OpenAI格式相同）
+	if request.ResponseFormat != nil {
+		parameters["response_format"] = request.ResponseFormat
+	}
+
+	// 添加其他自定义参数（如enable_search），均属于parameters
+	for k, v := range request.ExtraParams {
+		parameters[k] = v
+	}
+
+	return map[string]interface{}{
+		"model": request.Model,
+		"input": map[string]interface{}{
+			"messages": utils.NewChatMessages(request.Messages),
+		},
+		"parameters": parameters,
+	}
+}
+
+// normalizeFinishReason DashScope在未结束时返回字符串"null"
+func normalizeFinishReason(reason string) string {
+	if reason == "null" {
+		return ""
+	}
+	return reason
+}
+
+// 将DashScope的响应格式转换为SDK的通用格式
+func adaptResponse(qwenResp *QwenResponse, modelName string) *api.Response {
+	choices := make([]api.Choice, len(qwenResp.Output.Choices))
+	for i, choice := range qwenResp.Output.Choices {
+		choices[i] = api.Choice{
+			Index: i,
+			Message: api.Message{
+				Role:      api.RoleAssistant,
+				Content:   choice.Message.Content,
+				ToolCalls: choice.Message.ToolCalls,
+			},
+			FinishReason:    api.NormalizeFinishReason(normalizeFinishReason(choice.FinishReason)),
+			RawFinishReason: normalizeFinishReason(choice.FinishReason),
+		}
+	}
+
+	return &api.Response{
+		ID:      qwenResp.RequestID,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   modelName,
+		Choices: choices,
+		Usage:   adaptUsage(qwenResp.Usage),
+	}
+}
+
+// parseErrorBody 解析错误响应体
+func parseErrorBody(body []byte, statusCode int) *api.Error {
+	var qwenErr QwenError
+	if err := json.Unmarshal(body, &qwenErr); err != nil {
+		return api.NewError(api.ErrorTypeServer, fmt.Sprintf("API错误(状态码: %d)", statusCode), statusCode, nil)
+	}
+	return mapQwenError(&qwenErr, statusCode)
+}
+
+// 将DashScope的错误映射到SDK的错误类型
+func mapQwenError(qwenErr *QwenError, statusCode int) *api.Error {
+	errType := api.ErrorTypeUnknown
+	switch {
+	case qwenErr.Code == "InvalidApiKey" || qwenErr.Code == "AccessDenied" || strings.HasPrefix(qwenErr.Code, "AccessDenied."):
+		errType = api.ErrorTypeAuthentication
+	case qwenErr.Code == "Throttling" || strings.HasPrefix(qwenErr.Code, "Throttling."):
+		errType = api.ErrorTypeRateLimit
+	case qwenErr.Code == "InvalidParameter" || qwenErr.Code == "DataInspectionFailed" || qwenErr.Code == "ModelNotFound":
+		errType = api.ErrorTypeInvalidRequest
+	case qwenErr.Code == "RequestTimeOut":
+		errType = api.ErrorTypeTimeout
+	case qwenErr.Code == "InternalError" || strings.HasPrefix(qwenErr.Code, "InternalError."):
+		errType = api.ErrorTypeServer
+	case statusCode == 400 || statusCode == 404:
+		errType = api.ErrorTypeInvalidRequest
+	case statusCode == 401 || statusCode == 403:
+		errType = api.ErrorTypeAuthentication
+	case statusCode == 429:
+		errType = api.ErrorTypeRateLimit
+	case statusCode >= 500:
+		errType = api.ErrorTypeServer
+	}
+
+	return &api.Error{
+		Type:       errType,
+		Message:    qwenErr.Message,
+		StatusCode: statusCode,
+		Code:       qwenErr.Code,
+	}
+}
+
+// qwenResponseStream 实现流式响应接口
+type qwenResponseStream struct {
+	*utils.StreamDecoder
+	rawReader   io.ReadCloser
+	model       string
+	incremental bool
+	// previous 非增量模式下每个候选已输出的累积内容
+	previous map[int]string
+}
+
+// parseStreamError 解析流中途返回的错误，数据不是错误时返回nil
+func parseStreamError(data []byte) *api.Error {
+	var qwenErr QwenError
+	if err := json.Unmarshal(data, &qwenErr); err != nil || (qwenErr.Code == "" && qwenErr.Message == "") {
+		return nil
+	}
+	return mapQwenError(&qwenErr, 0)
+}
+
+// decode 将一个SSE事件解码为响应块
+func (s *qwenResponseStream) decode(event *utils.SSEEvent) (*api.ResponseChunk, error) {
+	// 解析JSON数据
+	var streamResp QwenResponse
+	if err := json.Unmarshal([]byte(utils.ParseSSEData(event.Data)), &streamResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析流式响应失败", 0, err)
+	}
+
+	// 转换为SDK的通用格式
+	choices := make([]api.ChunkChoice, len(streamResp.Output.Choices))
+	for i, choice := range streamResp.Output.Choices {
+		content := choice.Message.Content
+		if !s.incremental {
+			content = strings.TrimPrefix(content, s.previous[i])
+			s.previous[i] = choice.Message.Content
+		}
+		choices[i] = api.ChunkChoice{
+			Index: i,
+			Delta: api.Message{
+				Role:      api.RoleAssistant,
+				Content:   content,
+				ToolCalls: choice.Message.ToolCalls,
+			},
+			FinishReason:    api.NormalizeFinishReason(normalizeFinishReason(choice.FinishReason)),
+			RawFinishReason: normalizeFinishReason(choice.FinishReason),
+		}
+	}
+
+	chunk := &api.ResponseChunk{
+		ID:      streamResp.RequestID,
+		Object:  "chat.completion.chunk",
+		Created: time.Now().Unix(),
+		Model:   s.model,
+		Choices: choices,
+	}
+	if streamResp.Usage.TotalTokens > 0 {
+		usage := adaptUsage(streamResp.Usage)
+		chunk.Usage = &usage
+	}
+	return chunk, nil
+}
+
+// Close 关闭流
+func (s *qwenResponseStream) Close() error {
+	return s.rawReader.Close()
+}
@@ -0,0 +1,63 @@
+package qwen
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+func TestAdaptRequestResponseFormat(t *testing.T) {
+	schema := map[string]interface{}{
+		"name":   "answer",
+		"schema": map[string]interface{}{"type": "object"},
+	}
+	tests := []struct {
+		name   string
+		format *api.ResponseFormat
+		want   string
+	}{
+		{
+			name:   "json object",
+			format: &api.ResponseFormat{Type: api.ResponseFormatJSONObject},
+			want:   `{"type":"json_object"}`,
+		},
+		{
+			name:   "json schema",
+			format: &api.ResponseFormat{Type: api.ResponseFormatJSONSchema, JSONSchema: schema},
+			want:   `{"type":"json_schema","json_schema":{"name":"answer","schema":{"type":"object"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := &api.Request{
+				Model:          "qwen-plus",
+				Messages:       []api.Message{{Role: api.RoleUser, Content: "hi"}},
+				ResponseFormat: tt.format,
+			}
+			if err := validateRequest(request); err != nil {
+				t.Fatal(err)
+			}
+			parameters := adaptRequest(request)["parameters"].(map[string]interface{})
+			got, err := json.Marshal(parameters["response_format"])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("response_format = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRequestRequiresJSONSchema(t *testing.T) {
+	request := &api.Request{
+		Model:          "qwen-plus",
+		Messages:       []api.Message{{Role: api.RoleUser, Content: "hi"}},
+		ResponseFormat: &api.ResponseFormat{Type: api.ResponseFormatJSONSchema},
+	}
+	if err := validateRequest(request); err == nil {
+		t.Fatal("expected an error for json_schema without JSONSchema")
+	}
+}
@@ -0,0 +1,62 @@
+package qwen
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/utils"
+)
+
+// QwenModel 定义兼容模式/models接口返回的模型
+type QwenModel struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// QwenModelList 定义兼容模式/models接口的响应
+type QwenModelList struct {
+	Object string      `json:"object"`
+	Data   []QwenModel `json:"data"`
+}
+
+// ListModels 实现api.ModelLister接口，返回DashScope兼容模式/models接口列出的模型
+//
+// 原生接口没有模型列表，请求发往与baseURL同一域名下的/compatible-mode/v1/models。
+func (c *Client) ListModels(ctx context.Context) ([]api.Model, error) {
+	config := utils.DefaultHTTPConfig()
+	config.MaxRetries = c.maxRetries
+	body, statusCode, err := utils.DoHTTPRequest(ctx, c.httpClient, http.MethodGet, compatibleURL(c.baseURL)+"/models", nil, utils.MakeAuthHeader(c.apiKey, "bearer"), config)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查HTTP状态码
+	if statusCode != http.StatusOK {
+		return nil, parseErrorBody(body, statusCode)
+	}
+
+	var list QwenModelList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", statusCode, err)
+	}
+
+	result := make([]api.Model, len(list.Data))
+	for i, model := range list.Data {
+		result[i] = api.Model{ID: model.ID, Created: model.Created, OwnedBy: model.OwnedBy}
+	}
+	return result, nil
+}
+
+// compatibleURL 将原生接口的baseURL转换为兼容模式的地址，baseURL不以/api/v1结尾时原样返回
+func compatibleURL(baseURL string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if strings.HasSuffix(baseURL, "/api/v1") {
+		return strings.TrimSuffix(baseURL, "/api/v1") + "/compatible-mode/v1"
+	}
+	return baseURL
+}
@@ -0,0 +1,92 @@
+package zhipu
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// 令牌配置
+const (
+	// tokenTTL 生成的JWT有效期
+	tokenTTL = 30 * time.Minute
+	// tokenRefreshBefore 在过期前提前刷新，避免请求途中过期
+	tokenRefreshBefore = 1 * time.Minute
+)
+
+// tokenSigner 根据"id.secret"格式的API密钥在本地签发JWT并缓存
+type tokenSigner struct {
+	id     string
+	secret []byte
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// newTokenSigner 解析API密钥并创建签名器
+func newTokenSigner(apiKey string) (*tokenSigner, error) {
+	parts := strings.SplitN(apiKey, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, api.NewError(api.ErrorTypeAuthentication, "智谱API密钥格式应为id.secret", 0, nil)
+	}
+	return &tokenSigner{
+		id:     parts[0],
+		secret: []byte(parts[1]),
+	}, nil
+}
+
+// Token 返回有效的JWT，必要时重新签发
+func (s *tokenSigner) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.token != "" && now.Add(tokenRefreshBefore).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	expiresAt := now.Add(tokenTTL)
+	token, err := s.sign(now, expiresAt)
+	if err != nil {
+		return "", err
+	}
+
+	s.token = token
+	s.expiresAt = expiresAt
+	return token, nil
+}
+
+// sign 按智谱的要求签发HS256 JWT，时间戳使用毫秒
+func (s *tokenSigner) sign(now, expiresAt time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg":       "HS256",
+		"sign_type": "SIGN",
+	})
+	if err != nil {
+		return "", api.NewError(api.ErrorTypeAuthentication, "生成令牌失败", 0, err)
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"api_key":   s.id,
+		"exp":       expiresAt.UnixMilli(),
+		"timestamp": now.UnixMilli(),
+	})
+	if err != nil {
+		return "", api.NewError(api.ErrorTypeAuthentication, "生成令牌失败", 0, err)
+	}
+
+	encoding := base64.RawURLEncoding
+	signingInput := encoding.EncodeToString(header) + "." + encoding.EncodeToString(payload)
+
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(signingInput))
+
+	return signingInput + "." + encoding.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,497 @@
+package zhipu
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/utils"
+)
+
+// Client 实现了智谱GLM的API客户端
+//
+// API密钥为智谱开放平台提供的"id.secret"格式，客户端会在本地签发JWT用于鉴权。
+type Client struct {
+	signer     *tokenSigner
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	// streamClient 用于流式请求，不设置总超时，由streamTimeouts分阶段控制
+	streamClient   *http.Client
+	streamTimeouts api.StreamTimeouts
+	// strictParams 为true时不支持的参数返回错误，否则忽略并在响应中附带警告
+	strictParams bool
+}
+
+// 默认配置
+const (
+	defaultBaseURL        = "https://open.bigmodel.cn/api/paas/v4"
+	defaultTimeout        = 60 * time.Second
+	defaultMaxRetries     = 3
+	defaultEmbeddingModel = "embedding-3"
+)
+
+// NewClient 创建一个新的智谱GLM客户端
+func NewClient(options ...api.ClientOption) (api.LLMClient, error) {
+	clientOptions := &api.ClientOptions{
+		BaseURL:    defaultBaseURL,
+		Timeout:    int(defaultTimeout.Seconds()),
+		MaxRetries: defaultMaxRetries,
+	}
+
+	// 应用选项
+	for _, option := range options {
+		option(clientOptions)
+	}
+
+	// 验证必要的配置
+	if clientOptions.APIKey == "" {
+		return nil, api.NewError(api.ErrorTypeAuthentication, "API密钥不能为空", 0, nil)
+	}
+	signer, err := newTokenSigner(clientOptions.APIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// 创建HTTP客户端
+	httpClient := &http.Client{
+		Timeout: time.Duration(clientOptions.Timeout) * time.Second,
+	}
+	if clientOptions.HTTPClient != nil {
+		if client, ok := clientOptions.HTTPClient.(*http.Client); ok {
+			httpClient = client
+		}
+	}
+
+	return &Client{
+		signer:         signer,
+		baseURL:        clientOptions.BaseURL,
+		httpClient:     httpClient,
+		streamClient:   utils.NewStreamClient(httpClient),
+		streamTimeouts: clientOptions.StreamTimeouts.WithDefaults(time.Duration(clientOptions.Timeout) * time.Second),
+		strictParams:   clientOptions.StrictParams,
+		maxRetries:     clientOptions.MaxRetries,
+	}, nil
+}
+
+// Complete 发送请求并获取完整的响应
+func (c *Client) Complete(ctx context.Context, request *api.Request) (*api.Response, error) {
+	// 验证请求
+	if err := validateRequest(request); err != nil {
+		return nil, err
+	}
+
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.post(ctx, "/chat/completions", adaptRequest(request))
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析响应
+	var zhipuResp ZhipuResponse
+	if err := json.Unmarshal(body, &zhipuResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", http.StatusOK, err)
+	}
+
+	response := adaptResponse(&zhipuResp)
+	response.Warnings = warnings
+	return response, nil
+}
+
+// CompleteStream 发送请求并获取流式响应
+func (c *Client) CompleteStream(ctx context.Context, request *api.Request) (api.ResponseStream, error) {
+	// 验证请求
+	if err := validateRequest(request); err != nil {
+		return nil, err
+	}
+
+	// 检查不支持的参数
+	warnings, err := c.checkParams(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// 设置流式标志
+	reqCopy := *request
+	reqCopy.Stream = true
+
+	resp, err := c.doRequest(ctx, "/chat/completions", adaptRequest(&reqCopy), true)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查HTTP状态码
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, parseErrorBody(body, resp.StatusCode)
+	}
+
+	stream := &zhipuResponseStream{
+		rawReader: resp.Body,
+	}
+	stream.StreamDecoder = utils.NewStreamDecoder(resp.Body, stream.decode, func(options *utils.StreamDecoderOptions) {
+		options.DoneData = "[DONE]"
+		options.ParseError = parseStreamError
+		options.InlineErrors = true
+	})
+	return utils.WithWarnings(stream, warnings), nil
+}
+
+// Embedding 获取文本的嵌入向量
+func (c *Client) Embedding(ctx context.Context, input string) ([]float32, error) {
+	body, err := c.post(ctx, "/embeddings", map[string]interface{}{
+		"model": defaultEmbeddingModel,
+		"input": input,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 解析嵌入响应
+	var embedResp struct {
+		Data []struct {
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &embedResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析嵌入响应失败", http.StatusOK, err)
+	}
+
+	if len(embedResp.Data) == 0 || len(embedResp.Data[0].Embedding) == 0 {
+		return nil, api.NewError(api.ErrorTypeServer, "未收到有效的嵌入结果", http.StatusOK, nil)
+	}
+
+	return embedResp.Data[0].Embedding, nil
+}
+
+// post 发送JSON请求并返回成功响应的响应体
+func (c *Client) post(ctx context.Context, path string, payload interface{}) ([]byte, error) {
+	resp, err := c.doRequest(ctx, path, payload, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// 读取响应
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "读取响应失败", resp.StatusCode, err)
+	}
+
+	// 检查HTTP状态码
+	if resp.StatusCode != http.StatusOK {
+		return nil, parseErrorBody(body, resp.StatusCode)
+	}
+
+	return body, nil
+}
+
+// doRequest 构造并发送HTTP请求
+func (c *Client) doRequest(ctx context.Context, path string, payload interface{}, stream bool) (*http.Response, error) {
+	token, err := c.signer.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	// 准备请求体
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeInvalidRequest, "无法序列化请求", 0, err)
+	}
+
+	// 创建HTTP请求
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "创建HTTP请求失败", 0, err)
+	}
+
+	// 设置请求头
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	// 流式请求分阶段控制超时
+	if stream {
+		return utils.DoStreamRequest(c.streamClient, req, c.streamTimeouts)
+	}
+
+	// 发送请求
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, api.NewError(api.ErrorTypeConnection, "HTTP请求失败", 0, err)
+	}
+	return resp, nil
+}
+
+// 验证请求参数
+func validateRequest(request *api.Request) error {
+	if request == nil {
+		return api.NewError(api.ErrorTypeInvalidRequest, "请求不能为空", 0, nil)
+	}
+	if request.Model == "" {
+		return api.NewError(api.ErrorTypeInvalidRequest, "模型不能为空", 0, nil)
+	}
+	if len(request.Messages) == 0 {
+		return api.NewError(api.ErrorTypeInvalidRequest, "消息不能为空", 0, nil)
+	}
+	return nil
+}
+
+// supportedParams 智谱支持的可选参数
+var supportedParams = api.NewParamSet(
+	api.ParamTemperature, api.ParamTopP, api.ParamMaxTokens, api.ParamStop, api.ParamUser,
+	api.ParamTools, api.ParamResponseFormat,
+)
+
+// 检查请求中智谱不支持的参数，非严格模式下返回忽略这些参数的警告
+func (c *Client) checkParams(request *api.Request) ([]string, error) {
+	return api.CheckParams("智谱", request, supportedParams, c.strictParams)
+}
+
+// ZhipuResponse 定义智谱API的响应结构
+type ZhipuResponse struct {
+	ID      string `json:"id"`
+	Created int64  `json:"created"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index   int               `json:"index"`
+		Message utils.ChatMessage `json:"message"`
+		// FinishReason 可能为stop、length、tool_calls、sensitive或network_error
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage ZhipuUsage `json:"usage"`
+}
+
+// ZhipuUsage 定义智谱的令牌用量
+type ZhipuUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// 将智谱的令牌用量转换为SDK格式
+func adaptUsage(usage ZhipuUsage) api.Usage {
+	return api.Usage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+	}
+}
+
+// ZhipuStreamResponse 定义智谱API的流式响应结构
+type ZhipuStreamResponse struct {
+	ID      string `json:"id"`
+	Created int64  `json:"created"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index        int               `json:"index"`
+		Delta        utils.ChatMessage `json:"delta"`
+		FinishReason string            `json:"finish_reason,omitempty"`
+	} `json:"choices"`
+	// Usage 仅在开启stream_options.include_usage时出现在最后一个数据块中
+	Usage *ZhipuUsage `json:"usage,omitempty"`
+}
+
+// ZhipuError 定义智谱API的错误响应
+type ZhipuError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// 将SDK的请求格式转换为智谱的格式
+func adaptRequest(request *api.Request) map[string]interface{} {
+	// 智谱的API格式与OpenAI基本一致
+	req := map[string]interface{}{
+		"model":    request.Model,
+		"messages": utils.NewChatMessages(request.Messages),
+	}
+
+	// 添加可选参数
+	if request.Temperature != nil {
+		req["temperature"] = *request.Temperature
+	}
+	if request.TopP != nil {
+		req["top_p"] = *request.TopP
+	}
+	if request.MaxTokens != nil {
+		req["max_tokens"] = *request.MaxTokens
+	}
+	if len(request.Stop) > 0 {
+		req["stop"] = request.Stop
+	}
+	if request.User != "" {
+		req["user_id"] = request.User
+	}
+	if request.Stream {
+		req["stream"] = request.Stream
+		// 让最后一个数据块携带令牌用量
+		req["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
+
+	// 函数调用，智谱目前只支持auto
+	if len(request.Tools) > 0 {
+		req["tools"] = request.Tools
+		req["tool_choice"] = "auto"
+	}
+
+	// JSON模式
+	if request.ResponseFormat != nil {
+		req["response_format"] = map[string]interface{}{"type": request.ResponseFormat.Type}
+	}
+
+	// 添加其他自定义参数（如do_sample、request_id）
+	for k, v := range request.ExtraParams {
+		req[k] = v
+	}
+
+	return req
+}
+
+// 将智谱的响应格式转换为SDK的通用格式
+func adaptResponse(zhipuResp *ZhipuResponse) *api.Response {
+	choices := make([]api.Choice, len(zhipuResp.Choices))
+	for i, choice := range zhipuResp.Choices {
+		choices[i] = api.Choice{
+			Index:           choice.Index,
+			Message:         choice.Message.Message(),
+			FinishReason:    mapFinishReason(choice.FinishReason),
+			RawFinishReason: choice.FinishReason,
+		}
+	}
+
+	return &api.Response{
+		ID:      zhipuResp.ID,
+		Object:  "chat.completion",
+		Created: zhipuResp.Created,
+		Model:   zhipuResp.Model,
+		Choices: choices,
+		Usage:   adaptUsage(zhipuResp.Usage),
+	}
+}
+
+// parseErrorBody 解析错误响应体
+func parseErrorBody(body []byte, statusCode int) *api.Error {
+	var zhipuErr ZhipuError
+	if err := json.Unmarshal(body, &zhipuErr); err != nil {
+		return api.NewError(api.ErrorTypeServer, fmt.Sprintf("API错误(状态码: %d)", statusCode), statusCode, nil)
+	}
+	return mapZhipuError(&zhipuErr, statusCode)
+}
+
+// 将智谱的错误映射到SDK的错误类型
+//
+// 智谱使用数字业务码：1000-1004为鉴权错误，1113为余额不足，
+// 将智谱的结束原因映射为标准值
+func mapFinishReason(reason string) api.FinishReason {
+	switch reason {
+	case "sensitive":
+		return api.FinishReasonContentFilter
+	case "network_error":
+		return api.FinishReasonError
+	default:
+		return api.NormalizeFinishReason(reason)
+	}
+}
+
+// 1210-1261为参数错误，1301为内容安全拦截，1302-1305为并发或频率限制。
+func mapZhipuError(zhipuErr *ZhipuError, statusCode int) *api.Error {
+	errType := api.ErrorTypeUnknown
+	switch zhipuErr.Error.Code {
+	case "1000", "1001", "1002", "1003", "1004", "1100", "1110", "1111", "1112", "1120", "1121":
+		errType = api.ErrorTypeAuthentication
+	case "1113":
+		errType = api.ErrorTypeRateLimit
+	case "1210", "1211", "1212", "1213", "1214", "1215", "1261", "1301":
+		errType = api.ErrorTypeInvalidRequest
+	case "1302", "1303", "1304", "1305":
+		errType = api.ErrorTypeRateLimit
+	case "500":
+		errType = api.ErrorTypeServer
+	default:
+		switch {
+		case statusCode == 400 || statusCode == 404 || statusCode == 434 || statusCode == 435:
+			errType = api.ErrorTypeInvalidRequest
+		case statusCode == 401 || statusCode == 403:
+			errType = api.ErrorTypeAuthentication
+		case statusCode == 429:
+			errType = api.ErrorTypeRateLimit
+		case statusCode >= 500:
+			errType = api.ErrorTypeServer
+		}
+	}
+
+	return &api.Error{
+		Type:       errType,
+		Message:    zhipuErr.Error.Message,
+		StatusCode: statusCode,
+		Code:       zhipuErr.Error.Code,
+	}
+}
+
+// zhipuResponseStream 实现流式响应接口
+type zhipuResponseStream struct {
+	*utils.StreamDecoder
+	rawReader io.ReadCloser
+}
+
+// parseStreamError 解析流中途返回的错误，数据不是错误时返回nil
+func parseStreamError(data []byte) *api.Error {
+	var zhipuErr ZhipuError
+	if err := json.Unmarshal(data, &zhipuErr); err != nil || zhipuErr.Error.Code == "" {
+		return nil
+	}
+	return mapZhipuError(&zhipuErr, 0)
+}
+
+// decode 将一个SSE事件解码为响应块
+func (s *zhipuResponseStream) decode(event *utils.SSEEvent) (*api.ResponseChunk, error) {
+	// 解析JSON数据
+	var streamResp ZhipuStreamResponse
+	if err := json.Unmarshal([]byte(utils.ParseSSEData(event.Data)), &streamResp); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析流式响应失败", 0, err)
+	}
+
+	// 转换为SDK的通用格式
+	choices := make([]api.ChunkChoice, len(streamResp.Choices))
+	for i, choice := range streamResp.Choices {
+		choices[i] = api.ChunkChoice{
+			Index:           choice.Index,
+			Delta:           choice.Delta.Message(),
+			FinishReason:    mapFinishReason(choice.FinishReason),
+			RawFinishReason: choice.FinishReason,
+		}
+	}
+
+	chunk := &api.ResponseChunk{
+		ID:      streamResp.ID,
+		Object:  "chat.completion.chunk",
+		Created: streamResp.Created,
+		Model:   streamResp.Model,
+		Choices: choices,
+	}
+	if streamResp.Usage != nil {
+		usage := adaptUsage(*streamResp.Usage)
+		chunk.Usage = &usage
+	}
+	return chunk, nil
+}
+
+// Close 关闭流
+func (s *zhipuResponseStream) Close() error {
+	return s.rawReader.Close()
+}
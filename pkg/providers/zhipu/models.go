@@ -0,0 +1,55 @@
+package zhipu
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+	"github.com/ojbkgo/llm-sdk/pkg/utils"
+)
+
+// ZhipuModel 定义/models接口返回的模型
+type ZhipuModel struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// ZhipuModelList 定义/models接口的响应
+type ZhipuModelList struct {
+	Object string       `json:"object"`
+	Data   []ZhipuModel `json:"data"`
+}
+
+// ListModels 实现api.ModelLister接口，返回/models接口列出的模型
+func (c *Client) ListModels(ctx context.Context) ([]api.Model, error) {
+	token, err := c.signer.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	config := utils.DefaultHTTPConfig()
+	config.MaxRetries = c.maxRetries
+	body, statusCode, err := utils.DoHTTPRequest(ctx, c.httpClient, http.MethodGet, c.baseURL+"/models", nil, utils.MakeAuthHeader(token, "bearer"), config)
+	if err != nil {
+		return nil, err
+	}
+
+	// 检查HTTP状态码
+	if statusCode != http.StatusOK {
+		return nil, parseErrorBody(body, statusCode)
+	}
+
+	var list ZhipuModelList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, api.NewError(api.ErrorTypeServer, "解析响应失败", statusCode, err)
+	}
+
+	result := make([]api.Model, len(list.Data))
+	for i, model := range list.Data {
+		result[i] = api.Model{ID: model.ID, Created: model.Created, OwnedBy: model.OwnedBy}
+	}
+	return result, nil
+}
@@ -0,0 +1,306 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// CompleteFunc 发送一次非流式请求，通常为提供商客户端的Complete方法
+type CompleteFunc func(ctx context.Context, request *api.Request) (*api.Response, error)
+
+// StreamFunc 发送一次流式请求，通常为提供商客户端的CompleteStream方法
+type StreamFunc func(ctx context.Context, request *api.Request) (api.ResponseStream, error)
+
+// singleCandidate 返回只请求一个候选的请求副本
+func singleCandidate(request *api.Request) *api.Request {
+	reqCopy := *request
+	reqCopy.N = 0
+	return &reqCopy
+}
+
+// CompleteCandidates 通过request.N次并行请求模拟多候选生成，用于不支持n参数的提供商
+//
+// 第i次请求的第一个候选作为结果中序号为i的候选，用量为各次请求之和。任一请求失败时取消其余请求并返回该错误。
+func CompleteCandidates(ctx context.Context, request *api.Request, complete CompleteFunc) (*api.Response, error) {
+	n := request.N
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	single := singleCandidate(request)
+	responses := make([]*api.Response, n)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], errs[i] = complete(ctx, single)
+			if errs[i] != nil {
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := firstError(errs); err != nil {
+		return nil, err
+	}
+
+	merged := *responses[0]
+	merged.Choices = make([]api.Choice, 0, n)
+	merged.Usage = api.Usage{}
+	for i, response := range responses {
+		if len(response.Choices) > 0 {
+			choice := response.Choices[0]
+			choice.Index = i
+			merged.Choices = append(merged.Choices, choice)
+		}
+		addUsage(&merged.Usage, &response.Usage)
+	}
+	return &merged, nil
+}
+
+// StreamCandidates 通过request.N个并行的流式请求模拟多候选生成，用于不支持n参数的提供商
+//
+// 返回的流合并所有请求的响应块，第i个请求的候选序号改写为i；各请求的用量在所有请求结束后
+// 合并为最后一个只包含用量的响应块。任一请求失败时关闭其余请求并返回该错误。
+// Recv返回io.EOF时资源已经释放；提前结束读取时需要调用Close。
+func StreamCandidates(ctx context.Context, request *api.Request, open StreamFunc) (api.ResponseStream, error) {
+	n := request.N
+	ctx, cancel := context.WithCancel(ctx)
+
+	single := singleCandidate(request)
+	streams := make([]api.ResponseStream, n)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			streams[i], errs[i] = open(ctx, single)
+			if errs[i] != nil {
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if err := firstError(errs); err != nil {
+		closeStreams(streams)
+		cancel()
+		return nil, err
+	}
+
+	return &candidateStream{
+		streams: streams,
+		cancel:  cancel,
+		results: make(chan api.StreamResult),
+		done:    make(chan struct{}),
+		usages:  make([]*api.Usage, n),
+	}, nil
+}
+
+// candidateStream 合并多个单候选的流
+type candidateStream struct {
+	streams []api.ResponseStream
+	cancel  context.CancelFunc
+	results chan api.StreamResult
+	done    chan struct{}
+	wg      sync.WaitGroup
+
+	// usages 各请求最后一次出现的用量，所有请求结束后才会读取
+	usages []*api.Usage
+	// first 第一个响应块，用于填充最后一个用量块的元数据
+	first *api.ResponseChunk
+
+	// startOnce 第一次调用Recv时才开始读取各请求，以便在此之前设置心跳回调
+	startOnce sync.Once
+	closeOnce sync.Once
+	err       error
+	finished  bool
+}
+
+// start 为每个请求启动读取的goroutine，全部结束后关闭results
+func (s *candidateStream) start() {
+	s.wg.Add(len(s.streams))
+	for i := range s.streams {
+		go s.pump(i)
+	}
+	go func() {
+		s.wg.Wait()
+		close(s.results)
+	}()
+}
+
+// pump 读取第i个流并改写候选序号
+func (s *candidateStream) pump(i int) {
+	defer s.wg.Done()
+	for {
+		chunk, err := s.streams[i].Recv()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			s.send(api.StreamResult{Err: err})
+			return
+		}
+
+		if chunk.Usage != nil {
+			s.usages[i] = chunk.Usage
+		}
+		rewritten := *chunk
+		rewritten.Usage = nil
+		// 各请求的参数相同，警告只保留第一个请求的
+		if i > 0 {
+			rewritten.Warnings = nil
+		}
+		rewritten.Choices = make([]api.ChunkChoice, len(chunk.Choices))
+		for j, choice := range chunk.Choices {
+			choice.Index = i
+			rewritten.Choices[j] = choice
+		}
+		if len(rewritten.Choices) == 0 {
+			continue
+		}
+		if !s.send(api.StreamResult{Chunk: &rewritten}) {
+			return
+		}
+	}
+}
+
+// send 将结果交给Recv，流被关闭时返回false
+func (s *candidateStream) send(result api.StreamResult) bool {
+	select {
+	case s.results <- result:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// Recv 实现ResponseStream接口，所有请求结束后返回合并的用量块，随后返回io.EOF
+func (s *candidateStream) Recv() (*api.ResponseChunk, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.startOnce.Do(s.start)
+
+	result, ok := <-s.results
+	if !ok {
+		if s.finished {
+			s.err = io.EOF
+			return nil, io.EOF
+		}
+		// 所有请求都已结束，释放派生的上下文，调用方没有调用Close也不会泄漏
+		s.finished = true
+		s.cancel()
+		return s.usageChunk(), nil
+	}
+	if result.Err != nil {
+		s.err = result.Err
+		s.Close()
+		return nil, result.Err
+	}
+	if s.first == nil {
+		s.first = result.Chunk
+	}
+	return result.Chunk, nil
+}
+
+// usageChunk 返回合并了所有请求用量的响应块
+func (s *candidateStream) usageChunk() *api.ResponseChunk {
+	var usage api.Usage
+	for _, u := range s.usages {
+		if u != nil {
+			addUsage(&usage, u)
+		}
+	}
+
+	chunk := &api.ResponseChunk{
+		Object:  "chat.completion.chunk",
+		Choices: []api.ChunkChoice{},
+		Usage:   &usage,
+	}
+	if s.first != nil {
+		chunk.ID = s.first.ID
+		chunk.Object = s.first.Object
+		chunk.Created = s.first.Created
+		chunk.Model = s.first.Model
+	}
+	return chunk
+}
+
+// SetPingHandler 实现api.PingNotifier接口，任一请求收到心跳事件时调用handler
+//
+// 各请求在不同的goroutine中读取，handler的调用会被串行化。
+func (s *candidateStream) SetPingHandler(handler func()) {
+	var mu sync.Mutex
+	serialized := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		handler()
+	}
+	for _, stream := range s.streams {
+		if notifier, ok := stream.(api.PingNotifier); ok {
+			notifier.SetPingHandler(serialized)
+		}
+	}
+}
+
+// Close 实现ResponseStream接口，关闭所有请求
+func (s *candidateStream) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.cancel()
+		err = closeStreams(s.streams)
+	})
+	return err
+}
+
+// closeStreams 关闭所有不为空的流，返回第一个错误
+func closeStreams(streams []api.ResponseStream) error {
+	var firstErr error
+	for _, stream := range streams {
+		if stream == nil {
+			continue
+		}
+		if err := stream.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// firstError 返回导致失败的错误：其他请求因取消而返回的错误排在后面
+func firstError(errs []error) error {
+	var canceled error
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, context.Canceled) {
+			return err
+		}
+		if canceled == nil {
+			canceled = err
+		}
+	}
+	return canceled
+}
+
+// addUsage 将src累加到dst
+func addUsage(dst, src *api.Usage) {
+	dst.PromptTokens += src.PromptTokens
+	dst.CompletionTokens += src.CompletionTokens
+	dst.TotalTokens += src.TotalTokens
+	dst.ReasoningTokens += src.ReasoningTokens
+	dst.CacheCreationTokens += src.CacheCreationTokens
+	dst.CacheReadTokens += src.CacheReadTokens
+}
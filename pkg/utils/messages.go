@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// ChatMessage 是OpenAI兼容接口（OpenAI、智谱、通义千问、Moonshot、豆包等）的消息结构
+//
+// 只包含这些接口接受的字段，思考内容、思考块和缓存断点等SDK特有的字段不会发送。
+type ChatMessage struct {
+	Role       string         `json:"role"`
+	Content    string         `json:"content"`
+	Name       string         `json:"name,omitempty"`
+	ToolCalls  []api.ToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+// NewChatMessages 将SDK的消息转换为OpenAI兼容接口的格式
+//
+// 只设置了Parts的消息按顺序拼接各段文本作为内容；工具调用中仅用于流式拼接的Index不会发送。
+func NewChatMessages(messages []api.Message) []ChatMessage {
+	result := make([]ChatMessage, 0, len(messages))
+	for _, msg := range messages {
+		result = append(result, ChatMessage{
+			Role:       string(msg.Role),
+			Content:    MessageText(msg),
+			Name:       msg.Name,
+			ToolCalls:  requestToolCalls(msg.ToolCalls),
+			ToolCallID: msg.ToolCallID,
+		})
+	}
+	return result
+}
+
+// Message 将OpenAI兼容接口返回的消息转换为SDK的通用格式
+func (m ChatMessage) Message() api.Message {
+	return api.Message{
+		Role:       api.Role(m.Role),
+		Content:    m.Content,
+		Name:       m.Name,
+		ToolCalls:  m.ToolCalls,
+		ToolCallID: m.ToolCallID,
+	}
+}
+
+// MessageText 返回消息的文本内容：Content为空且设置了Parts时拼接各段文本
+func MessageText(msg api.Message) string {
+	if msg.Content != "" || len(msg.Parts) == 0 {
+		return msg.Content
+	}
+	var builder strings.Builder
+	for _, part := range msg.Parts {
+		builder.WriteString(part.Text)
+	}
+	return builder.String()
+}
+
+// requestToolCalls 去掉工具调用中仅用于流式拼接的Index，没有修改时返回原切片
+func requestToolCalls(calls []api.ToolCall) []api.ToolCall {
+	for i, call := range calls {
+		if call.Index == nil {
+			continue
+		}
+		result := make([]api.ToolCall, len(calls))
+		copy(result, calls)
+		for j := i; j < len(result); j++ {
+			result[j].Index = nil
+		}
+		return result
+	}
+	return calls
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+func TestNewChatMessages(t *testing.T) {
+	index := 0
+	tests := []struct {
+		name    string
+		message api.Message
+		want    string
+	}{
+		{
+			name:    "user",
+			message: api.Message{Role: api.RoleUser, Content: "你好"},
+			want:    `{"role":"user","content":"你好"}`,
+		},
+		{
+			name: "reasoning and thinking blocks are dropped",
+			message: api.Message{
+				Role:             api.RoleAssistant,
+				Content:          "答案",
+				ReasoningContent: "思考过程",
+				ThinkingBlocks:   []api.ThinkingBlock{{Type: "thinking", Thinking: "思考", Signature: "sig"}},
+				CacheControl:     &api.CacheControl{Type: "ephemeral"},
+			},
+			want: `{"role":"assistant","content":"答案"}`,
+		},
+		{
+			name: "parts become content",
+			message: api.Message{
+				Role:  api.RoleSystem,
+				Parts: []api.ContentPart{{Type: "text", Text: "a"}, {Type: "text", Text: "b"}},
+			},
+			want: `{"role":"system","content":"ab"}`,
+		},
+		{
+			name: "tool call index is dropped",
+			message: api.Message{
+				Role:      api.RoleAssistant,
+				ToolCalls: []api.ToolCall{{Index: &index, ID: "call_1", Type: "function", Function: api.FunctionCall{Name: "f", Arguments: "{}"}}},
+			},
+			want: `{"role":"assistant","content":"","tool_calls":[{"id":"call_1","type":"function","function":{"name":"f","arguments":"{}"}}]}`,
+		},
+		{
+			name:    "tool result",
+			message: api.Message{Role: api.RoleTool, Content: "42", Name: "f", ToolCallID: "call_1"},
+			want:    `{"role":"tool","content":"42","name":"f","tool_call_id":"call_1"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(NewChatMessages([]api.Message{tt.message}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "[" + tt.want + "]"; string(got) != want {
+				t.Errorf("got %s, want %s", got, want)
+			}
+		})
+	}
+
+	if tests[3].message.ToolCalls[0].Index == nil {
+		t.Error("NewChatMessages modified the input tool calls")
+	}
+}
@@ -1,119 +1,294 @@
 package utils
 
 import (
-	"bufio"
 	"bytes"
+	"errors"
 	"io"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
+// DefaultSSEMaxEventSize 单个事件的默认最大长度（字节）
+const DefaultSSEMaxEventSize = 16 * 1024 * 1024
+
+// sseReadBufferSize 读取缓冲区的大小
+const sseReadBufferSize = 4096
+
+// ErrSSEEventTooLarge 表示事件超过了最大长度
+var ErrSSEEventTooLarge = errors.New("sse: event too large")
+
+// utf8BOM UTF-8字节顺序标记
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
 // SSEEvent 表示一个SSE事件
 type SSEEvent struct {
+	// Event 事件类型，为空时表示默认的message类型
 	Event string
 	Data  string
-	ID    string
+	// ID 分发该事件时的最后事件ID，未被新的id字段覆盖时沿用之前的值
+	ID string
+	// Retry 该事件中retry字段指定的重连时间（毫秒），未指定时为0
 	Retry int
 }
 
-// SSEReader 是一个SSE事件流解析器
+// SSEReader 是一个SSE事件流解析器，按照WHATWG HTML标准中的规则解析事件流
+//
+// 支持\r\n、\n和\r三种换行符，忽略流开头的UTF-8 BOM，没有冒号的行视为值为空的字段。
+// 流在事件中途结束时，未完成的事件会被丢弃。
 type SSEReader struct {
-	reader    *bufio.Reader
-	delimiter []byte
-	event     SSEEvent
-	buffer    []byte
+	reader       io.Reader
+	maxEventSize int
+
+	// 读取缓冲区，buf[start:end]为尚未处理的数据
+	buf        []byte
+	start, end int
+	err        error
+	// started 是否已经检查过开头的BOM
+	started bool
+	// skipLF 上一行以\r结尾，紧随其后的\n属于同一个换行符
+	skipLF bool
+	// line 跨越缓冲区边界的行
+	line []byte
+
+	// 当前事件的状态
+	data      []byte
+	eventType []byte
+	hasData   bool
+	retry     int
+	size      int
+
+	lastEventIDBuffer string
+	lastEventID       string
+	reconnectionTime  int
+	// lastType 上一个事件的类型，类型相同时复用字符串以减少分配
+	lastType string
 }
 
-// NewSSEReader 创建一个新的SSE读取器
+// NewSSEReader 创建一个新的SSE读取器，单个事件的最大长度为DefaultSSEMaxEventSize
 func NewSSEReader(reader io.Reader) *SSEReader {
+	return NewSSEReaderSize(reader, DefaultSSEMaxEventSize)
+}
+
+// NewSSEReaderSize 创建一个新的SSE读取器，单个事件（或单行）超过maxEventSize字节时ReadEvent返回ErrSSEEventTooLarge
+func NewSSEReaderSize(reader io.Reader, maxEventSize int) *SSEReader {
+	if maxEventSize <= 0 {
+		maxEventSize = DefaultSSEMaxEventSize
+	}
 	return &SSEReader{
-		reader:    bufio.NewReader(reader),
-		delimiter: []byte{'\n', '\n'}, // SSE事件之间使用两个换行符分隔
-		event:     SSEEvent{},
+		reader:       reader,
+		maxEventSize: maxEventSize,
+		buf:          make([]byte, sseReadBufferSize),
 	}
 }
 
+// LastEventID 返回最后事件ID，重连时应通过Last-Event-ID请求头发送给服务器
+func (r *SSEReader) LastEventID() string {
+	return r.lastEventID
+}
+
+// Retry 返回服务器通过retry字段设置的重连时间（毫秒），未设置时为0
+func (r *SSEReader) Retry() int {
+	return r.reconnectionTime
+}
+
 // ReadEvent 读取SSE流中的下一个事件
+//
+// 只包含注释、或没有data字段的事件块不会被返回。流结束时返回io.EOF。
 func (r *SSEReader) ReadEvent() (*SSEEvent, error) {
-	r.event = SSEEvent{} // 重置事件对象
-
 	for {
-		line, err := r.reader.ReadBytes('\n')
+		line, err := r.readLine()
 		if err != nil {
-			if err == io.EOF {
-				// 如果读取到末尾但缓冲区中还有数据，处理最后一个事件
-				if len(r.buffer) > 0 && !bytes.Equal(r.buffer, []byte("\n")) {
-					r.buffer = append(r.buffer, '\n')
-					event := r.processBuffer()
-					r.buffer = nil
-					if event.Data != "" || event.Event != "" {
-						return &event, nil
-					}
-				}
-			}
+			// 流结束时丢弃未完成的事件
+			r.resetEvent()
 			return nil, err
 		}
 
-		// 将读取的行添加到缓冲区
-		r.buffer = append(r.buffer, line...)
-
-		// 检查是否有完整的事件（即双换行符）
-		if bytes.HasSuffix(r.buffer, r.delimiter) || (len(line) == 1 && line[0] == '\n' && len(r.buffer) > 1 && r.buffer[len(r.buffer)-2] == '\n') {
-			event := r.processBuffer()
-			r.buffer = nil
-			if event.Data != "" || event.Event != "" {
-				return &event, nil
-			}
-		}
-	}
-}
-
-// processBuffer 处理缓冲区中的数据，解析SSE事件
-func (r *SSEReader) processBuffer() SSEEvent {
-	lines := bytes.Split(r.buffer, []byte{'\n'})
-	event := SSEEvent{}
-
-	for _, line := range lines {
+		// 空行表示分发事件
 		if len(line) == 0 {
+			if event := r.dispatch(); event != nil {
+				return event, nil
+			}
 			continue
 		}
 
-		// 处理注释行
+		// 注释行
 		if line[0] == ':' {
 			continue
 		}
 
-		// 分割字段和值
-		parts := bytes.SplitN(line, []byte{':'}, 2)
-		if len(parts) != 2 {
-			continue
+		r.size += len(line)
+		if r.size > r.maxEventSize {
+			r.resetEvent()
+			return nil, ErrSSEEventTooLarge
 		}
+		r.processField(line)
+	}
+}
 
-		field := string(parts[0])
-		value := string(parts[1])
+// processField 处理一行字段
+func (r *SSEReader) processField(line []byte) {
+	field, value := line, []byte(nil)
+	if i := bytes.IndexByte(line, ':'); i >= 0 {
+		field, value = line[:i], line[i+1:]
 		if len(value) > 0 && value[0] == ' ' {
 			value = value[1:] // 去除第一个空格
 		}
+	}
 
-		// 根据字段类型设置事件属性
-		switch field {
-		case "event":
-			event.Event = value
-		case "data":
-			if event.Data != "" {
-				event.Data += "\n"
+	switch string(field) {
+	case "event":
+		r.eventType = append(r.eventType[:0], value...)
+	case "data":
+		if r.hasData {
+			r.data = append(r.data, '\n')
+		}
+		r.data = append(r.data, value...)
+		r.hasData = true
+	case "id":
+		// 包含NULL字符的id会被忽略
+		if bytes.IndexByte(value, 0) < 0 {
+			r.lastEventIDBuffer = toValidString(value)
+		}
+	case "retry":
+		if isASCIIDigits(value) {
+			if retry, err := strconv.Atoi(string(value)); err == nil {
+				r.retry = retry
+				r.reconnectionTime = retry
 			}
-			event.Data += value
-		case "id":
-			event.ID = value
-		case "retry":
-			// retry字段通常是一个整数，但我们这里简化处理
-			event.Retry = 3000 // 默认3秒
 		}
 	}
+}
+
+// dispatch 在遇到空行时分发事件，没有数据时返回nil
+func (r *SSEReader) dispatch() *SSEEvent {
+	r.lastEventID = r.lastEventIDBuffer
+	if !r.hasData {
+		r.resetEvent()
+		return nil
+	}
 
+	event := &SSEEvent{
+		Event: r.typeString(),
+		Data:  toValidString(r.data),
+		ID:    r.lastEventID,
+		Retry: r.retry,
+	}
+	r.resetEvent()
 	return event
 }
 
+// typeString 返回当前事件类型的字符串
+func (r *SSEReader) typeString() string {
+	if string(r.eventType) != r.lastType {
+		r.lastType = toValidString(r.eventType)
+	}
+	return r.lastType
+}
+
+// resetEvent 清空当前事件的状态，最后事件ID不受影响
+func (r *SSEReader) resetEvent() {
+	r.data = r.data[:0]
+	r.eventType = r.eventType[:0]
+	r.hasData = false
+	r.retry = 0
+	r.size = 0
+}
+
+// readLine 读取下一行，不包含换行符；返回的切片在下次调用前有效
+func (r *SSEReader) readLine() ([]byte, error) {
+	for {
+		if r.start < r.end {
+			if r.skipLF {
+				r.skipLF = false
+				if r.buf[r.start] == '\n' {
+					r.start++
+					continue
+				}
+			}
+
+			chunk := r.buf[r.start:r.end]
+			i := bytes.IndexAny(chunk, "\r\n")
+			if i >= 0 {
+				r.skipLF = chunk[i] == '\r'
+				r.start += i + 1
+				if len(r.line) == 0 {
+					return chunk[:i], nil
+				}
+				line := append(r.line, chunk[:i]...)
+				r.line = line[:0]
+				if len(line) > r.maxEventSize {
+					return nil, ErrSSEEventTooLarge
+				}
+				return line, nil
+			}
+
+			// 没有换行符，保存这部分后继续读取
+			r.line = append(r.line, chunk...)
+			r.start = r.end
+			if len(r.line) > r.maxEventSize {
+				r.line = r.line[:0]
+				return nil, ErrSSEEventTooLarge
+			}
+		}
+
+		if r.err != nil {
+			// 没有换行符结尾的最后一行属于未完成的事件，直接丢弃
+			r.line = r.line[:0]
+			return nil, r.err
+		}
+		r.fill()
+	}
+}
+
+// fill 从底层读取数据到缓冲区，首次读取时去除开头的BOM
+func (r *SSEReader) fill() {
+	r.start, r.end = 0, 0
+	for {
+		n, err := r.reader.Read(r.buf[r.end:])
+		r.end += n
+		if err != nil {
+			r.err = err
+		}
+
+		if !r.started {
+			// BOM可能被拆分到多次读取中
+			if r.end < len(utf8BOM) && r.err == nil && bytes.HasPrefix(utf8BOM, r.buf[:r.end]) {
+				continue
+			}
+			r.started = true
+			if bytes.HasPrefix(r.buf[:r.end], utf8BOM) {
+				r.start = len(utf8BOM)
+			}
+		}
+
+		if r.end > r.start || r.err != nil {
+			return
+		}
+	}
+}
+
+// toValidString 将字节转换为字符串，无效的UTF-8序列替换为U+FFFD
+func toValidString(b []byte) string {
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	return strings.ToValidUTF8(string(b), "�")
+}
+
+// isASCIIDigits 判断是否为非空的ASCII数字串
+func isASCIIDigits(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 // ParseSSEData 用于解析JSON格式的SSE数据
 func ParseSSEData(data string) string {
 	// 一些API会在data前面加上"data: "，需要去除
@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// 默认的转发配置
+const (
+	defaultHeartbeatInterval = 15 * time.Second
+	// SSEEventDone 默认结束事件的类型
+	SSEEventDone = "done"
+	// SSEEventError 默认错误事件的类型
+	SSEEventError = "error"
+)
+
+// RelayOptions 定义将流式响应转发为SSE的配置
+type RelayOptions struct {
+	// HeartbeatInterval 心跳注释的发送间隔，为负数时不发送心跳
+	HeartbeatInterval time.Duration
+
+	// Event 响应块事件的类型，为空时发送不带类型的消息事件
+	Event string
+
+	// Transform 将响应块转换为要发送的数据（序列化为JSON），返回nil时跳过该块；为空时发送响应块本身
+	Transform func(chunk *api.ResponseChunk) interface{}
+
+	// OnDone 流正常结束时调用，usage为流中最后一次出现的用量（可能为nil）；
+	// 为空时发送一个done事件，数据为{"usage": ...}
+	OnDone func(writer *SSEWriter, usage *api.Usage) error
+
+	// OnError 上游在流中途出错时调用；为空时发送一个error事件，数据为错误的JSON
+	OnError func(writer *SSEWriter, err error) error
+}
+
+// RelayOption 定义转发配置选项
+type RelayOption func(options *RelayOptions)
+
+// relayDone 默认结束事件的数据
+type relayDone struct {
+	Usage *api.Usage `json:"usage,omitempty"`
+}
+
+// relayError 默认错误事件的数据
+type relayError struct {
+	Error *api.Error `json:"error"`
+}
+
+// RelayStream 将流式响应以SSE格式写入w，直到流结束、上游出错或ctx被取消
+//
+// 调用前需要由调用方设置响应头（见SetSSEHeaders）。每个响应块写入后立即刷新；
+// 写入失败或ctx被取消（通常是客户端断开）时会关闭上游的流，使阻塞的Recv立即返回。
+// 函数返回时流已被关闭。返回值为流中最后一次出现的用量，以及上游错误或ctx的错误。
+func RelayStream(ctx context.Context, w io.Writer, stream api.ResponseStream, options ...RelayOption) (*api.Usage, error) {
+	opts := RelayOptions{
+		HeartbeatInterval: defaultHeartbeatInterval,
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	writer := NewSSEWriter(w)
+
+	var closeOnce sync.Once
+	closeStream := func() {
+		closeOnce.Do(func() { stream.Close() })
+	}
+	defer closeStream()
+
+	// 监听客户端断开并发送心跳
+	// 返回前等待该goroutine退出，避免在调用方返回后继续写入响应
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	defer func() {
+		close(done)
+		wg.Wait()
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var ticks <-chan time.Time
+		if opts.HeartbeatInterval > 0 {
+			ticker := time.NewTicker(opts.HeartbeatInterval)
+			defer ticker.Stop()
+			ticks = ticker.C
+		}
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				closeStream()
+				return
+			case <-ticks:
+				if err := writer.WriteComment("ping"); err != nil {
+					closeStream()
+					return
+				}
+			}
+		}
+	}()
+
+	var usage *api.Usage
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// 因客户端断开而关闭了上游时，返回ctx的错误
+			if ctx.Err() != nil {
+				return usage, ctx.Err()
+			}
+			if opts.OnError != nil {
+				opts.OnError(writer, err)
+			} else {
+				writer.WriteJSON(SSEEventError, relayError{Error: toAPIError(err)})
+			}
+			return usage, err
+		}
+
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+
+		var payload interface{} = chunk
+		if opts.Transform != nil {
+			payload = opts.Transform(chunk)
+			if payload == nil {
+				continue
+			}
+		}
+		if err := writer.WriteJSON(opts.Event, payload); err != nil {
+			return usage, err
+		}
+	}
+
+	if opts.OnDone != nil {
+		return usage, opts.OnDone(writer, usage)
+	}
+	return usage, writer.WriteJSON(SSEEventDone, relayDone{Usage: usage})
+}
+
+// NewSSEHandler 返回一个http.Handler，通过open创建流式响应并以SSE格式转发给客户端
+//
+// open返回错误时以JSON格式返回错误，状态码根据错误类型确定；
+// 客户端断开时上游的流会被关闭。open应使用r.Context()发起上游请求。
+func NewSSEHandler(open func(r *http.Request) (api.ResponseStream, error), options ...RelayOption) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stream, err := open(r)
+		if err != nil {
+			apiErr := toAPIError(err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(httpStatus(apiErr))
+			json.NewEncoder(w).Encode(relayError{Error: apiErr})
+			return
+		}
+
+		SetSSEHeaders(w.Header())
+		w.WriteHeader(http.StatusOK)
+		RelayStream(r.Context(), w, stream, options...)
+	})
+}
+
+// toAPIError 将错误转换为SDK错误
+func toAPIError(err error) *api.Error {
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return api.NewError(api.ErrorTypeUnknown, err.Error(), 0, err)
+}
+
+// httpStatus 返回SDK错误对应的HTTP状态码
+func httpStatus(err *api.Error) int {
+	switch err.Type {
+	case api.ErrorTypeInvalidRequest, api.ErrorTypeContentFiltered:
+		return http.StatusBadRequest
+	case api.ErrorTypeAuthentication:
+		return http.StatusUnauthorized
+	case api.ErrorTypeRateLimit:
+		return http.StatusTooManyRequests
+	case api.ErrorTypeTimeout:
+		return http.StatusGatewayTimeout
+	case api.ErrorTypeConnection, api.ErrorTypeServer:
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
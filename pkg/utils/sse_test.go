@@ -0,0 +1,317 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// readAll 读取流中的全部事件
+func readAll(r *SSEReader) ([]SSEEvent, error) {
+	var events []SSEEvent
+	for {
+		event, err := r.ReadEvent()
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+		events = append(events, *event)
+	}
+}
+
+func TestSSEReader(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   []SSEEvent
+		lastID string
+	}{
+		{
+			name:  "LF",
+			input: "event: ping\ndata: {\"a\":1}\n\ndata: b\n\n",
+			want:  []SSEEvent{{Event: "ping", Data: `{"a":1}`}, {Data: "b"}},
+		},
+		{
+			name:  "CRLF",
+			input: "event: x\r\ndata: 1\r\ndata: 2\r\n\r\n",
+			want:  []SSEEvent{{Event: "x", Data: "1\n2"}},
+		},
+		{
+			name:  "CR",
+			input: "data: a\rdata: b\r\rdata: c\r\r",
+			want:  []SSEEvent{{Data: "a\nb"}, {Data: "c"}},
+		},
+		{
+			name:  "BOM",
+			input: "\xEF\xBB\xBFdata: a\n\n",
+			want:  []SSEEvent{{Data: "a"}},
+		},
+		{
+			name:  "only first BOM is stripped",
+			input: "\xEF\xBB\xBF\xEF\xBB\xBFdata: a\n\ndata: b\n\n",
+			want:  []SSEEvent{{Data: "b"}},
+		},
+		{
+			name:  "field without colon",
+			input: "data\ndata\ndata: x\n\ndata\n\n",
+			want:  []SSEEvent{{Data: "\n\nx"}, {Data: ""}},
+		},
+		{
+			name:  "only one leading space is stripped",
+			input: "data:  two\ndata:none\n\n",
+			want:  []SSEEvent{{Data: " two\nnone"}},
+		},
+		{
+			name:  "comments and unknown fields are ignored",
+			input: ": keep-alive\nfoo: bar\ndata: x\n\n:\n\n",
+			want:  []SSEEvent{{Data: "x"}},
+		},
+		{
+			name:  "events without data are not dispatched",
+			input: "event: ping\n\ndata: x\n\n",
+			want:  []SSEEvent{{Data: "x"}},
+		},
+		{
+			name:   "id persists across events",
+			input:  "id: 1\ndata: a\n\ndata: b\n\nid\ndata: c\n\n",
+			want:   []SSEEvent{{ID: "1", Data: "a"}, {ID: "1", Data: "b"}, {ID: "", Data: "c"}},
+			lastID: "",
+		},
+		{
+			name:   "id containing NULL is ignored",
+			input:  "id: 1\ndata: a\n\nid: 2\x003\ndata: b\n\n",
+			want:   []SSEEvent{{ID: "1", Data: "a"}, {ID: "1", Data: "b"}},
+			lastID: "1",
+		},
+		{
+			name:  "retry",
+			input: "retry: 1500\ndata: a\n\nretry: 1.5\ndata: b\n\nretry: x\ndata: c\n\n",
+			want:  []SSEEvent{{Retry: 1500, Data: "a"}, {Data: "b"}, {Data: "c"}},
+		},
+		{
+			name:  "incomplete event is discarded",
+			input: "data: a\n\ndata: b\n",
+			want:  []SSEEvent{{Data: "a"}},
+		},
+		{
+			name:  "unterminated last line is discarded",
+			input: "data: a\n\ndata: b",
+			want:  []SSEEvent{{Data: "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, oneByte := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/oneByte=%v", tt.name, oneByte), func(t *testing.T) {
+				var in io.Reader = strings.NewReader(tt.input)
+				if oneByte {
+					in = iotest.OneByteReader(in)
+				}
+				r := NewSSEReader(in)
+				got, err := readAll(r)
+				if err != nil {
+					t.Fatalf("ReadEvent() error = %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("events = %#v, want %#v", got, tt.want)
+				}
+				wantID := tt.lastID
+				if wantID == "" && len(tt.want) > 0 {
+					wantID = tt.want[len(tt.want)-1].ID
+				}
+				if r.LastEventID() != wantID {
+					t.Errorf("LastEventID() = %q, want %q", r.LastEventID(), wantID)
+				}
+			})
+		}
+	}
+}
+
+func TestSSEReaderRetry(t *testing.T) {
+	r := NewSSEReader(strings.NewReader("retry: 2000\n\ndata: a\n\n"))
+	if _, err := r.ReadEvent(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Retry() != 2000 {
+		t.Errorf("Retry() = %d, want 2000", r.Retry())
+	}
+}
+
+func TestSSEReaderMaxEventSize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"long line", "data: " + strings.Repeat("x", 100) + "\n\n"},
+		{"many lines", strings.Repeat("data: 0123456789\n", 10) + "\n"},
+		{"unterminated line", strings.Repeat("x", 100)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewSSEReaderSize(strings.NewReader("data: ok\n\n"+tt.input), 64)
+			if event, err := r.ReadEvent(); err != nil || event.Data != "ok" {
+				t.Fatalf("first event = %v, %v", event, err)
+			}
+			if _, err := r.ReadEvent(); !errors.Is(err, ErrSSEEventTooLarge) {
+				t.Errorf("ReadEvent() error = %v, want ErrSSEEventTooLarge", err)
+			}
+		})
+	}
+}
+
+// referenceParse 按标准逐条实现的参考解析器，一次性处理全部输入
+func referenceParse(input []byte) ([]SSEEvent, string) {
+	s := strings.ToValidUTF8(string(bytes.TrimPrefix(input, utf8BOM)), "\uFFFD")
+
+	var lines []string
+	for {
+		i := strings.IndexAny(s, "\r\n")
+		if i < 0 {
+			break
+		}
+		lines = append(lines, s[:i])
+		if s[i] == '\r' && i+1 < len(s) && s[i+1] == '\n' {
+			i++
+		}
+		s = s[i+1:]
+	}
+
+	var (
+		events           []SSEEvent
+		data, eventType  string
+		hasData          bool
+		retry            int
+		idBuffer, lastID string
+	)
+	for _, line := range lines {
+		switch {
+		case line == "":
+			lastID = idBuffer
+			if hasData {
+				events = append(events, SSEEvent{Event: eventType, Data: data, ID: lastID, Retry: retry})
+			}
+			data, eventType, hasData, retry = "", "", false, 0
+			continue
+		case line[0] == ':':
+			continue
+		}
+
+		field, value, found := strings.Cut(line, ":")
+		if found {
+			value = strings.TrimPrefix(value, " ")
+		}
+		switch field {
+		case "event":
+			eventType = value
+		case "data":
+			if hasData {
+				data += "\n"
+			}
+			data += value
+			hasData = true
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				idBuffer = value
+			}
+		case "retry":
+			if value != "" && strings.Trim(value, "0123456789") == "" {
+				if n, err := strconv.Atoi(value); err == nil {
+					retry = n
+				}
+			}
+		}
+	}
+	return events, lastID
+}
+
+func FuzzSSEReader(f *testing.F) {
+	seeds := []string{
+		"data: a\n\n",
+		"event: message_start\r\ndata: {\"type\":\"message_start\"}\r\n\r\n",
+		"\xEF\xBB\xBFdata: a\rdata\r\r",
+		"id: 1\nretry: 100\ndata: x\n\n: comment\n\nid: \x00\ndata\n\n",
+		"data: \xff\xfe\n\n",
+		"data: [DONE]",
+	}
+	for _, seed := range seeds {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, input []byte) {
+		want, wantID := referenceParse(input)
+
+		for _, oneByte := range []bool{false, true} {
+			var in io.Reader = bytes.NewReader(input)
+			if oneByte {
+				in = iotest.OneByteReader(in)
+			}
+			r := NewSSEReader(in)
+			got, err := readAll(r)
+			if err != nil {
+				t.Fatalf("ReadEvent() error = %v", err)
+			}
+			if len(got) != len(want) || (len(got) > 0 && !reflect.DeepEqual(got, want)) {
+				t.Fatalf("oneByte=%v input=%q\n got  %#v\n want %#v", oneByte, input, got, want)
+			}
+			if r.LastEventID() != wantID {
+				t.Fatalf("oneByte=%v input=%q LastEventID() = %q, want %q", oneByte, input, r.LastEventID(), wantID)
+			}
+		}
+	})
+}
+
+// benchmarkStream 生成类似OpenAI流式响应的事件流
+func benchmarkStream(events int, lineEnding string) []byte {
+	var sb strings.Builder
+	for i := 0; i < events; i++ {
+		fmt.Fprintf(&sb, `data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1700000000,"model":"gpt-4o","choices":[{"index":0,"delta":{"content":"token %d"},"finish_reason":null}]}`, i)
+		sb.WriteString(lineEnding + lineEnding)
+	}
+	sb.WriteString("data: [DONE]" + lineEnding + lineEnding)
+	return []byte(sb.String())
+}
+
+func BenchmarkSSEReader(b *testing.B) {
+	for _, lineEnding := range []string{"\n", "\r\n"} {
+		input := benchmarkStream(1000, lineEnding)
+		b.Run(strconv.Quote(lineEnding), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(input)))
+			for i := 0; i < b.N; i++ {
+				r := NewSSEReader(bytes.NewReader(input))
+				for {
+					if _, err := r.ReadEvent(); err != nil {
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSSEReaderTyped(b *testing.B) {
+	var sb strings.Builder
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&sb, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"token %d\"}}\n\n", i)
+	}
+	input := []byte(sb.String())
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(input)))
+	for i := 0; i < b.N; i++ {
+		r := NewSSEReader(bytes.NewReader(input))
+		for {
+			if _, err := r.ReadEvent(); err != nil {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SSEWriter 是SSE事件流的写入器，每个事件写入后立即刷新
+//
+// SSEWriter可以被多个goroutine同时使用（例如一个写入数据，另一个发送心跳）。
+type SSEWriter struct {
+	mu      sync.Mutex
+	writer  io.Writer
+	flusher http.Flusher
+}
+
+// NewSSEWriter 创建一个新的SSE写入器，writer实现http.Flusher时每个事件写入后都会刷新
+func NewSSEWriter(writer io.Writer) *SSEWriter {
+	flusher, _ := writer.(http.Flusher)
+	return &SSEWriter{
+		writer:  writer,
+		flusher: flusher,
+	}
+}
+
+// SetSSEHeaders 设置SSE响应所需的响应头，需要在写入响应体之前调用
+func SetSSEHeaders(header http.Header) {
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// 禁用Nginx等反向代理的缓冲
+	header.Set("X-Accel-Buffering", "no")
+}
+
+// WriteEvent 写入一个事件，多行数据会被拆分为多个data字段
+func (w *SSEWriter) WriteEvent(event *SSEEvent) error {
+	var sb strings.Builder
+	if event.ID != "" {
+		writeField(&sb, "id", event.ID)
+	}
+	if event.Event != "" {
+		writeField(&sb, "event", event.Event)
+	}
+	if event.Retry > 0 {
+		writeField(&sb, "retry", strconv.Itoa(event.Retry))
+	}
+
+	data := strings.ReplaceAll(event.Data, "\r\n", "\n")
+	data = strings.ReplaceAll(data, "\r", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		writeField(&sb, "data", line)
+	}
+	sb.WriteByte('\n')
+
+	return w.write(sb.String())
+}
+
+// WriteData 写入一个只包含数据的事件
+func (w *SSEWriter) WriteData(data string) error {
+	return w.WriteEvent(&SSEEvent{Data: data})
+}
+
+// WriteJSON 将v序列化为JSON后作为事件数据写入，event为空时不设置事件类型
+func (w *SSEWriter) WriteJSON(event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return w.WriteEvent(&SSEEvent{Event: event, Data: string(data)})
+}
+
+// WriteComment 写入注释行，客户端会忽略注释，常用作心跳保持连接
+func (w *SSEWriter) WriteComment(text string) error {
+	text = strings.ReplaceAll(text, "\n", " ")
+	return w.write(": " + text + "\n\n")
+}
+
+// write 写入并刷新
+func (w *SSEWriter) write(s string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, err := io.WriteString(w.writer, s); err != nil {
+		return err
+	}
+	if w.flusher != nil {
+		w.flusher.Flush()
+	}
+	return nil
+}
+
+// writeField 写入一行字段
+func writeField(sb *strings.Builder, field, value string) {
+	sb.WriteString(field)
+	sb.WriteString(": ")
+	sb.WriteString(value)
+	sb.WriteByte('\n')
+}
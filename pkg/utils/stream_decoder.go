@@ -0,0 +1,150 @@
+package utils
+
+import (
+	"io"
+	"strings"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// SSEEventPing 心跳事件的类型
+const SSEEventPing = "ping"
+
+// StreamDecodeFunc 将一个SSE事件解码为响应块
+//
+// 返回(nil, nil)表示该事件不产生输出，继续读取下一个事件；返回io.EOF表示流正常结束。
+type StreamDecodeFunc func(event *SSEEvent) (*api.ResponseChunk, error)
+
+// StreamDecoderOptions 定义流式解码器的配置
+type StreamDecoderOptions struct {
+	// DoneData 表示流结束的数据，如OpenAI兼容接口的"[DONE]"；为空时只在连接关闭时结束
+	DoneData string
+
+	// ParseError 将错误数据解析为SDK错误，数据不是错误时返回nil。
+	// 类型为error的事件总是交给ParseError解析，解析不出时作为服务器错误返回
+	ParseError func(data []byte) *api.Error
+
+	// InlineErrors 错误是否通过普通数据事件下发（OpenAI兼容接口的做法），
+	// 为true时数据中包含"error"的事件会先交给ParseError
+	InlineErrors bool
+}
+
+// StreamDecoderOption 定义流式解码器选项
+type StreamDecoderOption func(options *StreamDecoderOptions)
+
+// StreamDecoder 是各提供商共用的流式响应读取循环
+//
+// StreamDecoder循环读取SSE事件，跳过空事件和不产生输出的事件，处理心跳、错误和结束标记，
+// 其余事件交给提供商的解码函数。提供商的流可以嵌入*StreamDecoder以实现Recv。
+// 流结束或出错后，后续的Recv返回相同的错误。
+type StreamDecoder struct {
+	reader  *SSEReader
+	decode  StreamDecodeFunc
+	options StreamDecoderOptions
+	onPing  func()
+	err     error
+
+	// observer 底层读取器需要感知首个token时不为空（见DoStreamRequest）
+	observer tokenObserver
+	gotToken bool
+}
+
+// NewStreamDecoder 创建一个新的流式解码器，从reader读取SSE事件并用decode解码
+func NewStreamDecoder(reader io.Reader, decode StreamDecodeFunc, options ...StreamDecoderOption) *StreamDecoder {
+	observer, _ := reader.(tokenObserver)
+	d := &StreamDecoder{
+		reader:   NewSSEReader(reader),
+		decode:   decode,
+		observer: observer,
+	}
+	for _, option := range options {
+		option(&d.options)
+	}
+	return d
+}
+
+// SetPingHandler 设置收到心跳事件时的回调，实现api.PingNotifier接口，需要在Recv之前调用
+func (d *StreamDecoder) SetPingHandler(handler func()) {
+	d.onPing = handler
+}
+
+// Recv 读取下一个响应块，流结束时返回io.EOF
+func (d *StreamDecoder) Recv() (*api.ResponseChunk, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	for {
+		chunk, err := d.next()
+		if err != nil {
+			d.err = err
+			return nil, err
+		}
+		if chunk != nil {
+			if d.observer != nil && !d.gotToken && hasToken(chunk) {
+				d.gotToken = true
+				d.observer.tokenReceived()
+			}
+			return chunk, nil
+		}
+	}
+}
+
+// hasToken 判断响应块是否包含模型生成的内容，只有角色、结束原因或用量的响应块不算
+func hasToken(chunk *api.ResponseChunk) bool {
+	for _, choice := range chunk.Choices {
+		delta := choice.Delta
+		if delta.Content != "" || delta.ReasoningContent != "" || len(delta.ToolCalls) > 0 || len(delta.ThinkingBlocks) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// next 处理一个事件，事件不产生输出时返回(nil, nil)
+func (d *StreamDecoder) next() (*api.ResponseChunk, error) {
+	event, err := d.reader.ReadEvent()
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, StreamReadError(err)
+	}
+
+	switch event.Event {
+	case SSEEventPing:
+		if d.onPing != nil {
+			d.onPing()
+		}
+		return nil, nil
+	case SSEEventError:
+		return nil, d.parseError(event.Data)
+	}
+
+	// 数据为空则跳过
+	if event.Data == "" {
+		return nil, nil
+	}
+	if d.options.DoneData != "" && event.Data == d.options.DoneData {
+		return nil, io.EOF
+	}
+
+	// 流中途通过数据事件返回的错误
+	if d.options.InlineErrors && d.options.ParseError != nil && strings.Contains(event.Data, `"error"`) {
+		if apiErr := d.options.ParseError([]byte(ParseSSEData(event.Data))); apiErr != nil {
+			return nil, apiErr
+		}
+	}
+
+	return d.decode(event)
+}
+
+// parseError 解析error事件中的错误
+func (d *StreamDecoder) parseError(data string) error {
+	data = ParseSSEData(data)
+	if d.options.ParseError != nil {
+		if apiErr := d.options.ParseError([]byte(data)); apiErr != nil {
+			return apiErr
+		}
+	}
+	return api.NewError(api.ErrorTypeServer, "流式响应返回错误: "+data, 0, nil)
+}
@@ -0,0 +1,210 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// NewStreamClient 返回用于流式请求的HTTP客户端：与client共享Transport，但不设置总超时
+//
+// 流式响应的时长取决于输出长度，由DoStreamRequest按阶段控制超时。
+func NewStreamClient(client *http.Client) *http.Client {
+	streamClient := *client
+	streamClient.Timeout = 0
+	return &streamClient
+}
+
+// DoStreamRequest 发送流式请求，并按timeouts监控连接、首个token和空闲三个阶段
+//
+// 某个阶段超时后请求会被取消：发生在收到响应头之前时直接返回错误，之后则在读取响应体时返回错误，
+// 错误类型均为api.ErrorTypeTimeout，可通过(*api.Error).TimeoutPhase()获取超时的阶段。
+// 首个token由基于响应体创建的StreamDecoder上报：只有包含内容、思考或工具调用的响应块才算，
+// 心跳和只有角色等元数据的事件不会结束首个token阶段。
+func DoStreamRequest(client *http.Client, req *http.Request, timeouts api.StreamTimeouts) (*http.Response, error) {
+	ctx, cancel := context.WithCancel(req.Context())
+	w := &streamWatchdog{cancel: cancel, timeouts: timeouts}
+	w.arm(api.TimeoutPhaseConnect, timeouts.Connect)
+
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		w.stop()
+		cancel()
+		if timeoutErr := w.timeoutError(err); timeoutErr != nil {
+			return nil, timeoutErr
+		}
+		return nil, api.NewError(api.ErrorTypeConnection, "HTTP请求失败", 0, err)
+	}
+
+	if timeouts.FirstToken > 0 {
+		w.arm(api.TimeoutPhaseFirstToken, timeouts.FirstToken)
+	} else {
+		w.arm(api.TimeoutPhaseIdle, timeouts.Idle)
+	}
+	resp.Body = &watchedBody{body: resp.Body, watchdog: w}
+	return resp, nil
+}
+
+// DoDownloadRequest 发送下载大文件（如批处理结果）的请求，只限制连接和空闲两个阶段，不限制总时长
+//
+// client不应设置总超时（见NewStreamClient）。读取响应体时两次收到数据的间隔超过timeouts.Idle会取消请求，
+// 错误类型为api.ErrorTypeTimeout；timeouts.FirstToken不起作用。
+func DoDownloadRequest(client *http.Client, req *http.Request, timeouts api.StreamTimeouts) (*http.Response, error) {
+	timeouts.FirstToken = 0
+	return DoStreamRequest(client, req, timeouts)
+}
+
+// StreamReadError 将读取流式响应时的错误转换为SDK错误，已经是SDK错误（如超时）时原样返回
+func StreamReadError(err error) error {
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return api.NewError(api.ErrorTypeServer, "读取SSE事件失败", 0, err)
+}
+
+// tokenObserver 由需要感知首个token到达的响应体实现
+type tokenObserver interface {
+	tokenReceived()
+}
+
+// streamWatchdog 在当前阶段超时后取消请求
+type streamWatchdog struct {
+	mu       sync.Mutex
+	cancel   context.CancelFunc
+	timeouts api.StreamTimeouts
+	timer    *time.Timer
+
+	// phase 当前监控的阶段，limit为该阶段的超时时间
+	phase api.TimeoutPhase
+	limit time.Duration
+	// lastActive 空闲阶段最近一次收到数据的时间
+	lastActive time.Time
+	gotToken   bool
+	stopped    bool
+
+	// expired 已超时的阶段
+	expired      api.TimeoutPhase
+	expiredLimit time.Duration
+}
+
+// arm 开始监控新的阶段，d不大于0时该阶段不限制
+func (w *streamWatchdog) arm(phase api.TimeoutPhase, d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stopped || w.expired != "" {
+		return
+	}
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	w.phase = phase
+	w.limit = d
+	w.lastActive = time.Now()
+	if d > 0 {
+		w.timer = time.AfterFunc(d, func() { w.fire(phase) })
+	}
+}
+
+// fire 在计时器到期时调用；空闲阶段期间收到过数据时顺延
+func (w *streamWatchdog) fire(phase api.TimeoutPhase) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stopped || w.expired != "" || w.phase != phase || w.timer == nil {
+		return
+	}
+	if phase == api.TimeoutPhaseIdle {
+		if remaining := time.Until(w.lastActive.Add(w.limit)); remaining > 0 {
+			w.timer.Reset(remaining)
+			return
+		}
+	}
+
+	w.expired = phase
+	w.expiredLimit = w.limit
+	w.cancel()
+}
+
+// touch 记录收到了数据
+func (w *streamWatchdog) touch() {
+	w.mu.Lock()
+	w.lastActive = time.Now()
+	w.mu.Unlock()
+}
+
+// tokenReceived 收到第一个token后从首个token阶段切换到空闲阶段
+func (w *streamWatchdog) tokenReceived() {
+	w.mu.Lock()
+	first := !w.gotToken
+	w.gotToken = true
+	inFirstToken := w.phase == api.TimeoutPhaseFirstToken
+	w.mu.Unlock()
+
+	if first && inFirstToken {
+		w.arm(api.TimeoutPhaseIdle, w.timeouts.Idle)
+	}
+}
+
+// stop 停止监控
+func (w *streamWatchdog) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stopped = true
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+}
+
+// timeoutError 已超时时返回对应阶段的超时错误，否则返回nil
+func (w *streamWatchdog) timeoutError(err error) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.expired == "" {
+		return nil
+	}
+	return api.NewTimeoutError(w.expired, w.expiredLimit, err)
+}
+
+// watchedBody 包装响应体，读取到数据时通知watchdog
+type watchedBody struct {
+	body     io.ReadCloser
+	watchdog *streamWatchdog
+}
+
+// Read 实现io.Reader接口，超时导致的读取错误会被替换为超时错误
+func (b *watchedBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if n > 0 {
+		b.watchdog.touch()
+	}
+	if err != nil && err != io.EOF {
+		if timeoutErr := b.watchdog.timeoutError(err); timeoutErr != nil {
+			return n, timeoutErr
+		}
+	}
+	return n, err
+}
+
+// Close 实现io.Closer接口，同时停止监控并取消请求
+func (b *watchedBody) Close() error {
+	b.watchdog.stop()
+	err := b.body.Close()
+	b.watchdog.cancel()
+	return err
+}
+
+// tokenReceived 实现tokenObserver接口
+func (b *watchedBody) tokenReceived() {
+	b.watchdog.tokenReceived()
+}
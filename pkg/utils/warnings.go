@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"github.com/ojbkgo/llm-sdk/pkg/api"
+)
+
+// WithWarnings 返回在第一个响应块中附带warnings的流，warnings为空时直接返回stream
+func WithWarnings(stream api.ResponseStream, warnings []string) api.ResponseStream {
+	if len(warnings) == 0 {
+		return stream
+	}
+	return &warningStream{ResponseStream: stream, warnings: warnings}
+}
+
+// warningStream 在第一个响应块中附带警告
+type warningStream struct {
+	api.ResponseStream
+	warnings []string
+}
+
+// Recv 实现ResponseStream接口
+func (s *warningStream) Recv() (*api.ResponseChunk, error) {
+	chunk, err := s.ResponseStream.Recv()
+	if err == nil && s.warnings != nil {
+		chunk.Warnings = append(chunk.Warnings, s.warnings...)
+		s.warnings = nil
+	}
+	return chunk, err
+}
+
+// SetPingHandler 实现api.PingNotifier接口，底层的流不上报心跳时不做任何事
+func (s *warningStream) SetPingHandler(handler func()) {
+	if notifier, ok := s.ResponseStream.(api.PingNotifier); ok {
+		notifier.SetPingHandler(handler)
+	}
+}